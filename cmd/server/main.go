@@ -11,6 +11,26 @@ func main() {
 	if err := apiserver.New(
 		apiserver.WithServerEnv(os.Getenv("SERVER_ENV")),
 		apiserver.WithLogLevel(os.Getenv("LOG_LEVEL")),
+		apiserver.WithAOFPath(os.Getenv("AOF_PATH")),
+		apiserver.WithAOFFsync(os.Getenv("AOF_FSYNC")),
+		apiserver.WithSnapshotDir(os.Getenv("SNAPSHOT_DIR")),
+		apiserver.WithSnapshotInterval(os.Getenv("SNAPSHOT_INTERVAL")),
+		apiserver.WithRESPAddr(os.Getenv("RESP_ADDR")),
+		apiserver.WithMemcacheAddr(os.Getenv("MEMCACHE_ADDR")),
+		apiserver.WithAccessLogSampleRate(os.Getenv("ACCESS_LOG_SAMPLE_RATE")),
+		apiserver.WithPublicMetrics(os.Getenv("PUBLIC_METRICS")),
+		apiserver.WithAPIKeysFile(os.Getenv("API_KEYS_FILE")),
+		apiserver.WithAPIKeys(os.Getenv("API_KEYS")),
+		apiserver.WithJWTSecrets(os.Getenv("JWT_SECRETS")),
+		apiserver.WithJWKSFile(os.Getenv("JWT_JWKS_FILE")),
+		apiserver.WithJWTIssuer(os.Getenv("JWT_ISSUER")),
+		apiserver.WithJWTAudience(os.Getenv("JWT_AUDIENCE")),
+		apiserver.WithTLSCertFile(os.Getenv("TLS_CERT_FILE")),
+		apiserver.WithTLSKeyFile(os.Getenv("TLS_KEY_FILE")),
+		apiserver.WithTLSMinVersion(os.Getenv("TLS_MIN_VERSION")),
+		apiserver.WithTLSClientCAFile(os.Getenv("TLS_CLIENT_CA_FILE")),
+		apiserver.WithTLSClientAuth(os.Getenv("TLS_CLIENT_AUTH")),
+		apiserver.WithClientCertPermissionsFile(os.Getenv("TLS_CLIENT_PERMISSIONS_FILE")),
 	); err != nil {
 		log.Fatal(err)
 	}
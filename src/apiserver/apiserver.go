@@ -2,18 +2,30 @@ package apiserver
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/vbyazilim/kvstore/src/internal/auth"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvpersist"
 	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/tlsconfig"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
 	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+	"github.com/vbyazilim/kvstore/src/internal/transport/memcache/memcacheserver"
+	"github.com/vbyazilim/kvstore/src/internal/transport/resp/respserver"
 	"github.com/vbyazilim/kvstore/src/releaseinfo"
 )
 
@@ -24,37 +36,94 @@ const (
 	ServerReadTimeout    = 10 * time.Second
 	ServerWriteTimeout   = 10 * time.Second
 	ServerIdleTimeout    = 60 * time.Second
+	SnapshotInterval     = 5 * time.Minute
+	JWTLeeway            = 30 * time.Second
+	ExpireSweepInterval  = 1 * time.Second
+
+	DefaultAddr = ":8000"
 
 	apiV1Prefix = "/api/v1"
+	metricsPath = "/metrics"
 )
 
-type apiServer struct {
+// Server is the kvstore server, serves http api and optional Redis and
+// memcached protocol listeners on top of the same storage.
+type Server struct {
 	db        kvstorage.MemoryDB
 	logLevel  slog.Level
 	logger    *slog.Logger
 	serverEnv string
+	aofPath   string
+	aofFsync  kvstorage.FsyncPolicy
+
+	snapshotDir      string
+	snapshotInterval time.Duration
+
+	respAddr     string
+	memcacheAddr string
+
+	accessLogSampleRate float64
+	publicMetrics       bool
+
+	apiKeysFile   string
+	apiKeys       string
+	jwtSecrets    []string
+	jwksFile      string
+	jwtIssuer     string
+	jwtAudience   string
+	authenticator auth.Authenticator // nil when authentication is disabled
+
+	tlsCertFile           string
+	tlsKeyFile            string
+	tlsMinVersion         string
+	tlsClientCAFile       string
+	tlsClientAuth         string
+	clientCertPermissions string
+	tlsConfig             *tls.Config       // nil when serving plain http
+	certMapping           *auth.CertMapping // nil when client certificates are not mapped
+
+	addr          string
+	listener      net.Listener
+	storage       kvstorage.Storer
+	persist       *kvpersist.Storage // persistence and background jobs of storage
+	handleSignals bool
+
+	service        kvstoreservice.KVStoreService
+	metrics        *serverMetrics
+	handler        http.Handler
+	httpServer     *http.Server
+	respServer     *respserver.Server
+	memcacheServer *memcacheserver.Server
+	streamsCtx     context.Context // canceled on shutdown, ends watch streams
+	cancelStreams  context.CancelFunc
+	errs           chan error
+
+	mu           sync.Mutex // guarding started and shutdownErr
+	started      bool
+	shutdownOnce sync.Once
+	shutdownErr  error
 }
 
 // Option represents api server option type.
-type Option func(*apiServer)
+type Option func(*Server)
 
 // WithLogger sets logger option.
 func WithLogger(l *slog.Logger) Option {
-	return func(s *apiServer) {
+	return func(s *Server) {
 		s.logger = l
 	}
 }
 
 // WithServerEnv sets serverEnv option.
 func WithServerEnv(env string) Option {
-	return func(s *apiServer) {
+	return func(s *Server) {
 		s.serverEnv = env
 	}
 }
 
 // WithLogLevel sets logLevel option.
 func WithLogLevel(level string) Option {
-	return func(s *apiServer) {
+	return func(s *Server) {
 		var logLevel slog.Level
 
 		switch level {
@@ -72,42 +141,409 @@ func WithLogLevel(level string) Option {
 	}
 }
 
-// New instantiates new server instance.
-func New(options ...Option) error {
-	apisrvr := &apiServer{
-		db:       kvstorage.MemoryDB(make(map[string]any)), // default db
+// WithAOFPath sets append-only file path option, persistence is disabled
+// when path is empty.
+func WithAOFPath(path string) Option {
+	return func(s *Server) {
+		s.aofPath = path
+	}
+}
+
+// WithAOFFsync sets append-only file fsync policy option.
+func WithAOFFsync(policy string) Option {
+	return func(s *Server) {
+		var fsyncPolicy kvstorage.FsyncPolicy
+
+		switch policy {
+		case "always":
+			fsyncPolicy = kvstorage.FsyncAlways
+		case "no":
+			fsyncPolicy = kvstorage.FsyncNever
+		default:
+			fsyncPolicy = kvstorage.FsyncEverySecond
+		}
+
+		s.aofFsync = fsyncPolicy
+	}
+}
+
+// WithSnapshotDir sets snapshot directory option, snapshots are disabled
+// when dir is empty.
+func WithSnapshotDir(dir string) Option {
+	return func(s *Server) {
+		s.snapshotDir = dir
+	}
+}
+
+// WithSnapshotInterval sets snapshot interval option, accepts
+// time.ParseDuration format, falls back to SnapshotInterval.
+func WithSnapshotInterval(interval string) Option {
+	return func(s *Server) {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			d = SnapshotInterval
+		}
+
+		s.snapshotInterval = d
+	}
+}
+
+// WithAccessLogSampleRate sets fraction of successful (2xx) requests which
+// are logged, accepts 0 to 1, falls back to 1 (all requests). Other
+// requests are always logged.
+func WithAccessLogSampleRate(rate string) Option {
+	return func(s *Server) {
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil || r < 0 || r > 1 {
+			r = 1
+		}
+
+		s.accessLogSampleRate = r
+	}
+}
+
+// WithPublicMetrics sets whether /metrics stays public when authentication
+// is enabled, accepts "true" or "false", falls back to false. Otherwise
+// metrics require admin scope on all namespaces, they expose namespace
+// names.
+func WithPublicMetrics(enabled string) Option {
+	return func(s *Server) {
+		s.publicMetrics, _ = strconv.ParseBool(enabled)
+	}
+}
+
+// WithAPIKeysFile sets path of api keys json file option, requests are
+// authenticated when a key is configured.
+func WithAPIKeysFile(path string) Option {
+	return func(s *Server) {
+		s.apiKeysFile = path
+	}
+}
+
+// WithAPIKeys sets api keys json option, merged with keys of WithAPIKeysFile.
+func WithAPIKeys(keys string) Option {
+	return func(s *Server) {
+		s.apiKeys = keys
+	}
+}
+
+// WithJWTSecrets sets comma separated hmac secrets option, verifying
+// HS256 signed bearer tokens. Secrets must be at least 32 bytes.
+func WithJWTSecrets(secrets string) Option {
+	return func(s *Server) {
+		for _, secret := range strings.Split(secrets, ",") {
+			if secret = strings.TrimSpace(secret); secret != "" {
+				s.jwtSecrets = append(s.jwtSecrets, secret)
+			}
+		}
+	}
+}
+
+// WithJWKSFile sets path of json web key set option, verifying RS256 (and
+// HS256 for "oct" keys) signed bearer tokens.
+func WithJWKSFile(path string) Option {
+	return func(s *Server) {
+		s.jwksFile = path
+	}
+}
+
+// WithJWTIssuer sets required iss claim of bearer tokens option.
+func WithJWTIssuer(issuer string) Option {
+	return func(s *Server) {
+		s.jwtIssuer = issuer
+	}
+}
+
+// WithJWTAudience sets required aud claim of bearer tokens option.
+func WithJWTAudience(audience string) Option {
+	return func(s *Server) {
+		s.jwtAudience = audience
+	}
+}
+
+// WithTLSCertFile sets certificate (chain) pem file option, http api is
+// served over tls when set. Rotated certificates are reloaded.
+func WithTLSCertFile(path string) Option {
+	return func(s *Server) {
+		s.tlsCertFile = path
+	}
+}
+
+// WithTLSKeyFile sets private key pem file option of WithTLSCertFile.
+func WithTLSKeyFile(path string) Option {
+	return func(s *Server) {
+		s.tlsKeyFile = path
+	}
+}
+
+// WithTLSMinVersion sets minimum tls version option, "1.2" (default) or
+// "1.3".
+func WithTLSMinVersion(version string) Option {
+	return func(s *Server) {
+		s.tlsMinVersion = version
+	}
+}
+
+// WithTLSClientCAFile sets ca bundle pem file option, client certificates
+// are verified against it (mutual tls).
+func WithTLSClientCAFile(path string) Option {
+	return func(s *Server) {
+		s.tlsClientCAFile = path
+	}
+}
+
+// WithTLSClientAuth sets client certificate mode option, "require"
+// (default) or "optional" (verified if given).
+func WithTLSClientAuth(mode string) Option {
+	return func(s *Server) {
+		s.tlsClientAuth = mode
+	}
+}
+
+// WithClientCertPermissionsFile sets path of json file option, mapping
+// subjects of client certificates to permissions.
+func WithClientCertPermissionsFile(path string) Option {
+	return func(s *Server) {
+		s.clientCertPermissions = path
+	}
+}
+
+// WithAddr sets http listen address option, defaults to DefaultAddr.
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithListener sets http listener option, takes precedence over WithAddr.
+// Listener is closed on Shutdown.
+func WithListener(ln net.Listener) Option {
+	return func(s *Server) {
+		s.listener = ln
+	}
+}
+
+// WithStorage sets storage option. Injected storage is used as is,
+// persistence options (aof, snapshots) are ignored and storage is not
+// snapshotted on Shutdown.
+func WithStorage(strg kvstorage.Storer) Option {
+	return func(s *Server) {
+		s.storage = strg
+	}
+}
+
+// WithSignalHandling sets signal handling option of Run, SIGINT and SIGTERM
+// trigger shutdown when enabled (default).
+func WithSignalHandling(enabled bool) Option {
+	return func(s *Server) {
+		s.handleSignals = enabled
+	}
+}
+
+// WithRESPAddr sets Redis protocol listen address option, e.g. ":6379",
+// Redis protocol listener is disabled when addr is empty. Listener is not
+// authenticated, it can not be enabled with authentication.
+func WithRESPAddr(addr string) Option {
+	return func(s *Server) {
+		s.respAddr = addr
+	}
+}
+
+// WithMemcacheAddr sets memcached protocol listen address option, e.g.
+// ":11211", memcached protocol listener is disabled when addr is empty.
+// Listener is not authenticated, it can not be enabled with authentication.
+func WithMemcacheAddr(addr string) Option {
+	return func(s *Server) {
+		s.memcacheAddr = addr
+	}
+}
+
+// NewServer instantiates new server instance, storage is loaded (snapshot,
+// append-only file) but nothing is served until Start.
+func NewServer(options ...Option) (*Server, error) {
+	srvr := &Server{
+		db:       make(kvstorage.MemoryDB), // default db
 		logLevel: slog.LevelInfo,
+		aofFsync: kvstorage.FsyncEverySecond,
+		addr:     DefaultAddr,
+
+		snapshotInterval:    SnapshotInterval,
+		accessLogSampleRate: 1,
+		handleSignals:       true,
+		errs:                make(chan error, 3),
 	}
 
 	for _, o := range options {
-		o(apisrvr)
+		o(srvr)
 	}
 
 	// default logging options if logger not present.
-	if apisrvr.logger == nil {
-		logHandlerOpts := &slog.HandlerOptions{Level: apisrvr.logLevel}
+	if srvr.logger == nil {
+		logHandlerOpts := &slog.HandlerOptions{Level: srvr.logLevel}
 		logHandler := slog.NewJSONHandler(os.Stdout, logHandlerOpts)
-		apisrvr.logger = slog.New(logHandler)
+		srvr.logger = slog.New(logHandler)
 	}
-	slog.SetDefault(apisrvr.logger)
+	// request id, trace ids and subject of request contexts are logged.
+	srvr.logger = slog.New(auth.NewLogHandler(tracing.NewLogHandler(srvr.logger.Handler())))
 
-	if apisrvr.serverEnv == "" {
-		apisrvr.serverEnv = "production" // default server environment
+	if srvr.serverEnv == "" {
+		srvr.serverEnv = "production" // default server environment
 	}
 
-	logger := apisrvr.logger
+	if err := srvr.loadAuthenticators(); err != nil {
+		return nil, err
+	}
 
-	storage := kvstorage.New(
-		kvstorage.WithMemoryDB(apisrvr.db),
+	if err := srvr.loadTLS(); err != nil {
+		return nil, err
+	}
+
+	// protocol listeners have no credentials, they would bypass http api
+	// authentication.
+	if (srvr.authenticator != nil || srvr.certMapping != nil) && (srvr.respAddr != "" || srvr.memcacheAddr != "") {
+		return nil, errors.New("redis and memcached protocol listeners can not be enabled with authentication")
+	}
+
+	persist, err := kvpersist.Open(
+		kvpersist.WithStorage(srvr.storage),
+		kvpersist.WithMemoryDB(srvr.db),
+		kvpersist.WithSnapshotDir(srvr.snapshotDir),
+		kvpersist.WithSnapshotInterval(srvr.snapshotInterval),
+		kvpersist.WithExpireSweepInterval(ExpireSweepInterval),
+		kvpersist.WithAOF(srvr.aofPath, srvr.aofFsync),
+		kvpersist.WithLogger(srvr.logger),
 	)
-	service := kvstoreservice.New(
-		kvstoreservice.WithStorage(storage),
+	if err != nil {
+		return nil, err
+	}
+	srvr.persist = persist
+	srvr.storage = persist.Storer()
+
+	srvr.metrics = newServerMetrics(srvr.storage)
+	srvr.service = kvstoreservice.Observe(
+		kvstoreservice.New(kvstoreservice.WithStorage(srvr.storage)),
+		srvr.metrics.observeOperation,
 	)
+	srvr.streamsCtx, srvr.cancelStreams = context.WithCancel(context.Background())
+	srvr.handler = srvr.routes()
+
+	return srvr, nil
+}
+
+// loadAuthenticators loads api keys and jwt verification keys,
+// authentication stays disabled when none is configured.
+func (s *Server) loadAuthenticators() error {
+	var authenticators auth.Authenticators
+
+	keys := &auth.KeyStore{}
+
+	if s.apiKeysFile != "" {
+		fileKeys, err := auth.LoadKeys(s.apiKeysFile)
+		if err != nil {
+			return fmt.Errorf("load api keys err: %w", err)
+		}
+		keys = keys.Merge(fileKeys)
+	}
+
+	if s.apiKeys != "" {
+		envKeys, err := auth.ParseKeys([]byte(s.apiKeys))
+		if err != nil {
+			return fmt.Errorf("parse api keys err: %w", err)
+		}
+		keys = keys.Merge(envKeys)
+	}
+
+	if keys.Len() > 0 {
+		s.logger.Info("api key authentication enabled", "keys", keys.Len())
+		authenticators = append(authenticators, keys)
+	}
+
+	jwtOptions := []auth.JWTOption{
+		auth.WithIssuer(s.jwtIssuer),
+		auth.WithAudience(s.jwtAudience),
+		auth.WithLeeway(JWTLeeway),
+	}
+	for _, secret := range s.jwtSecrets {
+		jwtOptions = append(jwtOptions, auth.WithHMACSecret(secret))
+	}
+
+	if s.jwksFile != "" {
+		jwks, err := auth.LoadJWKS(s.jwksFile)
+		if err != nil {
+			return fmt.Errorf("load jwks err: %w", err)
+		}
+		jwtOptions = append(jwtOptions, auth.WithJWKS(jwks))
+	}
+
+	if len(s.jwtSecrets) > 0 || s.jwksFile != "" {
+		verifier, err := auth.NewJWTVerifier(jwtOptions...)
+		if err != nil {
+			return fmt.Errorf("jwt verifier err: %w", err)
+		}
+
+		s.logger.Info("jwt authentication enabled", "issuer", s.jwtIssuer, "audience", s.jwtAudience)
+		authenticators = append(authenticators, verifier)
+	}
+
+	if len(authenticators) > 0 {
+		s.authenticator = authenticators
+	}
+
+	return nil
+}
+
+// loadTLS builds tls config and client certificate mapping, http api is
+// served in plain when no certificate is configured.
+func (s *Server) loadTLS() error {
+	if s.tlsCertFile == "" && s.tlsKeyFile == "" {
+		if s.tlsClientCAFile != "" || s.clientCertPermissions != "" {
+			return errors.New("client certificates require tls certificate and key files")
+		}
+		return nil
+	}
+
+	tlsOptions := []tlsconfig.Option{
+		tlsconfig.WithCertFiles(s.tlsCertFile, s.tlsKeyFile),
+		tlsconfig.WithClientCAFile(s.tlsClientCAFile),
+		tlsconfig.WithLogger(s.logger),
+	}
+	if s.tlsMinVersion != "" {
+		tlsOptions = append(tlsOptions, tlsconfig.WithMinVersion(s.tlsMinVersion))
+	}
+	if s.tlsClientAuth != "" {
+		tlsOptions = append(tlsOptions, tlsconfig.WithClientAuth(s.tlsClientAuth))
+	}
+
+	tlsConfig, err := tlsconfig.New(tlsOptions...)
+	if err != nil {
+		return fmt.Errorf("tls config err: %w", err)
+	}
+	s.tlsConfig = tlsConfig
+
+	if s.clientCertPermissions != "" {
+		if s.tlsClientCAFile == "" {
+			return errors.New("client certificate permissions require client ca file")
+		}
+
+		mapping, err := auth.LoadCertMapping(s.clientCertPermissions)
+		if err != nil {
+			return fmt.Errorf("load client certificate permissions err: %w", err)
+		}
+
+		s.logger.Info("client certificate authentication enabled", "subjects", mapping.Len())
+		s.certMapping = mapping
+	}
+
+	return nil
+}
+
+// routes builds http handler.
+func (s *Server) routes() http.Handler {
 	kvStoreHandler := kvstorehandler.New(
-		kvstorehandler.WithService(service),
+		kvstorehandler.WithService(s.service),
 		kvstorehandler.WithContextTimeout(ContextCancelTimeout),
-		kvstorehandler.WithServerEnv(apisrvr.serverEnv),
-		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithServerEnv(s.serverEnv),
+		kvstorehandler.WithLogger(s.logger),
 	)
 
 	mux := http.NewServeMux()
@@ -117,7 +553,7 @@ func New(options ...Option) error {
 		w.WriteHeader(http.StatusOK)
 
 		j, _ := json.Marshal(map[string]any{
-			"server":            apisrvr.serverEnv,
+			"server":            s.serverEnv,
 			"version":           releaseinfo.Version,
 			"build_information": releaseinfo.BuildInformation,
 			"message":           "liveness is OK!, server is ready to accept connections",
@@ -129,7 +565,7 @@ func New(options ...Option) error {
 		w.WriteHeader(http.StatusOK)
 
 		j, _ := json.Marshal(map[string]any{
-			"server":            apisrvr.serverEnv,
+			"server":            s.serverEnv,
 			"version":           releaseinfo.Version,
 			"build_information": releaseinfo.BuildInformation,
 			"message":           "readiness is OK!, server is ready to accept connections",
@@ -142,41 +578,230 @@ func New(options ...Option) error {
 	mux.HandleFunc(apiV1Prefix+"/update/", kvStoreHandler.Update)
 	mux.HandleFunc(apiV1Prefix+"/delete/", kvStoreHandler.Delete)
 	mux.HandleFunc(apiV1Prefix+"/list/", kvStoreHandler.List)
+	mux.HandleFunc(apiV1Prefix+"/txn/", kvStoreHandler.Txn)
+	mux.HandleFunc(apiV1Prefix+"/batch/", kvStoreHandler.Batch)
+	mux.HandleFunc(apiV1Prefix+"/ns/", kvStoreHandler.Namespaces)
+	mux.Handle(apiV1Prefix+"/watch/", streamMiddleware(s.streamsCtx, http.HandlerFunc(kvStoreHandler.Watch)))
+
+	var api http.Handler = mux
+	metrics := s.metrics.registry.Handler()
+	if s.authenticator != nil || s.certMapping != nil {
+		api = authMiddleware(s.authenticator, s.certMapping, mux)
+		if !s.publicMetrics {
+			metrics = authMiddleware(s.authenticator, s.certMapping, metrics)
+		}
+	}
+
+	root := http.NewServeMux()
+	root.Handle(metricsPath, metrics)
+	root.Handle("/", traceMiddleware(accessLogMiddleware(
+		s.logger,
+		s.accessLogSampleRate,
+		appendSlashMiddleware(httpMetricsMiddleware(s.metrics, mux, recoverMiddleware(s.logger, s.metrics, mux, api))),
+	)))
+
+	return root
+}
+
+// Handler returns http handler of the api and metrics, e.g. for mounting
+// into another server or httptest.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Addr returns http listen address, the actual address after Start.
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+// Err returns channel which receives error of a listener failing after
+// Start.
+func (s *Server) Err() <-chan error {
+	return s.errs
+}
+
+// Start starts background jobs and listeners then returns, ctx is used
+// only while listening.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("apiserver.Start server already started")
+	}
 
-	api := &http.Server{
-		Addr:         ":8000",
-		Handler:      appendSlashMiddleware(httpLoggingMiddleware(logger, mux)),
+	logger := s.logger
+
+	if s.listener == nil {
+		var lc net.ListenConfig
+
+		ln, err := lc.Listen(ctx, "tcp", s.addr)
+		if err != nil {
+			return fmt.Errorf("apiserver.Start lc.Listen err: %w", err)
+		}
+		s.listener = ln
+	}
+	s.started = true
+
+	s.persist.Start()
+
+	s.httpServer = &http.Server{
+		Handler:      s.handler,
+		TLSConfig:    s.tlsConfig,
 		ReadTimeout:  ServerReadTimeout,
 		WriteTimeout: ServerWriteTimeout,
 		IdleTimeout:  ServerIdleTimeout,
 	}
+	// in-flight requests are finished, only long-lived watch streams are
+	// ended on shutdown.
+	s.httpServer.RegisterOnShutdown(s.cancelStreams)
+
+	go func(ln net.Listener) {
+		logger.Info("starting api server", "listening", ln.Addr().String(), "env", s.serverEnv, "tls", s.tlsConfig != nil)
 
-	shutdown := make(chan os.Signal, 1)
-	apiError := make(chan error, 1)
-	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+		var err error
+		if s.tlsConfig != nil {
+			err = s.httpServer.ServeTLS(ln, "", "")
+		} else {
+			err = s.httpServer.Serve(ln)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- fmt.Errorf("api server err: %w", err)
+		}
+	}(s.listener)
 
-	go func() {
-		logger.Info("starting api server", "listening", api.Addr, "env", apisrvr.serverEnv)
-		apiError <- api.ListenAndServe()
-	}()
+	if s.respAddr != "" {
+		s.respServer = respserver.New(
+			respserver.WithAddr(s.respAddr),
+			respserver.WithService(s.service),
+			respserver.WithContextTimeout(ContextCancelTimeout),
+			respserver.WithLogger(logger),
+		)
 
-	select {
-	case err := <-apiError:
-		return fmt.Errorf("listen and server err: %w", err)
-	case sig := <-shutdown:
-		logger.Info("starting shutdown", "pid", sig)
-		defer logger.Info("shutdown completed", "pid", sig)
+		go func() {
+			logger.Info("starting resp server", "listening", s.respServer.Addr())
+			if err := s.respServer.ListenAndServe(); !errors.Is(err, respserver.ErrServerClosed) {
+				s.errs <- fmt.Errorf("resp server err: %w", err)
+			}
+		}()
+	}
 
-		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
-		defer cancel()
+	if s.memcacheAddr != "" {
+		s.memcacheServer = memcacheserver.New(
+			memcacheserver.WithAddr(s.memcacheAddr),
+			memcacheserver.WithService(s.service),
+			memcacheserver.WithContextTimeout(ContextCancelTimeout),
+			memcacheserver.WithLogger(logger),
+		)
 
-		if err := api.Shutdown(ctx); err != nil {
-			if errr := api.Close(); errr != nil {
+		go func() {
+			logger.Info("starting memcache server", "listening", s.memcacheServer.Addr())
+			if err := s.memcacheServer.ListenAndServe(); !errors.Is(err, memcacheserver.ErrServerClosed) {
+				s.errs <- fmt.Errorf("memcache server err: %w", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// Shutdown stops listeners gracefully, stops background jobs, saves the
+// final snapshot and closes append-only file. Only the first call does the
+// work, others return its result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.shutdownOnce.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.shutdownErr = s.shutdown(ctx)
+	})
+	return s.shutdownErr
+}
+
+func (s *Server) shutdown(ctx context.Context) error {
+	logger := s.logger
+
+	var errs []error
+
+	if s.httpServer != nil {
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			if errr := s.httpServer.Close(); errr != nil {
 				logger.Error("api close", "err", errr)
 			}
-			return fmt.Errorf("could not stop server gracefully: %w", err)
+			errs = append(errs, fmt.Errorf("api server shutdown err: %w", err))
 		}
+	} else if s.listener != nil {
+		_ = s.listener.Close()
 	}
 
-	return nil
+	if s.respServer != nil {
+		if err := s.respServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("resp server shutdown err: %w", err))
+		}
+	}
+
+	if s.memcacheServer != nil {
+		if err := s.memcacheServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("memcache server shutdown err: %w", err))
+		}
+	}
+
+	if err := s.persist.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run starts server and blocks until ctx is done, a signal is received (if
+// signal handling is enabled) or a listener fails, then shuts down.
+func (s *Server) Run(ctx context.Context) error {
+	slog.SetDefault(s.logger)
+
+	if err := s.Start(ctx); err != nil {
+		_ = s.Shutdown(context.Background())
+		return err
+	}
+
+	var shutdown chan os.Signal // nil channel blocks forever
+	if s.handleSignals {
+		shutdown = make(chan os.Signal, 1)
+		signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(shutdown)
+	}
+
+	var runErr error
+
+	select {
+	case err := <-s.errs:
+		runErr = fmt.Errorf("listen and server err: %w", err)
+	case sig := <-shutdown:
+		s.logger.Info("starting shutdown", "pid", sig)
+		defer s.logger.Info("shutdown completed", "pid", sig)
+	case <-ctx.Done():
+		s.logger.Info("starting shutdown", "reason", ctx.Err())
+		defer s.logger.Info("shutdown completed", "reason", ctx.Err())
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	if err := s.Shutdown(shutdownCtx); err != nil && runErr == nil {
+		runErr = fmt.Errorf("could not stop server gracefully: %w", err)
+	}
+
+	return runErr
+}
+
+// New instantiates new server instance and runs it until SIGINT or
+// SIGTERM.
+func New(options ...Option) error {
+	srvr, err := NewServer(options...)
+	if err != nil {
+		return err
+	}
+	return srvr.Run(context.Background())
 }
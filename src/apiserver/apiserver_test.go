@@ -0,0 +1,148 @@
+package apiserver_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/apiserver"
+)
+
+func TestServerLifecycle(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	addr := ln.Addr().String()
+	snapshotDir := filepath.Join(t.TempDir(), "snapshots")
+
+	srvr, err := apiserver.NewServer(
+		apiserver.WithListener(ln),
+		apiserver.WithLogger(discardLogger),
+		apiserver.WithSnapshotDir(snapshotDir),
+		apiserver.WithSignalHandling(false),
+	)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err = srvr.Start(context.Background()); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if srvr.Addr() != addr {
+		t.Errorf("wrong addr, want: %s, got: %s", addr, srvr.Addr())
+	}
+
+	resp, err := http.Get("http://" + addr + "/healthz/ready/") // nolint:noctx
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// long-lived watch stream is ended on shutdown.
+	watch, err := http.Get("http://" + addr + "/api/v1/watch/?prefix=") // nolint:noctx
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	defer func() { _ = watch.Body.Close() }()
+	if watch.StatusCode != http.StatusOK {
+		t.Errorf("wrong status code of watch, want: %d, got: %d", http.StatusOK, watch.StatusCode)
+	}
+
+	// in-flight request: body is sent after shutdown starts.
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	body := `{"key": "in-flight", "value": "done"}`
+	head := "POST /api/v1/set/ HTTP/1.1\r\nHost: " + addr + "\r\nContent-Type: application/json\r\n" +
+		"Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n"
+	if _, err = conn.Write([]byte(head + body[:10])); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond) // request is read by the server
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- srvr.Shutdown(context.Background())
+	}()
+
+	select {
+	case err = <-shutdown:
+		t.Fatalf("shutdown returned before in-flight request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err = conn.Write([]byte(body[10:])); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	resp, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("wrong status code of in-flight request, want: %d, got: %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	if err = <-shutdown; err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if _, err = io.ReadAll(watch.Body); err != nil {
+		t.Errorf("watch stream must be ended: %v", err)
+	}
+
+	if _, err = net.Dial("tcp", addr); err == nil {
+		t.Error("listener must be closed")
+	}
+
+	if files, _ := filepath.Glob(filepath.Join(snapshotDir, "*")); len(files) != 1 {
+		t.Errorf("shutdown snapshot must be saved, got: %v", files)
+	}
+
+	// second shutdown returns result of the first.
+	if err = srvr.Shutdown(context.Background()); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+}
+
+func TestProtocolListenersWithAuthentication(t *testing.T) {
+	keys := apiserver.WithAPIKeys(`[{"name": "ci", "key": "s3cr3t", "scopes": ["read"]}]`)
+
+	tcs := []struct {
+		testName string
+		options  []apiserver.Option
+	}{
+		{"redis", []apiserver.Option{keys, apiserver.WithRESPAddr("127.0.0.1:0")}},
+		{"memcached", []apiserver.Option{keys, apiserver.WithMemcacheAddr("127.0.0.1:0")}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			options := append(tc.options, apiserver.WithLogger(discardLogger), apiserver.WithSignalHandling(false))
+			if _, err := apiserver.NewServer(options...); err == nil {
+				t.Error("unauthenticated protocol listener must be refused")
+			}
+		})
+	}
+
+	if _, err := apiserver.NewServer(
+		apiserver.WithLogger(discardLogger),
+		apiserver.WithRESPAddr("127.0.0.1:0"),
+		apiserver.WithMemcacheAddr("127.0.0.1:0"),
+	); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+// APIKeyHeader is the header carrying api key, alternative to
+// "Authorization: Bearer <key>".
+const APIKeyHeader = "X-API-Key"
+
+// permission is what a request needs: a scope, the namespace and all keys
+// (or key prefixes) it touches.
+type permission struct {
+	scope         auth.Scope
+	namespace     string // empty if request is not bound to a namespace
+	allNamespaces bool   // request touches every namespace
+	keys          []string
+	prefixes      []string
+}
+
+// deniedFor returns why principal is not allowed, empty if it is.
+func (p permission) deniedFor(principal *auth.Principal) string {
+	if !principal.HasScope(p.scope) {
+		return "missing scope " + string(p.scope)
+	}
+	if p.allNamespaces && len(principal.Namespaces) > 0 {
+		return "all namespaces are not allowed"
+	}
+	if p.namespace != "" && !principal.AllowsNamespace(p.namespace) {
+		return "namespace '" + p.namespace + "' is not allowed"
+	}
+	for _, key := range p.keys {
+		if !principal.AllowsKey(key) {
+			return "key '" + key + "' is not allowed"
+		}
+	}
+	for _, prefix := range p.prefixes {
+		if !principal.AllowsPrefix(prefix) {
+			return "prefix '" + prefix + "' is not allowed"
+		}
+	}
+	return ""
+}
+
+// permissionOf returns permission needed by request. Keys of set, update,
+// txn and batch and name of created namespace are read from the body, which
+// is restored for the handler.
+func permissionOf(r *http.Request) (permission, error) {
+	if r.URL.Path == metricsPath {
+		// metrics are labeled by namespace.
+		return permission{scope: auth.ScopeAdmin, allNamespaces: true}, nil
+	}
+
+	p, err := keyPermissionOf(r)
+	if err != nil {
+		return permission{}, err
+	}
+	if p.namespace == "" {
+		p.namespace = namespaceOf(r)
+	}
+	return p, nil
+}
+
+// namespaceOf returns namespace of request, empty for namespace collection
+// (listing is filtered by the handler).
+func namespaceOf(r *http.Request) string {
+	path, ok := strings.CutPrefix(r.URL.Path, apiV1Prefix+"/ns/")
+	if !ok {
+		return kvstorage.DefaultNamespace
+	}
+	namespace, _, _ := strings.Cut(path, "/")
+	return namespace
+}
+
+// keyPermissionOf returns scope and keys needed by request.
+func keyPermissionOf(r *http.Request) (permission, error) {
+	path, ok := strings.CutPrefix(r.URL.Path, apiV1Prefix+"/")
+	if !ok {
+		return permission{scope: auth.ScopeRead}, nil
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if segments[0] == "ns" {
+		switch {
+		case len(segments) == 1 && r.Method == http.MethodGet:
+			return permission{scope: auth.ScopeRead}, nil
+		case len(segments) == 1 && r.Method == http.MethodPost:
+			var payload struct {
+				Name string `json:"name"`
+			}
+			if err := decodeBody(r, &payload); err != nil {
+				return permission{}, err
+			}
+			return permission{scope: auth.ScopeAdmin, namespace: payload.Name}, nil
+		case len(segments) <= 2:
+			return permission{scope: auth.ScopeAdmin}, nil
+		}
+		segments = segments[2:]
+	}
+
+	query := r.URL.Query()
+
+	switch segments[0] {
+	case "get":
+		return permission{scope: auth.ScopeRead, keys: []string{query.Get("key")}}, nil
+	case "delete":
+		return permission{scope: auth.ScopeWrite, keys: []string{query.Get("key")}}, nil
+	case "list":
+		return permission{scope: auth.ScopeRead, prefixes: []string{query.Get("prefix")}}, nil
+	case "watch":
+		if query.Has("key") {
+			return permission{scope: auth.ScopeRead, keys: []string{query.Get("key")}}, nil
+		}
+		return permission{scope: auth.ScopeRead, prefixes: []string{query.Get("prefix")}}, nil
+	case "set", "update":
+		var payload struct {
+			Key string `json:"key"`
+		}
+		if err := decodeBody(r, &payload); err != nil {
+			return permission{}, err
+		}
+		return permission{scope: auth.ScopeWrite, keys: []string{payload.Key}}, nil
+	case "txn":
+		var payload struct {
+			Compare []struct {
+				Key string `json:"key"`
+			} `json:"compare"`
+			Success []struct {
+				Op  string `json:"op"`
+				Key string `json:"key"`
+			} `json:"success"`
+			Failure []struct {
+				Op  string `json:"op"`
+				Key string `json:"key"`
+			} `json:"failure"`
+		}
+		if err := decodeBody(r, &payload); err != nil {
+			return permission{}, err
+		}
+
+		p := permission{scope: auth.ScopeRead}
+		for _, c := range payload.Compare {
+			p.keys = append(p.keys, c.Key)
+		}
+		for _, op := range append(payload.Success, payload.Failure...) {
+			p.keys = append(p.keys, op.Key)
+			if op.Op != "get" {
+				p.scope = auth.ScopeWrite
+			}
+		}
+		return p, nil
+	case "batch":
+		var payload struct {
+			Op    string `json:"op"`
+			Items []struct {
+				Key string `json:"key"`
+			} `json:"items"`
+		}
+		if err := decodeBody(r, &payload); err != nil {
+			return permission{}, err
+		}
+
+		p := permission{scope: auth.ScopeWrite}
+		if payload.Op == "get" {
+			p.scope = auth.ScopeRead
+		}
+		for _, item := range payload.Items {
+			p.keys = append(p.keys, item.Key)
+		}
+		return p, nil
+	}
+
+	return permission{scope: auth.ScopeRead}, nil
+}
+
+// decodeBody decodes json body into v and restores body for the handler.
+// Empty body is left to the handler to reject.
+func decodeBody(r *http.Request, v any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
+// credentialsOf returns bearer token or api key of request.
+func credentialsOf(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// authMiddleware authenticates api keys, bearer tokens or verified client
+// certificates (when request has no credentials) and checks scopes,
+// namespace and key prefixes of the request before handlers run, health
+// checks are public. Either authenticator or certs may be nil. Subject of
+// the principal and denial reasons are added to the access log, principal
+// is set on the request context.
+func authMiddleware(authenticator auth.Authenticator, certs *auth.CertMapping, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/healthz/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var principal *auth.Principal
+		var err error
+
+		credentials := credentialsOf(r)
+		switch {
+		case credentials == "" && certs != nil && r.TLS != nil && len(r.TLS.VerifiedChains) > 0:
+			principal = certs.AuthenticateCert(r.TLS.VerifiedChains[0][0])
+		case credentials == "":
+			err = auth.ErrNoCredentials
+		case authenticator == nil:
+			err = auth.ErrInvalidCredentials
+		default:
+			principal, err = authenticator.Authenticate(credentials)
+		}
+		if err != nil {
+			addAccessLogAttrs(r, slog.String("auth_error", err.Error()))
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kvstore"`)
+			basehttphandler.WriteProblem(w, basehttphandler.NewProblem(r, &basehttphandler.Error{
+				Status:  http.StatusUnauthorized,
+				Code:    basehttphandler.CodeUnauthorized,
+				Message: err.Error(),
+			}))
+			return
+		}
+		addAccessLogAttrs(r, slog.String("subject", principal.Subject))
+
+		perm, err := permissionOf(r)
+		if err != nil {
+			basehttphandler.WriteProblem(w, basehttphandler.NewProblem(r, basehttphandler.BadRequest(err.Error())))
+			return
+		}
+
+		if reason := perm.deniedFor(principal); reason != "" {
+			addAccessLogAttrs(r, slog.String("auth_error", reason))
+
+			basehttphandler.WriteProblem(w, basehttphandler.NewProblem(r, &basehttphandler.Error{
+				Status:  http.StatusForbidden,
+				Code:    basehttphandler.CodeForbidden,
+				Message: reason,
+			}))
+			return
+		}
+
+		h.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,215 @@
+package apiserver_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/apiserver"
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+// newAuthHandler returns authMiddleware of test keys and certificate
+// mapping, allowed requests are answered with subject of the principal
+// (empty for public requests) and the request body.
+func newAuthHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	keys, err := auth.ParseKeys([]byte(`[
+		{"name": "reader", "key": "r", "scopes": ["read"]},
+		{"name": "writer", "key": "w", "scopes": ["write"], "prefixes": ["app/"]},
+		{"name": "admin", "key": "a", "scopes": ["admin"], "namespaces": ["team-a"]},
+		{"name": "root", "key": "o", "scopes": ["admin"]}
+	]`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	certs, err := auth.ParseCertMapping([]byte(`[
+		{"subject": "billing", "scopes": ["write"], "namespaces": ["billing"]}
+	]`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var subject string
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			subject = principal.Subject
+		}
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(subject + " " + string(body)))
+	})
+
+	return apiserver.AuthMiddleware(keys, certs, next)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	h := newAuthHandler(t)
+
+	billing := &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}}
+	unmapped := &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}}
+
+	tcs := []struct {
+		testName    string
+		method      string
+		target      string
+		body        string
+		credentials string
+		cert        *x509.Certificate // verified client certificate
+		wantStatus  int
+		wantSubject string // subject of allowed requests
+	}{
+		{"healthz is public", http.MethodGet, "/healthz/live/", "", "", nil, http.StatusOK, ""},
+		{"no credentials", http.MethodGet, "/api/v1/get/?key=a", "", "", nil, http.StatusUnauthorized, ""},
+		{"invalid key", http.MethodGet, "/api/v1/get/?key=a", "", "x", nil, http.StatusUnauthorized, ""},
+
+		{"read scope reads", http.MethodGet, "/api/v1/get/?key=a", "", "r", nil, http.StatusOK, "reader"},
+		{"read scope can not write", http.MethodPost, "/api/v1/set/", `{"key": "a"}`, "r", nil, http.StatusForbidden, ""},
+		{"read scope can not delete", http.MethodDelete, "/api/v1/delete/?key=a", "", "r", nil, http.StatusForbidden, ""},
+		{"write scope reads", http.MethodGet, "/api/v1/get/?key=app/a", "", "w", nil, http.StatusOK, "writer"},
+		{"write scope can not create namespace", http.MethodPost, "/api/v1/ns/", `{"name": "team-a"}`, "w", nil, http.StatusForbidden, ""},
+		{"write scope can not drop namespace", http.MethodDelete, "/api/v1/ns/team-a/", "", "w", nil, http.StatusForbidden, ""},
+
+		{"set key of body allowed", http.MethodPost, "/api/v1/set/", `{"key": "app/a"}`, "w", nil, http.StatusOK, "writer"},
+		{"set key of body denied", http.MethodPost, "/api/v1/set/", `{"key": "other"}`, "w", nil, http.StatusForbidden, ""},
+		{"update key of body denied", http.MethodPut, "/api/v1/update/", `{"key": "other"}`, "w", nil, http.StatusForbidden, ""},
+		{"invalid body", http.MethodPost, "/api/v1/set/", `{`, "w", nil, http.StatusBadRequest, ""},
+		{"get key denied", http.MethodGet, "/api/v1/get/?key=other", "", "w", nil, http.StatusForbidden, ""},
+		{"list prefix allowed", http.MethodGet, "/api/v1/list/?prefix=app/x", "", "w", nil, http.StatusOK, "writer"},
+		{"list all denied", http.MethodGet, "/api/v1/list/", "", "w", nil, http.StatusForbidden, ""},
+		{"watch key denied", http.MethodGet, "/api/v1/watch/?key=other", "", "w", nil, http.StatusForbidden, ""},
+		{"watch prefix denied", http.MethodGet, "/api/v1/watch/?prefix=", "", "w", nil, http.StatusForbidden, ""},
+
+		{"txn keys allowed", http.MethodPost, "/api/v1/txn/", `{"compare": [{"key": "app/a"}], "success": [{"op": "put", "key": "app/b"}]}`, "w", nil, http.StatusOK, "writer"},
+		{"txn compare key denied", http.MethodPost, "/api/v1/txn/", `{"compare": [{"key": "other"}], "success": [{"op": "put", "key": "app/b"}]}`, "w", nil, http.StatusForbidden, ""},
+		{"txn failure key denied", http.MethodPost, "/api/v1/txn/", `{"success": [{"op": "put", "key": "app/b"}], "failure": [{"op": "get", "key": "other"}]}`, "w", nil, http.StatusForbidden, ""},
+		{"txn of gets is read", http.MethodPost, "/api/v1/txn/", `{"success": [{"op": "get", "key": "a"}]}`, "r", nil, http.StatusOK, "reader"},
+		{"txn put needs write", http.MethodPost, "/api/v1/txn/", `{"success": [{"op": "get", "key": "a"}], "failure": [{"op": "put", "key": "a"}]}`, "r", nil, http.StatusForbidden, ""},
+		{"batch keys allowed", http.MethodPost, "/api/v1/batch/", `{"op": "set", "items": [{"key": "app/a"}, {"key": "app/b"}]}`, "w", nil, http.StatusOK, "writer"},
+		{"batch key denied", http.MethodPost, "/api/v1/batch/", `{"op": "set", "items": [{"key": "app/a"}, {"key": "other"}]}`, "w", nil, http.StatusForbidden, ""},
+		{"batch get is read", http.MethodPost, "/api/v1/batch/", `{"op": "get", "items": [{"key": "a"}]}`, "r", nil, http.StatusOK, "reader"},
+		{"batch set needs write", http.MethodPost, "/api/v1/batch/", `{"op": "set", "items": [{"key": "a"}]}`, "r", nil, http.StatusForbidden, ""},
+
+		{"namespace allowed", http.MethodGet, "/api/v1/ns/team-a/get/?key=a", "", "a", nil, http.StatusOK, "admin"},
+		{"namespace denied", http.MethodGet, "/api/v1/ns/team-b/get/?key=a", "", "a", nil, http.StatusForbidden, ""},
+		{"default namespace denied", http.MethodGet, "/api/v1/get/?key=a", "", "a", nil, http.StatusForbidden, ""},
+		{"namespace list", http.MethodGet, "/api/v1/ns/", "", "r", nil, http.StatusOK, "reader"},
+		{"create allowed namespace", http.MethodPost, "/api/v1/ns/", `{"name": "team-a"}`, "a", nil, http.StatusOK, "admin"},
+		{"create denied namespace", http.MethodPost, "/api/v1/ns/", `{"name": "team-b"}`, "a", nil, http.StatusForbidden, ""},
+		{"drop allowed namespace", http.MethodDelete, "/api/v1/ns/team-a/", "", "a", nil, http.StatusOK, "admin"},
+		{"drop denied namespace", http.MethodDelete, "/api/v1/ns/team-b/", "", "a", nil, http.StatusForbidden, ""},
+
+		{"metrics need credentials", http.MethodGet, "/metrics", "", "", nil, http.StatusUnauthorized, ""},
+		{"metrics need admin scope", http.MethodGet, "/metrics", "", "r", nil, http.StatusForbidden, ""},
+		{"metrics need all namespaces", http.MethodGet, "/metrics", "", "a", nil, http.StatusForbidden, ""},
+		{"metrics allowed", http.MethodGet, "/metrics", "", "o", nil, http.StatusOK, "root"},
+
+		{"certificate allowed", http.MethodGet, "/api/v1/ns/billing/get/?key=a", "", "", billing, http.StatusOK, "CN=billing"},
+		{"certificate namespace denied", http.MethodGet, "/api/v1/get/?key=a", "", "", billing, http.StatusForbidden, ""},
+		{"unmapped certificate", http.MethodGet, "/api/v1/ns/billing/get/?key=a", "", "", unmapped, http.StatusForbidden, ""},
+		{"credentials precede certificate", http.MethodGet, "/api/v1/get/?key=a", "", "r", billing, http.StatusOK, "reader"},
+		{"invalid credentials with certificate", http.MethodGet, "/api/v1/get/?key=a", "", "x", billing, http.StatusUnauthorized, ""},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.credentials != "" {
+				req.Header.Set(apiserver.APIKeyHeader, tc.credentials)
+			}
+			if tc.cert != nil {
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{tc.cert}}}
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("wrong status code, want: %d, got: %d (%s)", tc.wantStatus, w.Code, w.Body.String())
+			}
+
+			switch tc.wantStatus {
+			case http.StatusOK:
+				// body read for the permission check is restored for the handler.
+				if want := tc.wantSubject + " " + tc.body; w.Body.String() != want {
+					t.Errorf("wrong body, want: %q, got: %q", want, w.Body.String())
+				}
+			case http.StatusUnauthorized:
+				if w.Header().Get("WWW-Authenticate") == "" {
+					t.Error("WWW-Authenticate header must be set")
+				}
+				problemShouldHaveCode(t, w, basehttphandler.CodeUnauthorized)
+			case http.StatusForbidden:
+				problemShouldHaveCode(t, w, basehttphandler.CodeForbidden)
+			}
+		})
+	}
+}
+
+func problemShouldHaveCode(t *testing.T, w *httptest.ResponseRecorder, code string) {
+	t.Helper()
+
+	var problem basehttphandler.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if problem.Code != code {
+		t.Errorf("wrong code, want: %s, got: %s", code, problem.Code)
+	}
+}
+
+func TestAuthBearerToken(t *testing.T) {
+	h := newAuthHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/get/?key=a", nil)
+	req.Header.Set("Authorization", "Bearer r")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestMetricsAuth(t *testing.T) {
+	keys := apiserver.WithAPIKeys(`[{"name": "root", "key": "o", "scopes": ["admin"]}]`)
+
+	tcs := []struct {
+		testName    string
+		options     []apiserver.Option
+		credentials string
+		wantStatus  int
+	}{
+		{"public without auth", nil, "", http.StatusOK},
+		{"protected with auth", []apiserver.Option{keys}, "", http.StatusUnauthorized},
+		{"allowed to admin", []apiserver.Option{keys}, "o", http.StatusOK},
+		{"public by option", []apiserver.Option{keys, apiserver.WithPublicMetrics("true")}, "", http.StatusOK},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			h := newHandler(t, tc.options...)
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tc.credentials != "" {
+				req.Header.Set(apiserver.APIKeyHeader, tc.credentials)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("wrong status code, want: %d, got: %d", tc.wantStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package apiserver
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+// middlewares exported for tests.
+var (
+	AccessLogMiddleware = accessLogMiddleware
+	AuthMiddleware      = authMiddleware
+	TraceMiddleware     = traceMiddleware
+)
+
+// RecoverMiddleware returns recoverMiddleware of h mounted on "/", metrics
+// handler serves the panic counter.
+func RecoverMiddleware(l *slog.Logger, h http.Handler) (http.Handler, http.Handler) {
+	mux := http.NewServeMux()
+	mux.Handle("/", h)
+
+	m := newServerMetrics(kvstorage.New())
+	return recoverMiddleware(l, m, mux, h), m.registry.Handler()
+}
@@ -0,0 +1,109 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/metrics"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+// errorLabel returns metric label of err: kverror code, "timeout",
+// "canceled" or "unknown".
+func errorLabel(err error) string {
+	if code := kverror.CodeOf(err); code != "" {
+		return string(code)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// serverMetrics holds metrics of http api and service operations.
+type serverMetrics struct {
+	registry *metrics.Registry
+
+	requests        *metrics.Counter
+	requestDuration *metrics.Histogram
+	operations      *metrics.Counter
+	operationErrors *metrics.Counter
+	panics          *metrics.Counter
+}
+
+func newServerMetrics(storage kvstorage.Storer) *serverMetrics {
+	registry := metrics.NewRegistry()
+
+	m := &serverMetrics{
+		registry: registry,
+		requests: registry.NewCounter(
+			"kvstore_http_requests_total",
+			"Number of http requests by method, route and status code.",
+			"method", "route", "code",
+		),
+		requestDuration: registry.NewHistogram(
+			"kvstore_http_request_duration_seconds",
+			"Latency of http requests by method, route and status code.",
+			metrics.DefaultBuckets,
+			"method", "route", "code",
+		),
+		operations: registry.NewCounter(
+			"kvstore_operations_total",
+			"Number of storage operations of all transports by operation.",
+			"op",
+		),
+		operationErrors: registry.NewCounter(
+			"kvstore_operation_errors_total",
+			"Number of failed storage operations by operation and error.",
+			"op", "error",
+		),
+		panics: registry.NewCounter(
+			"kvstore_http_panics_total",
+			"Number of recovered panics of http handlers by route.",
+			"route",
+		),
+	}
+
+	registry.NewGaugeFunc("kvstore_keys", "Number of keys by namespace, including expired keys not removed yet.", func() []metrics.Sample {
+		stats := storage.Stats()
+		samples := make([]metrics.Sample, len(stats))
+		for i, s := range stats {
+			samples[i] = metrics.Sample{LabelValues: []string{s.Name}, Value: float64(s.Keys)}
+		}
+		return samples
+	}, "namespace")
+
+	registry.NewGaugeFunc("kvstore_bytes", "Approximate size of keys and values by namespace.", func() []metrics.Sample {
+		stats := storage.Stats()
+		samples := make([]metrics.Sample, len(stats))
+		for i, s := range stats {
+			samples[i] = metrics.Sample{LabelValues: []string{s.Name}, Value: float64(s.Bytes)}
+		}
+		return samples
+	}, "namespace")
+
+	registry.RegisterGoCollector()
+
+	return m
+}
+
+// observeOperation is the kvstoreservice.Observer of service operations.
+func (m *serverMetrics) observeOperation(op string, err error) {
+	m.operations.Inc(op)
+	if err != nil {
+		m.operationErrors.Inc(op, errorLabel(err))
+	}
+}
+
+// observeRequest records http request.
+func (m *serverMetrics) observeRequest(method, route string, status int, seconds float64) {
+	code := strconv.Itoa(status)
+	m.requests.Inc(method, route, code)
+	m.requestDuration.Observe(seconds, method, route, code)
+}
@@ -1,19 +1,143 @@
 package apiserver
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand"
 	"net/http"
+	"runtime/debug"
 	"strings"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 )
 
-func httpLoggingMiddleware(l *slog.Logger, h http.Handler) http.Handler {
+// responseWriter records status code and number of bytes written. Unwrap
+// lets http.ResponseController reach the underlying writer (e.g. Flush of
+// watch streams).
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap returns the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns written status code, 200 if nothing is written.
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// accessLogEntry collects attributes which inner middlewares add to the
+// access log line of the request, e.g. authenticated subject.
+type accessLogEntry struct {
+	attrs []slog.Attr
+}
+
+type accessLogEntryKey struct{}
+
+// addAccessLogAttrs adds attrs to access log line of r, no-op if r is not
+// logged.
+func addAccessLogAttrs(r *http.Request, attrs ...slog.Attr) {
+	if entry, ok := r.Context().Value(accessLogEntryKey{}).(*accessLogEntry); ok {
+		entry.attrs = append(entry.attrs, attrs...)
+	}
+}
+
+// accessLogMiddleware logs every request with its outcome, request id and
+// trace ids are added by the logger. Level is chosen
+// by status class: error for 5xx, warn for 4xx, info otherwise. Successful
+// (2xx) requests are logged with probability of sampleRate.
+func accessLogMiddleware(l *slog.Logger, sampleRate float64, h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		h.ServeHTTP(w, r)
+		start := time.Now()
+
+		entry := &accessLogEntry{}
+		rw := &responseWriter{ResponseWriter: w}
+		h.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), accessLogEntryKey{}, entry)))
+
+		status := rw.statusCode()
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case status >= http.StatusOK && status < http.StatusMultipleChoices:
+			if sampleRate < 1 && rand.Float64() >= sampleRate { // nolint:gosec
+				return
+			}
+		}
+
+		attrs := append([]slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("uri", r.URL.String()),
+			slog.Int("status", status),
+			slog.Int64("bytes", rw.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		}, entry.attrs...)
+
+		l.LogAttrs(r.Context(), level, "http request", attrs...)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// traceMiddleware accepts or generates request id, continues or starts
+// W3C trace of the request and puts them on the request context. Both are
+// sent back as response headers.
+func traceMiddleware(h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		trace := tracing.New(r.Header.Get(tracing.RequestIDHeader), r.Header.Get(tracing.TraceparentHeader))
+
+		w.Header().Set(tracing.RequestIDHeader, trace.RequestID)
+		w.Header().Set(tracing.TraceparentHeader, trace.Traceparent())
+
+		h.ServeHTTP(w, r.WithContext(tracing.NewContext(r.Context(), trace)))
+	}
 
-		uri := r.URL.String()
-		method := r.Method
+	return http.HandlerFunc(fn)
+}
+
+// streamMiddleware cancels request context when ctx is done, ends
+// long-lived streams on shutdown.
+func streamMiddleware(ctx context.Context, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		reqCtx, cancel := context.WithCancel(r.Context())
+		defer cancel()
 
-		l.Info("http request", "method", method, "uri", uri)
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+
+		h.ServeHTTP(w, r.WithContext(reqCtx))
 	}
 
 	return http.HandlerFunc(fn)
@@ -33,3 +157,91 @@ func appendSlashMiddleware(h http.Handler) http.Handler {
 	}
 	return http.HandlerFunc(fn)
 }
+
+// routeOf returns pattern of mux matching the request, used as metric label.
+func routeOf(mux *http.ServeMux, r *http.Request) string {
+	if _, route := mux.Handler(r); route != "" {
+		return route
+	}
+	return "unmatched"
+}
+
+// knownMethods are methods used as metric label as is.
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// methodOf returns method of the request as metric label, arbitrary
+// methods of clients are reported as "other" to bound label cardinality.
+func methodOf(r *http.Request) string {
+	if knownMethods[r.Method] {
+		return r.Method
+	}
+	return "other"
+}
+
+// httpMetricsMiddleware records requests by method and route.
+func httpMetricsMiddleware(m *serverMetrics, mux *http.ServeMux, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &responseWriter{ResponseWriter: w}
+
+		h.ServeHTTP(rw, r)
+
+		m.observeRequest(methodOf(r), routeOf(mux, r), rw.statusCode(), time.Since(start).Seconds())
+	}
+	return http.HandlerFunc(fn)
+}
+
+// recoverMiddleware recovers panics of handlers, logs them with stack and
+// responds with json 500 unless response is already started.
+// http.ErrAbortHandler is re-panicked, it is the way to abort a response.
+func recoverMiddleware(l *slog.Logger, m *serverMetrics, mux *http.ServeMux, h http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+
+			m.panics.Inc(routeOf(mux, r))
+
+			l.ErrorContext(
+				r.Context(),
+				"http handler panic",
+				"panic", fmt.Sprint(v),
+				"method", r.Method,
+				"uri", r.URL.String(),
+				"stack", string(debug.Stack()),
+			)
+
+			if rw.status != 0 {
+				// response is already started, abort it to close connection.
+				panic(http.ErrAbortHandler)
+			}
+
+			basehttphandler.WriteProblem(w, basehttphandler.NewProblem(r, &basehttphandler.Error{
+				Status:  http.StatusInternalServerError,
+				Code:    basehttphandler.CodeInternal,
+				Message: "internal server error",
+			}))
+		}()
+
+		h.ServeHTTP(rw, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
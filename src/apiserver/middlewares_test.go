@@ -0,0 +1,366 @@
+package apiserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/apiserver"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newHandler returns handler of server with in-memory storage, logging to
+// discardLogger unless options set another logger.
+func newHandler(t *testing.T, options ...apiserver.Option) http.Handler {
+	t.Helper()
+
+	options = append([]apiserver.Option{
+		apiserver.WithLogger(discardLogger),
+		apiserver.WithStorage(kvstorage.New()),
+	}, options...)
+
+	srvr, err := apiserver.NewServer(options...)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return srvr.Handler()
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestMetricsMethodLabel(t *testing.T) {
+	h := newHandler(t)
+
+	serve(h, http.MethodGet, "/api/v1/get/?key=a")
+	serve(h, "FOO", "/api/v1/get/?key=a")
+	serve(h, "X-RANDOM-1", "/api/v1/get/?key=a")
+
+	metrics := serve(h, http.MethodGet, "/metrics").Body.String()
+
+	for _, want := range []string{
+		`kvstore_http_requests_total{method="GET",route="/api/v1/get/",code="404"} 1`,
+		`kvstore_http_requests_total{method="other",route="/api/v1/get/",code="405"} 2`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics must contain %s, got: %s", want, metrics)
+		}
+	}
+	for _, method := range []string{"FOO", "X-RANDOM-1"} {
+		if strings.Contains(metrics, `method="`+method+`"`) {
+			t.Errorf("method %s must not be a label, got: %s", method, metrics)
+		}
+	}
+}
+
+// logRecords decodes json log lines.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// statusHandler responds with status given in status query param.
+var statusHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte("body"))
+})
+
+func TestAccessLogLevels(t *testing.T) {
+	var buf bytes.Buffer
+	h := apiserver.AccessLogMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)), 1, statusHandler)
+
+	tcs := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusOK, "INFO"},
+		{http.StatusPermanentRedirect, "INFO"},
+		{http.StatusNotFound, "WARN"},
+		{http.StatusServiceUnavailable, "ERROR"},
+	}
+
+	for _, tc := range tcs {
+		buf.Reset()
+		serve(h, http.MethodGet, "/api/v1/get/?status="+strconv.Itoa(tc.status))
+
+		records := logRecords(t, &buf)
+		if len(records) != 1 {
+			t.Fatalf("want: 1 access log line, got: %d", len(records))
+		}
+		record := records[0]
+
+		if record["level"] != tc.level {
+			t.Errorf("wrong level of %d, want: %s, got: %v", tc.status, tc.level, record["level"])
+		}
+		if record["status"] != float64(tc.status) {
+			t.Errorf("wrong status, want: %d, got: %v", tc.status, record["status"])
+		}
+		if record["method"] != http.MethodGet || record["bytes"] != float64(4) {
+			t.Errorf("wrong access log line: %v", record)
+		}
+	}
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+
+	count := func(rate float64, status int) int {
+		buf.Reset()
+		h := apiserver.AccessLogMiddleware(slog.New(slog.NewJSONHandler(&buf, nil)), rate, statusHandler)
+		for i := 0; i < 1000; i++ {
+			serve(h, http.MethodGet, "/?status="+strconv.Itoa(status))
+		}
+		return len(logRecords(t, &buf))
+	}
+
+	if n := count(0, http.StatusOK); n != 0 {
+		t.Errorf("successful requests must not be logged with rate 0, got: %d", n)
+	}
+	if n := count(1, http.StatusOK); n != 1000 {
+		t.Errorf("all successful requests must be logged with rate 1, got: %d", n)
+	}
+	if n := count(0.5, http.StatusOK); n < 350 || n > 650 {
+		t.Errorf("about half of successful requests must be logged with rate 0.5, got: %d", n)
+	}
+
+	// errors are always logged.
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError} {
+		if n := count(0, status); n != 1000 {
+			t.Errorf("all %d responses must be logged, got: %d", status, n)
+		}
+	}
+}
+
+func TestAccessLogSampleRateOption(t *testing.T) {
+	var buf bytes.Buffer
+	h := newHandler(
+		t,
+		apiserver.WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+		apiserver.WithAccessLogSampleRate("0"),
+	)
+
+	serve(h, http.MethodGet, "/healthz/live/")
+	serve(h, http.MethodGet, "/api/v1/get/?key=missing")
+
+	var accessLogs []map[string]any
+	for _, record := range logRecords(t, &buf) {
+		if record["msg"] == "http request" {
+			accessLogs = append(accessLogs, record)
+		}
+	}
+	if len(accessLogs) != 1 || accessLogs[0]["status"] != float64(http.StatusNotFound) {
+		t.Errorf("only not found response must be logged, got: %v", accessLogs)
+	}
+	if len(accessLogs) == 1 && accessLogs[0]["request_id"] == nil {
+		t.Errorf("access log line must have request id: %v", accessLogs[0])
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+
+	var got tracing.Trace
+	h := apiserver.TraceMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got, _ = tracing.FromContext(r.Context())
+	}))
+
+	tcs := []struct {
+		name        string
+		requestID   string
+		traceparent string
+		keepID      bool
+		continued   bool
+		sampled     bool
+	}{
+		{"continued", "req-1", "00-" + traceID + "-" + parentID + "-00", true, true, false},
+		{"continued sampled", "req-2", "00-" + traceID + "-" + parentID + "-01", true, true, true},
+		{"new", "", "", false, false, true},
+		{"invalid", strings.Repeat("a", tracing.MaxRequestIDLength+1), "00-" + traceID + "-0000000000000000-01", false, false, true},
+	}
+
+	for _, tc := range tcs {
+		got = tracing.Trace{}
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.requestID != "" {
+			req.Header.Set(tracing.RequestIDHeader, tc.requestID)
+		}
+		if tc.traceparent != "" {
+			req.Header.Set(tracing.TraceparentHeader, tc.traceparent)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if tc.keepID && got.RequestID != tc.requestID {
+			t.Errorf("%s: wrong request id, want: %s, got: %s", tc.name, tc.requestID, got.RequestID)
+		}
+		if !tc.keepID && (got.RequestID == "" || got.RequestID == tc.requestID) {
+			t.Errorf("%s: request id must be generated, got: %s", tc.name, got.RequestID)
+		}
+		if header := w.Header().Get(tracing.RequestIDHeader); header != got.RequestID {
+			t.Errorf("%s: wrong request id header, want: %s, got: %s", tc.name, got.RequestID, header)
+		}
+
+		if tc.continued && (got.TraceID != traceID || got.ParentSpanID != parentID) {
+			t.Errorf("%s: trace must be continued, got: %+v", tc.name, got)
+		}
+		if !tc.continued && (got.TraceID == "" || got.TraceID == traceID || got.ParentSpanID != "") {
+			t.Errorf("%s: new trace must be started, got: %+v", tc.name, got)
+		}
+		if got.SpanID == "" || got.SpanID == parentID {
+			t.Errorf("%s: span of server must be new, got: %s", tc.name, got.SpanID)
+		}
+		if got.Sampled != tc.sampled {
+			t.Errorf("%s: wrong sampled, want: %t, got: %t", tc.name, tc.sampled, got.Sampled)
+		}
+
+		flags := "00"
+		if tc.sampled {
+			flags = "01"
+		}
+		want := "00-" + got.TraceID + "-" + got.SpanID + "-" + flags
+		if header := w.Header().Get(tracing.TraceparentHeader); header != want {
+			t.Errorf("%s: wrong traceparent header, want: %s, got: %s", tc.name, want, header)
+		}
+	}
+}
+
+func TestTraceInProblem(t *testing.T) {
+	h := newHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/get/?key=missing", nil)
+	req.Header.Set(tracing.RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if header := w.Header().Get(tracing.RequestIDHeader); header != "req-1" {
+		t.Errorf("wrong request id header, want: %s, got: %s", "req-1", header)
+	}
+	if _, _, _, ok := tracing.ParseTraceparent(w.Header().Get(tracing.TraceparentHeader)); !ok {
+		t.Errorf("invalid traceparent header: %s", w.Header().Get(tracing.TraceparentHeader))
+	}
+
+	var p basehttphandler.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if p.RequestID != "req-1" {
+		t.Errorf("wrong problem request id, want: %s, got: %s", "req-1", p.RequestID)
+	}
+}
+
+// servePanic serves request, returns response and re-panicked value.
+func servePanic(h http.Handler) (w *httptest.ResponseRecorder, repanicked any) {
+	w = httptest.NewRecorder()
+
+	defer func() {
+		repanicked = recover()
+	}()
+
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/get/?key=a", nil))
+	return w, nil
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	h, metrics := apiserver.RecoverMiddleware(logger, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	w, repanicked := servePanic(h)
+	if repanicked != nil {
+		t.Fatalf("panic must be recovered, got: %v", repanicked)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusInternalServerError, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != basehttphandler.ProblemContentType {
+		t.Errorf("wrong content type, want: %s, got: %s", basehttphandler.ProblemContentType, contentType)
+	}
+
+	var p basehttphandler.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if p.Code != basehttphandler.CodeInternal || p.Status != http.StatusInternalServerError {
+		t.Errorf("wrong problem: %+v", p)
+	}
+	if strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("panic value must not be exposed: %s", w.Body.String())
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 1 || records[0]["panic"] != "boom" || records[0]["stack"] == "" {
+		t.Errorf("panic must be logged with stack, got: %v", records)
+	}
+
+	want := `kvstore_http_panics_total{route="/"} 1`
+	if got := serve(metrics, http.MethodGet, "/metrics").Body.String(); !strings.Contains(got, want) {
+		t.Errorf("metrics must contain %s, got: %s", want, got)
+	}
+}
+
+func TestRecoverMiddlewareAbort(t *testing.T) {
+	tcs := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"abort handler", func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}},
+		{"wrapped abort handler", func(http.ResponseWriter, *http.Request) {
+			panic(fmt.Errorf("stream err: %w", http.ErrAbortHandler))
+		}},
+		{"started response", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			panic("boom")
+		}},
+	}
+
+	for _, tc := range tcs {
+		h, _ := apiserver.RecoverMiddleware(discardLogger, tc.handler)
+
+		w, repanicked := servePanic(h)
+
+		err, _ := repanicked.(error)
+		if !errors.Is(err, http.ErrAbortHandler) {
+			t.Errorf("%s: wrong panic, want: %v, got: %v", tc.name, http.ErrAbortHandler, repanicked)
+		}
+		if w.Code == http.StatusInternalServerError {
+			t.Errorf("%s: problem must not be written", tc.name)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Package client is the Go client of kvstore http api.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaults.
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultRetries    = 3
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 2 * time.Second
+
+	apiV1Prefix = "/api/v1"
+)
+
+// Client is kvstore http api client, safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	timeout    time.Duration
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	namespace  string
+	token      string
+}
+
+// Option represents client option type.
+type Option func(*Client)
+
+// WithHTTPClient sets http client option.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTimeout sets timeout of each attempt, zero means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetries sets maximum number of retries after the first attempt, zero
+// disables retries.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// WithBackoff sets exponential backoff bounds between retries.
+func WithBackoff(minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithToken sets token option, sent as "Authorization: Bearer <token>" to
+// servers requiring authentication.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// New instantiates new client for server at baseURL, e.g.
+// "http://localhost:8000".
+func New(baseURL string, options ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("client.New url.Parse err: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("client.New invalid base url: %q", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		timeout:    DefaultTimeout,
+		retries:    DefaultRetries,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+
+	for _, o := range options {
+		o(c)
+	}
+
+	return c, nil
+}
+
+// Namespace returns a client bound to given namespace, empty name means the
+// default namespace.
+func (c *Client) Namespace(name string) *Client {
+	nc := *c
+	nc.namespace = name
+	return &nc
+}
+
+// endpoint returns url of given api endpoint, e.g. "get".
+func (c *Client) endpoint(name string, query url.Values) string {
+	u := *c.baseURL
+
+	u.Path += apiV1Prefix
+	if c.namespace != "" {
+		u.Path += "/ns/" + url.PathEscape(c.namespace)
+	}
+	u.Path += "/" + name + "/"
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// do sends request, retries transient failures and decodes response body
+// into out (if given). Requests which may have been processed are retried
+// only for safe methods.
+func (c *Client) do(ctx context.Context, method, name string, query url.Values, in, out any) (http.Header, error) {
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return nil, fmt.Errorf("client json.Marshal err: %w", err)
+		}
+	}
+
+	endpoint := c.endpoint(name, query)
+
+	for attempt := 0; ; attempt++ {
+		header, body, statusCode, err := c.send(ctx, method, endpoint, payload)
+		if err != nil {
+			if attempt < c.retries && ctx.Err() == nil && retryableError(method, err) {
+				if err = c.wait(ctx, attempt, nil); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		if statusCode >= http.StatusBadRequest {
+			if attempt < c.retries && retryableStatus(method, statusCode) {
+				if err = c.wait(ctx, attempt, header); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			return header, newError(statusCode, body)
+		}
+
+		if out != nil && len(body) > 0 {
+			if err = json.Unmarshal(body, out); err != nil {
+				return header, fmt.Errorf("client json.Unmarshal err: %w", err)
+			}
+		}
+		return header, nil
+	}
+}
+
+// send makes a single attempt.
+func (c *Client) send(ctx context.Context, method, endpoint string, payload []byte) (http.Header, []byte, int, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("client http.NewRequestWithContext err: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("client httpClient.Do err: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("client io.ReadAll err: %w", err)
+	}
+
+	return resp.Header, respBody, resp.StatusCode, nil
+}
+
+// wait sleeps before the next attempt, Retry-After header (seconds) wins
+// over exponential backoff if given.
+func (c *Client) wait(ctx context.Context, attempt int, header http.Header) error {
+	delay := c.minBackoff << attempt
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	if delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)) // nolint:gosec
+	}
+
+	if header != nil {
+		if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+			if delay > c.maxBackoff {
+				delay = c.maxBackoff
+			}
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// safe reports whether request of method can be sent again although it may
+// have been processed. Updates and deletes are not, sending them again
+// after they are applied fails with ErrKeyNotFound or ErrVersionMismatch.
+func safe(method string) bool {
+	return method == http.MethodGet
+}
+
+// retryableError reports whether request can be sent again after err,
+// requests which could not connect were never processed.
+func retryableError(method string, err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return safe(method)
+}
+
+// retryableStatus reports whether request can be sent again after response
+// with given status code.
+func retryableStatus(method string, statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return safe(method)
+	}
+	return false
+}
+
+// authorize sets authorization header of req if client has a token.
+func (c *Client) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
@@ -0,0 +1,382 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/client"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+)
+
+func newHandler() http.Handler {
+	storage := kvstorage.New()
+	service := kvstoreservice.New(kvstoreservice.WithStorage(storage))
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(service),
+		kvstorehandler.WithContextTimeout(time.Second),
+		kvstorehandler.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/set/", handler.Set)
+	mux.HandleFunc("/api/v1/get/", handler.Get)
+	mux.HandleFunc("/api/v1/update/", handler.Update)
+	mux.HandleFunc("/api/v1/delete/", handler.Delete)
+	mux.HandleFunc("/api/v1/list/", handler.List)
+	mux.HandleFunc("/api/v1/ns/", handler.Namespaces)
+	mux.HandleFunc("/api/v1/watch/", handler.Watch)
+
+	return mux
+}
+
+func newClient(t *testing.T, handler http.Handler, options ...client.Option) *client.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	options = append([]client.Option{client.WithBackoff(time.Millisecond, 5*time.Millisecond)}, options...)
+
+	c, err := client.New(server.URL, options...)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return c
+}
+
+func TestNewInvalidURL(t *testing.T) {
+	if _, err := client.New("localhost:8000"); err == nil {
+		t.Error("error not occurred")
+	}
+}
+
+func TestItemLifecycle(t *testing.T) {
+	c := newClient(t, newHandler())
+	ctx := context.Background()
+
+	item, err := c.Set(ctx, "key", "value", time.Minute)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item.Key != "key" || item.Value != "value" || item.Version == 0 || item.ExpiresAt.IsZero() {
+		t.Errorf("wrong item: %+v", item)
+	}
+
+	item, err = c.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item.Value != "value" {
+		t.Errorf("wrong value, want: %s, got: %v", "value", item.Value)
+	}
+
+	updated, err := c.Update(ctx, "key", map[string]any{"a": 1.0}, 0, item.Version)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if updated.Version <= item.Version || !updated.ExpiresAt.IsZero() {
+		t.Errorf("wrong updated item: %+v", updated)
+	}
+
+	if err = c.Delete(ctx, "key", updated.Version); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if _, err = c.Get(ctx, "key"); !errors.Is(err, client.ErrKeyNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrKeyNotFound, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newClient(t, newHandler())
+	ctx := context.Background()
+
+	item, err := c.Set(ctx, "key", "value", 0)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	_, err = c.Set(ctx, "key", "value", 0)
+	if !errors.Is(err, client.ErrKeyExists) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrKeyExists, err)
+	}
+
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("error must be *client.Error with status %d, got: %v", http.StatusConflict, err)
+	}
+
+	if _, err = c.Update(ctx, "key", "other", 0, item.Version+1); !errors.Is(err, client.ErrVersionMismatch) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrVersionMismatch, err)
+	}
+
+	if err = c.Delete(ctx, "key", item.Version+1); !errors.Is(err, client.ErrVersionMismatch) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrVersionMismatch, err)
+	}
+
+	if _, err = c.Update(ctx, "missing", "value", 0, 0); !errors.Is(err, client.ErrKeyNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrKeyNotFound, err)
+	}
+
+	if _, err = c.Set(ctx, "", "value", 0); !errors.Is(err, client.ErrInvalidRequest) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrInvalidRequest, err)
+	}
+
+	if _, err = c.Namespace("missing").Get(ctx, "key"); !errors.Is(err, client.ErrNamespaceNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrNamespaceNotFound, err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer read-only":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"status": 403, "code": "forbidden", "detail": "missing scope write"}`))
+		case "":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	})
+	ctx := context.Background()
+
+	if _, err := newClient(t, handler).Get(ctx, "key"); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrUnauthorized, err)
+	}
+
+	_, err := newClient(t, handler, client.WithToken("read-only")).Set(ctx, "key", "value", 0)
+	if !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrForbidden, err)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	var body string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(body))
+	})
+	c := newClient(t, handler)
+	ctx := context.Background()
+
+	tcs := []struct {
+		body string
+		want error
+	}{
+		{`{"code": "namespace_not_found", "detail": "no such namespace"}`, client.ErrNamespaceNotFound},
+		{`{"code": "key_not_found", "detail": "namespace not found"}`, client.ErrKeyNotFound},
+		{`{"detail": "namespace not found"}`, client.ErrKeyNotFound},
+	}
+
+	for _, tc := range tcs {
+		body = tc.body
+		if _, err := c.Get(ctx, "key"); !errors.Is(err, tc.want) {
+			t.Errorf("wrong error for %s, want: %v, got: %v", tc.body, tc.want, err)
+		}
+	}
+
+	// empty list is told apart by code only.
+	body = `{"code": "not_found", "detail": "anything"}`
+	if page, err := c.List(ctx, nil); err != nil || len(page.Items) != 0 {
+		t.Errorf("page must be empty, got: %+v, %v", page, err)
+	}
+
+	body = `{"code": "key_not_found", "detail": "nothing found"}`
+	if _, err := c.List(ctx, nil); !errors.Is(err, client.ErrKeyNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrKeyNotFound, err)
+	}
+}
+
+func TestList(t *testing.T) {
+	c := newClient(t, newHandler())
+	ctx := context.Background()
+
+	page, err := c.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if len(page.Items) != 0 || page.Next != "" {
+		t.Errorf("page must be empty: %+v", page)
+	}
+
+	for _, key := range []string{"app/a", "app/b", "app/c", "other"} {
+		if _, err = c.Set(ctx, key, key, 0); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+	}
+
+	var keys []string
+	opts := &client.ListOptions{Prefix: "app/", Limit: 2}
+	for {
+		page, err = c.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+		for _, item := range page.Items {
+			keys = append(keys, item.Key)
+		}
+		if page.Next == "" {
+			break
+		}
+		opts.Cursor = page.Next
+	}
+
+	if len(keys) != 3 || keys[0] != "app/a" || keys[2] != "app/c" {
+		t.Errorf("wrong keys: %v", keys)
+	}
+}
+
+// flaky fails first n requests with given status code.
+func flaky(handler http.Handler, n int32, statusCode int, attempts *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) <= n {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(statusCode)
+			_, _ = w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func TestRetry(t *testing.T) {
+	var attempts atomic.Int32
+	c := newClient(t, flaky(newHandler(), 2, http.StatusServiceUnavailable, &attempts))
+
+	if _, err := c.Set(context.Background(), "key", "value", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("wrong number of attempts, want: %d, got: %d", 3, got)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	c := newClient(t, flaky(newHandler(), 10, http.StatusGatewayTimeout, &attempts), client.WithRetries(2))
+
+	_, err := c.Get(context.Background(), "key")
+	if !errors.Is(err, client.ErrServer) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrServer, err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("wrong number of attempts, want: %d, got: %d", 3, got)
+	}
+}
+
+func TestNoRetryForNonIdempotentRequest(t *testing.T) {
+	var attempts atomic.Int32
+	c := newClient(t, flaky(newHandler(), 1, http.StatusGatewayTimeout, &attempts))
+
+	if _, err := c.Set(context.Background(), "key", "value", 0); !errors.Is(err, client.ErrServer) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrServer, err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("wrong number of attempts, want: %d, got: %d", 1, got)
+	}
+}
+
+// dropping applies first n requests then drops the connection without a
+// response.
+func dropping(handler http.Handler, n int32, attempts *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) > n {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			panic(err)
+		}
+		_ = conn.Close()
+	})
+}
+
+func TestNoRetryAfterAppliedRequest(t *testing.T) {
+	var attempts atomic.Int32
+	c := newClient(t, dropping(newHandler(), 2, &attempts))
+	ctx := context.Background()
+
+	item, err := c.Set(ctx, "key", "value", 0)
+	if err == nil {
+		t.Fatal("error expected for dropped connection")
+	}
+
+	attempts.Store(10) // no more drops
+	if item, err = c.Get(ctx, "key"); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	tcs := []struct {
+		name string
+		do   func() error
+	}{
+		{"update", func() error {
+			_, uErr := c.Update(ctx, "key", "updated", 0, item.Version)
+			return uErr
+		}},
+		{"delete", func() error {
+			return c.Delete(ctx, "key", 0)
+		}},
+	}
+
+	for _, tc := range tcs {
+		attempts.Store(0)
+
+		// retrying would fail with version mismatch or not found.
+		err = tc.do()
+		if err == nil || errors.Is(err, client.ErrVersionMismatch) || errors.Is(err, client.ErrKeyNotFound) {
+			t.Errorf("%s: wrong error, want: connection error, got: %v", tc.name, err)
+		}
+		if got := attempts.Load(); got != 1 {
+			t.Errorf("%s: wrong number of attempts, want: %d, got: %d", tc.name, 1, got)
+		}
+	}
+
+	if _, err = c.Get(ctx, "key"); !errors.Is(err, client.ErrKeyNotFound) {
+		t.Errorf("delete should be applied, want: %v, got: %v", client.ErrKeyNotFound, err)
+	}
+}
+
+func TestRetryAfterDroppedGet(t *testing.T) {
+	var attempts atomic.Int32
+	c := newClient(t, dropping(newHandler(), 2, &attempts))
+
+	if _, err := c.Get(context.Background(), "key"); !errors.Is(err, client.ErrKeyNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrKeyNotFound, err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("wrong number of attempts, want: %d, got: %d", 3, got)
+	}
+}
+
+func TestRetryWithCancel(t *testing.T) {
+	var attempts atomic.Int32
+	c := newClient(
+		t,
+		flaky(newHandler(), 10, http.StatusServiceUnavailable, &attempts),
+		client.WithBackoff(time.Hour, time.Hour),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Get(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wrong error, want: %v, got: %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+var _ error = (*Error)(nil) // compile time proof
+
+// sentinel errors, use errors.Is to check returned errors.
+var (
+	ErrKeyNotFound       = errors.New("key not found")
+	ErrKeyExists         = errors.New("key exist")
+	ErrVersionMismatch   = errors.New("version mismatch")
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	ErrInvalidRequest    = errors.New("invalid request")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrForbidden         = errors.New("forbidden")
+	ErrServer            = errors.New("server error")
+)
+
+// Error represents non-successful response of kvstore server.
+type Error struct {
+	StatusCode int
+	Code       string // machine-readable code of response, e.g. key_not_found
+	Message    string // detail of response body
+	Err        error  // one of sentinel errors, nil if code and status are not known
+}
+
+func (e *Error) Error() string {
+	return "kvstore: " + e.Message + " (status " + strconv.Itoa(e.StatusCode) + ")"
+}
+
+// Unwrap returns sentinel error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// errorResponse is the problem details body of error responses, servers
+// before problem details send error field only.
+type errorResponse struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Error  string `json:"error"`
+}
+
+// codeNotFound is the code of not found responses which are not about a
+// key or namespace, e.g. empty list.
+const codeNotFound = "not_found"
+
+// codeErrors maps codes of error responses to sentinel errors.
+var codeErrors = map[string]error{
+	"key_not_found":       ErrKeyNotFound,
+	"namespace_not_found": ErrNamespaceNotFound,
+	"key_exists":          ErrKeyExists,
+	"version_mismatch":    ErrVersionMismatch,
+	"invalid_request":     ErrInvalidRequest,
+	"invalid_txn":         ErrInvalidRequest,
+	"invalid_namespace":   ErrInvalidRequest,
+	"unauthorized":        ErrUnauthorized,
+	"forbidden":           ErrForbidden,
+}
+
+// newError maps error response to sentinel error by its code, responses
+// without known code are mapped by status code.
+func newError(statusCode int, body []byte) *Error {
+	var errResp errorResponse
+	_ = json.Unmarshal(body, &errResp)
+
+	e := &Error{
+		StatusCode: statusCode,
+		Code:       errResp.Code,
+		Message:    errResp.Detail,
+	}
+	if e.Message == "" {
+		e.Message = errResp.Error
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(statusCode)
+	}
+
+	if err, ok := codeErrors[e.Code]; ok && statusCode != http.StatusPreconditionFailed {
+		e.Err = err
+		return e
+	}
+
+	switch {
+	case statusCode == http.StatusNotFound:
+		e.Err = ErrKeyNotFound
+	case statusCode == http.StatusPreconditionFailed:
+		e.Err = ErrVersionMismatch
+	case statusCode == http.StatusConflict:
+		e.Err = ErrKeyExists
+	case statusCode == http.StatusUnauthorized:
+		e.Err = ErrUnauthorized
+	case statusCode == http.StatusForbidden:
+		e.Err = ErrForbidden
+	case statusCode == http.StatusBadRequest:
+		e.Err = ErrInvalidRequest
+	case statusCode >= http.StatusInternalServerError:
+		e.Err = ErrServer
+	}
+
+	return e
+}
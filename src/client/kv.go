@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// listNextCursorHeader carries continuation cursor of list.
+const listNextCursorHeader = "X-Next-Cursor"
+
+// Item represents stored k/v item.
+type Item struct {
+	Key       string
+	Value     any
+	ExpiresAt time.Time // zero value means never expires
+	Version   uint64
+}
+
+// itemResponse is the wire format of Item.
+type itemResponse struct {
+	Key       string     `json:"key"`
+	Value     any        `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Version   uint64     `json:"version,omitempty"`
+}
+
+func (r *itemResponse) item() *Item {
+	item := &Item{
+		Key:     r.Key,
+		Value:   r.Value,
+		Version: r.Version,
+	}
+	if r.ExpiresAt != nil {
+		item.ExpiresAt = *r.ExpiresAt
+	}
+	return item
+}
+
+type setRequest struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+	TTL   int64  `json:"ttl,omitempty"`
+}
+
+type updateRequest struct {
+	Key     string `json:"key"`
+	Value   any    `json:"value"`
+	TTL     int64  `json:"ttl,omitempty"`
+	Version uint64 `json:"version,omitempty"`
+}
+
+// ttlSeconds converts ttl to seconds, rounded up.
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return int64((ttl + time.Second - 1) / time.Second)
+}
+
+// Set creates new item, fails with ErrKeyExists if key exists. Zero ttl
+// means never expires.
+func (c *Client) Set(ctx context.Context, key string, value any, ttl time.Duration) (*Item, error) {
+	var resp itemResponse
+	if _, err := c.do(ctx, http.MethodPost, "set", nil, &setRequest{
+		Key:   key,
+		Value: value,
+		TTL:   ttlSeconds(ttl),
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.item(), nil
+}
+
+// Get returns item, fails with ErrKeyNotFound if key does not exist.
+func (c *Client) Get(ctx context.Context, key string) (*Item, error) {
+	var resp itemResponse
+	if _, err := c.do(ctx, http.MethodGet, "get", url.Values{"key": {key}}, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.item(), nil
+}
+
+// Update replaces existing item, fails with ErrKeyNotFound if key does not
+// exist. Non-zero version must match current version of the item,
+// otherwise fails with ErrVersionMismatch.
+func (c *Client) Update(ctx context.Context, key string, value any, ttl time.Duration, version uint64) (*Item, error) {
+	var resp itemResponse
+	if _, err := c.do(ctx, http.MethodPut, "update", nil, &updateRequest{
+		Key:     key,
+		Value:   value,
+		TTL:     ttlSeconds(ttl),
+		Version: version,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.item(), nil
+}
+
+// Delete deletes item, fails with ErrKeyNotFound if key does not exist.
+// Non-zero version must match current version of the item, otherwise fails
+// with ErrVersionMismatch.
+func (c *Client) Delete(ctx context.Context, key string, version uint64) error {
+	query := url.Values{"key": {key}}
+	if version != 0 {
+		query.Set("version", strconv.FormatUint(version, 10))
+	}
+
+	_, err := c.do(ctx, http.MethodDelete, "delete", query, nil, nil)
+	return err
+}
+
+// ListOptions filters and pages List, all fields are optional.
+type ListOptions struct {
+	Prefix string
+	Start  string // inclusive
+	End    string // exclusive
+	Cursor string // Next of the previous page
+	Limit  int    // zero means server default
+}
+
+// ListPage is a page of items in key order. Next is the cursor of the next
+// page, empty if there are no more items.
+type ListPage struct {
+	Items []Item
+	Next  string
+}
+
+// List returns items in key order, nil opts lists all items.
+func (c *Client) List(ctx context.Context, opts *ListOptions) (*ListPage, error) {
+	query := url.Values{}
+	if opts != nil {
+		for name, value := range map[string]string{
+			"prefix": opts.Prefix,
+			"start":  opts.Start,
+			"end":    opts.End,
+			"cursor": opts.Cursor,
+		} {
+			if value != "" {
+				query.Set(name, value)
+			}
+		}
+		if opts.Limit > 0 {
+			query.Set("limit", strconv.Itoa(opts.Limit))
+		}
+	}
+
+	var resp []itemResponse
+	header, err := c.do(ctx, http.MethodGet, "list", query, nil, &resp)
+	if err != nil {
+		var apiErr *Error
+		// empty result is reported as not found.
+		if errors.As(err, &apiErr) && apiErr.Code == codeNotFound {
+			return &ListPage{}, nil
+		}
+		return nil, err
+	}
+
+	page := &ListPage{
+		Items: make([]Item, len(resp)),
+		Next:  header.Get(listNextCursorHeader),
+	}
+	for i := range resp {
+		page.Items[i] = *resp[i].item()
+	}
+	return page, nil
+}
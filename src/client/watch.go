@@ -0,0 +1,182 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Event represents a change of watched keys.
+type Event struct {
+	Type     string // put, delete, expire or drop_namespace
+	Key      string
+	Revision uint64
+	Item     *Item // new item, put only
+	PrevItem *Item // nil if key did not exist
+}
+
+// eventResponse is the wire format of Event.
+type eventResponse struct {
+	Type     string        `json:"type"`
+	Key      string        `json:"key"`
+	Revision uint64        `json:"revision"`
+	Item     *itemResponse `json:"item"`
+	PrevItem *itemResponse `json:"prev_item"`
+}
+
+// WatchOptions selects watched keys, Key watches single key, otherwise keys
+// starting with Prefix (all keys if Prefix is empty) are watched.
+type WatchOptions struct {
+	Key      string
+	Prefix   string
+	Revision uint64 // first revision to receive, zero means only new events
+}
+
+// callbackError carries error returned by Watch callback.
+type callbackError struct {
+	err error
+}
+
+func (e *callbackError) Error() string {
+	return e.err.Error()
+}
+
+// Watch calls fn for every event until ctx is done or fn returns error,
+// which is returned as is. Broken streams are resumed from the next
+// revision after a backoff, Watch gives up after retries consecutive
+// failures. Use a http client without timeout, streams are long-lived.
+func (c *Client) Watch(ctx context.Context, opts *WatchOptions, fn func(Event) error) error {
+	revision := opts.Revision
+	failures := 0
+
+	for {
+		query := url.Values{}
+		if opts.Key != "" {
+			query.Set("key", opts.Key)
+		} else {
+			query.Set("prefix", opts.Prefix)
+		}
+		if revision > 0 {
+			query.Set("revision", strconv.FormatUint(revision, 10))
+		}
+
+		err := c.stream(ctx, query, func(event Event) error {
+			revision = event.Revision + 1
+			failures = 0
+
+			if err := fn(event); err != nil {
+				return &callbackError{err: err}
+			}
+			return nil
+		})
+
+		var cbErr *callbackError
+		if errors.As(err, &cbErr) {
+			return cbErr.err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		var apiErr *Error
+		if errors.As(err, &apiErr) && !retryableStatus(http.MethodGet, apiErr.StatusCode) {
+			return err
+		}
+
+		if failures >= c.retries {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("client.Watch stream err: %w", err)
+		}
+		if err = c.wait(ctx, failures, nil); err != nil {
+			return err
+		}
+		failures++
+	}
+}
+
+// stream reads server-sent events of a single watch request, returns nil
+// if server ends the stream.
+func (c *Client) stream(ctx context.Context, query url.Values, fn func(Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint("watch", query), nil)
+	if err != nil {
+		return fmt.Errorf("client http.NewRequestWithContext err: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("client httpClient.Do err: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+
+		return newError(resp.StatusCode, body)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("client stream reader.ReadString err: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+
+			var event eventResponse
+			if err = json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("client stream json.Unmarshal err: %w", err)
+			}
+			data.Reset()
+
+			if err = fn(event.event()); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// comments (heartbeats), id and event fields are not needed, data
+		// carries all of them.
+	}
+}
+
+func (r *eventResponse) event() Event {
+	event := Event{
+		Type:     r.Type,
+		Key:      r.Key,
+		Revision: r.Revision,
+	}
+	if r.Item != nil {
+		event.Item = r.Item.item()
+	}
+	if r.PrevItem != nil {
+		event.PrevItem = r.PrevItem.item()
+	}
+	return event
+}
@@ -0,0 +1,58 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/client"
+)
+
+func TestWatch(t *testing.T) {
+	c := newClient(t, newHandler())
+	ctx := context.Background()
+
+	for _, key := range []string{"app/a", "other", "app/b"} {
+		if _, err := c.Set(ctx, key, key, 0); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+	}
+	if err := c.Delete(ctx, "app/a", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	errStop := errors.New("stop")
+
+	var events []client.Event
+	err := c.Watch(ctx, &client.WatchOptions{Prefix: "app/", Revision: 1}, func(event client.Event) error {
+		events = append(events, event)
+		if len(events) == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("wrong error, want: %v, got: %v", errStop, err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("wrong number of events, want: %d, got: %d", 3, len(events))
+	}
+	if events[0].Type != "put" || events[0].Key != "app/a" || events[0].Item.Value != "app/a" {
+		t.Errorf("wrong first event: %+v", events[0])
+	}
+	if events[2].Type != "delete" || events[2].Revision != 4 || events[2].PrevItem == nil {
+		t.Errorf("wrong last event: %+v", events[2])
+	}
+}
+
+func TestWatchWithError(t *testing.T) {
+	c := newClient(t, newHandler())
+
+	err := c.Namespace("missing").Watch(context.Background(), &client.WatchOptions{Key: "key"}, func(client.Event) error {
+		return nil
+	})
+	if !errors.Is(err, client.ErrNamespaceNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", client.ErrNamespaceNotFound, err)
+	}
+}
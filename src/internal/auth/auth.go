@@ -0,0 +1,135 @@
+// Package auth authenticates api clients (api keys, JWTs, client
+// certificates) and authorizes their requests by scopes, namespaces and key
+// prefixes.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// sentinel errors.
+var (
+	ErrNoCredentials      = errors.New("credentials required")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Scope is a permission of a principal. Scopes are ordered, admin implies
+// write and write implies read.
+type Scope string
+
+// scopes.
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin" // namespace management
+)
+
+var scopeLevels = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// Valid reports whether scope is known.
+func (s Scope) Valid() bool {
+	_, ok := scopeLevels[s]
+	return ok
+}
+
+// Authenticator authenticates credentials of a request, e.g. api key or
+// bearer token.
+type Authenticator interface {
+	Authenticate(credentials string) (*Principal, error)
+}
+
+// Authenticators tries each authenticator in order, first success wins.
+type Authenticators []Authenticator
+
+var _ Authenticator = (Authenticators)(nil) // compile time proof
+
+// Authenticate returns principal of the first authenticator accepting
+// credentials. When all fail, the most specific error (e.g. expired token)
+// is returned rather than ErrInvalidCredentials.
+func (as Authenticators) Authenticate(credentials string) (*Principal, error) {
+	if credentials == "" {
+		return nil, ErrNoCredentials
+	}
+
+	err := ErrInvalidCredentials
+	for _, a := range as {
+		principal, aErr := a.Authenticate(credentials)
+		if aErr == nil {
+			return principal, nil
+		}
+		if aErr != ErrInvalidCredentials { // nolint:errorlint
+			err = aErr
+		}
+	}
+	return nil, err
+}
+
+// Principal is an authenticated client with its permissions.
+type Principal struct {
+	Subject    string // e.g. name of api key, sub claim of token
+	Scopes     []Scope
+	Namespaces []string // allowed namespaces, empty allows all namespaces
+	Prefixes   []string // allowed key prefixes, empty allows all keys
+}
+
+// HasScope reports whether principal has scope or a scope implying it.
+func (p *Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if scopeLevels[s] >= scopeLevels[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsNamespace reports whether namespace is allowed.
+func (p *Principal) AllowsNamespace(namespace string) bool {
+	if len(p.Namespaces) == 0 {
+		return true
+	}
+	for _, ns := range p.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsKey reports whether key is under one of allowed prefixes.
+func (p *Principal) AllowsKey(key string) bool {
+	if len(p.Prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range p.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsPrefix reports whether all keys starting with prefix are allowed,
+// e.g. for listing or watching by prefix.
+func (p *Principal) AllowsPrefix(prefix string) bool {
+	return p.AllowsKey(prefix)
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying principal.
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, principal)
+}
+
+// FromContext returns principal of ctx, ok is false if request is not
+// authenticated.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(contextKey{}).(*Principal)
+	return principal, ok
+}
@@ -0,0 +1,151 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+)
+
+func TestPrincipalHasScope(t *testing.T) {
+	tcs := []struct {
+		scopes []auth.Scope
+		scope  auth.Scope
+		want   bool
+	}{
+		{[]auth.Scope{auth.ScopeRead}, auth.ScopeRead, true},
+		{[]auth.Scope{auth.ScopeRead}, auth.ScopeWrite, false},
+		{[]auth.Scope{auth.ScopeWrite}, auth.ScopeRead, true},
+		{[]auth.Scope{auth.ScopeWrite}, auth.ScopeAdmin, false},
+		{[]auth.Scope{auth.ScopeAdmin}, auth.ScopeWrite, true},
+		{nil, auth.ScopeRead, false},
+	}
+
+	for _, tc := range tcs {
+		p := &auth.Principal{Scopes: tc.scopes}
+		if got := p.HasScope(tc.scope); got != tc.want {
+			t.Errorf("wrong HasScope(%s) of %v, want: %t, got: %t", tc.scope, tc.scopes, tc.want, got)
+		}
+	}
+}
+
+func TestPrincipalAllowsKey(t *testing.T) {
+	restricted := &auth.Principal{Prefixes: []string{"app/", "cfg/"}}
+	unrestricted := &auth.Principal{}
+
+	tcs := []struct {
+		principal *auth.Principal
+		key       string
+		want      bool
+	}{
+		{restricted, "app/a", true},
+		{restricted, "cfg/", true},
+		{restricted, "other", false},
+		{restricted, "", false},
+		{unrestricted, "other", true},
+		{unrestricted, "", true},
+	}
+
+	for _, tc := range tcs {
+		if got := tc.principal.AllowsKey(tc.key); got != tc.want {
+			t.Errorf("wrong AllowsKey(%q), want: %t, got: %t", tc.key, tc.want, got)
+		}
+	}
+
+	if restricted.AllowsPrefix("") {
+		t.Error("empty prefix must not be allowed for restricted principal")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	tcs := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{`},
+		{"empty name", `[{"key": "k", "scopes": ["read"]}]`},
+		{"no scopes", `[{"name": "a", "key": "k"}]`},
+		{"unknown scope", `[{"name": "a", "key": "k", "scopes": ["root"]}]`},
+		{"no key", `[{"name": "a", "scopes": ["read"]}]`},
+		{"both key and sha256", `[{"name": "a", "key": "k", "sha256": "00", "scopes": ["read"]}]`},
+		{"invalid sha256", `[{"name": "a", "sha256": "zz", "scopes": ["read"]}]`},
+	}
+
+	for _, tc := range tcs {
+		if _, err := auth.ParseKeys([]byte(tc.data)); err == nil {
+			t.Errorf("%s: error expected", tc.name)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	hash := sha256.Sum256([]byte("hashed-secret"))
+
+	ks, err := auth.ParseKeys([]byte(`[
+		{"name": "ci", "key": "secret", "scopes": ["read"], "prefixes": ["app/"]},
+		{"name": "ops", "sha256": "` + hex.EncodeToString(hash[:]) + `", "scopes": ["admin"]}
+	]`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if ks.Len() != 2 {
+		t.Errorf("wrong number of keys, want: %d, got: %d", 2, ks.Len())
+	}
+
+	principal, err := ks.Authenticate("secret")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if principal.Subject != "ci" {
+		t.Errorf("wrong subject, want: %s, got: %s", "ci", principal.Subject)
+	}
+	if len(principal.Prefixes) != 1 || principal.Prefixes[0] != "app/" {
+		t.Errorf("wrong prefixes, want: %v, got: %v", []string{"app/"}, principal.Prefixes)
+	}
+
+	principal, err = ks.Authenticate("hashed-secret")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if principal.Subject != "ops" {
+		t.Errorf("wrong subject, want: %s, got: %s", "ops", principal.Subject)
+	}
+
+	if _, err = ks.Authenticate(""); !errors.Is(err, auth.ErrNoCredentials) {
+		t.Errorf("wrong error, want: %v, got: %v", auth.ErrNoCredentials, err)
+	}
+	if _, err = ks.Authenticate("wrong"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("wrong error, want: %v, got: %v", auth.ErrInvalidCredentials, err)
+	}
+
+	other, err := auth.ParseKeys([]byte(`[{"name": "extra", "key": "extra", "scopes": ["write"]}]`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	merged := ks.Merge(other)
+	if merged.Len() != 3 {
+		t.Errorf("wrong number of merged keys, want: %d, got: %d", 3, merged.Len())
+	}
+	if _, err = merged.Authenticate("extra"); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if _, ok := auth.FromContext(context.Background()); ok {
+		t.Error("principal of empty context must not be found")
+	}
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: "ci"})
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		t.Fatal("principal not found")
+	}
+	if principal.Subject != "ci" {
+		t.Errorf("wrong subject, want: %s, got: %s", "ci", principal.Subject)
+	}
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CertPermission grants permissions to client certificates of Subject,
+// which is either the distinguished name, e.g. "CN=billing,O=Acme", or the
+// common name, e.g. "billing".
+type CertPermission struct {
+	Subject    string   `json:"subject"`
+	Scopes     []Scope  `json:"scopes"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Prefixes   []string `json:"prefixes,omitempty"`
+}
+
+// CertMapping maps subjects of verified client certificates to principals.
+type CertMapping struct {
+	permissions []CertPermission
+}
+
+// ParseCertMapping parses json array of CertPermission.
+func ParseCertMapping(data []byte) (*CertMapping, error) {
+	var permissions []CertPermission
+	if err := json.Unmarshal(data, &permissions); err != nil {
+		return nil, fmt.Errorf("auth.ParseCertMapping json.Unmarshal err: %w", err)
+	}
+
+	for i, p := range permissions {
+		if p.Subject == "" {
+			return nil, fmt.Errorf("auth.ParseCertMapping permission %d err: subject is empty", i)
+		}
+		for _, s := range p.Scopes {
+			if !s.Valid() {
+				return nil, fmt.Errorf("auth.ParseCertMapping permission %d err: unknown scope '%s'", i, s)
+			}
+		}
+	}
+	return &CertMapping{permissions: permissions}, nil
+}
+
+// LoadCertMapping loads mapping from json file, see ParseCertMapping.
+func LoadCertMapping(path string) (*CertMapping, error) {
+	data, err := os.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("auth.LoadCertMapping os.ReadFile err: %w", err)
+	}
+	return ParseCertMapping(data)
+}
+
+// Len returns number of mapped subjects.
+func (m *CertMapping) Len() int {
+	return len(m.permissions)
+}
+
+// AuthenticateCert returns principal of client certificate, which must be
+// verified by the tls handshake. Subject of the principal is distinguished
+// name of certificate, unmapped certificates get a principal without
+// scopes.
+func (m *CertMapping) AuthenticateCert(cert *x509.Certificate) *Principal {
+	principal := &Principal{Subject: cert.Subject.String()}
+
+	for _, p := range m.permissions {
+		if p.Subject == principal.Subject || p.Subject == cert.Subject.CommonName {
+			principal.Scopes = p.Scopes
+			principal.Namespaces = p.Namespaces
+			principal.Prefixes = p.Prefixes
+			break
+		}
+	}
+	return principal
+}
@@ -0,0 +1,57 @@
+package auth_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+)
+
+func TestCertMapping(t *testing.T) {
+	m, err := auth.ParseCertMapping([]byte(`[
+		{"subject": "billing", "scopes": ["write"], "namespaces": ["billing"]},
+		{"subject": "CN=ops,O=Acme", "scopes": ["admin"]}
+	]`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if m.Len() != 2 {
+		t.Errorf("wrong number of subjects, want: %d, got: %d", 2, m.Len())
+	}
+
+	tcs := []struct {
+		subject     pkix.Name
+		wantSubject string
+		wantScope   auth.Scope // scope principal must have, empty if none
+	}{
+		{pkix.Name{CommonName: "billing", Organization: []string{"Acme"}}, "CN=billing,O=Acme", auth.ScopeWrite},
+		{pkix.Name{CommonName: "ops", Organization: []string{"Acme"}}, "CN=ops,O=Acme", auth.ScopeAdmin},
+		{pkix.Name{CommonName: "ops", Organization: []string{"Other"}}, "CN=ops,O=Other", ""},
+		{pkix.Name{CommonName: "unknown"}, "CN=unknown", ""},
+	}
+
+	for _, tc := range tcs {
+		principal := m.AuthenticateCert(&x509.Certificate{Subject: tc.subject})
+		if principal.Subject != tc.wantSubject {
+			t.Errorf("wrong subject, want: %s, got: %s", tc.wantSubject, principal.Subject)
+		}
+		if tc.wantScope == "" && principal.HasScope(auth.ScopeRead) {
+			t.Errorf("%s must not have scopes, got: %v", tc.wantSubject, principal.Scopes)
+		}
+		if tc.wantScope != "" && !principal.HasScope(tc.wantScope) {
+			t.Errorf("%s must have scope %s, got: %v", tc.wantSubject, tc.wantScope, principal.Scopes)
+		}
+	}
+
+	principal := m.AuthenticateCert(&x509.Certificate{Subject: pkix.Name{CommonName: "billing"}})
+	if principal.AllowsNamespace("default") {
+		t.Errorf("wrong namespaces: %v", principal.Namespaces)
+	}
+
+	for _, data := range []string{`{`, `[{"scopes": ["read"]}]`, `[{"subject": "a", "scopes": ["root"]}]`} {
+		if _, err = auth.ParseCertMapping([]byte(data)); err == nil {
+			t.Errorf("error expected for %s", data)
+		}
+	}
+}
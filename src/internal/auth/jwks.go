@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// minRSABits is the minimum accepted size of rsa keys.
+const minRSABits = 2048
+
+// verificationKey is a key verifying signatures of tokens, either hmac
+// secret or rsa public key.
+type verificationKey struct {
+	kid       string // empty matches any kid
+	alg       string // empty matches any alg of key type
+	hmac      []byte
+	rsaPublic *rsa.PublicKey
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517) of token verification keys. Rsa
+// ("RSA") and hmac ("oct") keys are supported, other keys and keys which
+// are not for signing are skipped.
+type JWKS struct {
+	keys []verificationKey
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS parses json web key set.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("auth.ParseJWKS json.Unmarshal err: %w", err)
+	}
+
+	jwks := &JWKS{}
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key := verificationKey{kid: k.Kid, alg: k.Alg}
+
+		switch k.Kty {
+		case "RSA":
+			pub, err := rsaPublicKey(k.N, k.E)
+			if err != nil {
+				return nil, fmt.Errorf("auth.ParseJWKS key %d err: %w", i, err)
+			}
+			key.rsaPublic = pub
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("auth.ParseJWKS key %d err: invalid k", i)
+			}
+			key.hmac = secret
+		default:
+			continue
+		}
+
+		jwks.keys = append(jwks.keys, key)
+	}
+	return jwks, nil
+}
+
+// LoadJWKS loads json web key set from file, see ParseJWKS.
+func LoadJWKS(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("auth.LoadJWKS os.ReadFile err: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// Len returns number of keys.
+func (s *JWKS) Len() int {
+	return len(s.keys)
+}
+
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil || len(nBytes) == 0 {
+		return nil, errors.New("invalid n")
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil || len(eBytes) == 0 || len(eBytes) > 4 {
+		return nil, errors.New("invalid e")
+	}
+
+	var exponent int
+	for _, b := range eBytes {
+		exponent = exponent<<8 | int(b)
+	}
+
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: exponent}
+	if pub.N.BitLen() < minRSABits {
+		return nil, fmt.Errorf("rsa key is shorter than %d bits", minRSABits)
+	}
+	return pub, nil
+}
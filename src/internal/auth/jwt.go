@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var _ Authenticator = (*JWTVerifier)(nil) // compile time proof
+
+// supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+// minHMACSecretLen is the minimum length of hmac secrets, size of the hash
+// output as required by RFC 7518.
+const minHMACSecretLen = sha256.Size
+
+// JWTVerifier authenticates HS256 and RS256 signed JSON Web Tokens. Claims
+// map to principal:
+//
+//	sub        subject, required
+//	scope      space separated scopes, e.g. "read write", unknown ones are ignored
+//	namespaces allowed namespaces, all if missing
+//	prefixes   allowed key prefixes, all if missing
+//
+// Tokens must have exp, nbf is checked when present, iss and aud are checked
+// when issuer and audience are configured.
+type JWTVerifier struct {
+	keys     []verificationKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// JWTOption represents jwt verifier option type.
+type JWTOption func(*JWTVerifier)
+
+// WithHMACSecret adds hmac secret verifying HS256 tokens, may be given
+// multiple times during secret rotation.
+func WithHMACSecret(secret string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.keys = append(v.keys, verificationKey{hmac: []byte(secret)})
+	}
+}
+
+// WithJWKS adds keys of json web key set.
+func WithJWKS(jwks *JWKS) JWTOption {
+	return func(v *JWTVerifier) {
+		v.keys = append(v.keys, jwks.keys...)
+	}
+}
+
+// WithIssuer sets required iss claim option.
+func WithIssuer(issuer string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience sets audience option, aud claim must contain it.
+func WithAudience(audience string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway sets allowed clock skew of exp and nbf checks.
+func WithLeeway(d time.Duration) JWTOption {
+	return func(v *JWTVerifier) {
+		v.leeway = d
+	}
+}
+
+// WithClock sets clock option, defaults to time.Now.
+func WithClock(now func() time.Time) JWTOption {
+	return func(v *JWTVerifier) {
+		v.now = now
+	}
+}
+
+// NewJWTVerifier instantiates new jwt verifier, at least one key is required.
+func NewJWTVerifier(options ...JWTOption) (*JWTVerifier, error) {
+	v := &JWTVerifier{now: time.Now}
+
+	for _, o := range options {
+		o(v)
+	}
+
+	if len(v.keys) == 0 {
+		return nil, errors.New("auth.NewJWTVerifier no verification keys")
+	}
+	for _, k := range v.keys {
+		if k.rsaPublic == nil && len(k.hmac) < minHMACSecretLen {
+			return nil, fmt.Errorf("auth.NewJWTVerifier hmac secret is shorter than %d bytes", minHMACSecretLen)
+		}
+	}
+
+	return v, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// audience is aud claim, either a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	var ss []string
+	if err := json.Unmarshal(data, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+type jwtClaims struct {
+	Subject    string   `json:"sub"`
+	Issuer     string   `json:"iss"`
+	Audience   audience `json:"aud"`
+	ExpiresAt  *float64 `json:"exp"`
+	NotBefore  *float64 `json:"nbf"`
+	Scope      string   `json:"scope"`
+	Namespaces []string `json:"namespaces"`
+	Prefixes   []string `json:"prefixes"`
+}
+
+// Authenticate verifies token and returns principal of its claims. Tokens
+// which are not jwt get ErrInvalidCredentials, rejected tokens get an error
+// wrapping it with the reason.
+func (v *JWTVerifier) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCredentials
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed token header", ErrInvalidCredentials)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token signature", ErrInvalidCredentials)
+	}
+
+	if err = v.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed token claims", ErrInvalidCredentials)
+	}
+
+	if err = v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{
+		Subject:    claims.Subject,
+		Namespaces: claims.Namespaces,
+		Prefixes:   claims.Prefixes,
+	}
+	for _, s := range strings.Fields(claims.Scope) {
+		if scope := Scope(s); scope.Valid() {
+			principal.Scopes = append(principal.Scopes, scope)
+		}
+	}
+
+	return principal, nil
+}
+
+func (v *JWTVerifier) verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	if header.Alg != AlgHS256 && header.Alg != AlgRS256 {
+		return fmt.Errorf("%w: unsupported alg '%s'", ErrInvalidCredentials, header.Alg)
+	}
+
+	digest := sha256.Sum256([]byte(signingInput))
+
+	for _, k := range v.keys {
+		if (k.kid != "" && k.kid != header.Kid) || (k.alg != "" && k.alg != header.Alg) {
+			continue
+		}
+
+		switch {
+		case header.Alg == AlgHS256 && k.hmac != nil:
+			mac := hmac.New(sha256.New, k.hmac)
+			mac.Write([]byte(signingInput))
+			if hmac.Equal(signature, mac.Sum(nil)) {
+				return nil
+			}
+		case header.Alg == AlgRS256 && k.rsaPublic != nil:
+			if rsa.VerifyPKCS1v15(k.rsaPublic, crypto.SHA256, digest[:], signature) == nil {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: invalid token signature", ErrInvalidCredentials)
+}
+
+func (v *JWTVerifier) validateClaims(claims jwtClaims) error {
+	now := v.now()
+
+	switch {
+	case claims.ExpiresAt == nil:
+		return fmt.Errorf("%w: token has no exp", ErrInvalidCredentials)
+	case now.After(unixTime(*claims.ExpiresAt).Add(v.leeway)):
+		return fmt.Errorf("%w: token is expired", ErrInvalidCredentials)
+	case claims.NotBefore != nil && now.Add(v.leeway).Before(unixTime(*claims.NotBefore)):
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidCredentials)
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return fmt.Errorf("%w: wrong token issuer", ErrInvalidCredentials)
+	case v.audience != "" && !claims.Audience.contains(v.audience):
+		return fmt.Errorf("%w: wrong token audience", ErrInvalidCredentials)
+	case claims.Subject == "":
+		return fmt.Errorf("%w: token has no sub", ErrInvalidCredentials)
+	}
+
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, aud := range a {
+		if aud == s {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
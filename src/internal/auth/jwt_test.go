@@ -0,0 +1,262 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, header, claims map[string]any) string {
+	t.Helper()
+
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims map[string]any) string {
+	t.Helper()
+
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":        "billing",
+		"iss":        "https://issuer.example",
+		"aud":        "kvstore",
+		"exp":        testNow.Add(time.Hour).Unix(),
+		"scope":      "openid read write",
+		"namespaces": []string{"billing"},
+		"prefixes":   []string{"invoices/"},
+	}
+}
+
+func newHS256Verifier(t *testing.T) *auth.JWTVerifier {
+	t.Helper()
+
+	v, err := auth.NewJWTVerifier(
+		auth.WithHMACSecret(testSecret),
+		auth.WithIssuer("https://issuer.example"),
+		auth.WithAudience("kvstore"),
+		auth.WithLeeway(30*time.Second),
+		auth.WithClock(func() time.Time { return testNow }),
+	)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return v
+}
+
+func TestJWTVerifierHS256(t *testing.T) {
+	v := newHS256Verifier(t)
+
+	token := signHS256(t, testSecret, map[string]any{"alg": "HS256", "typ": "JWT"}, validClaims())
+	principal, err := v.Authenticate(token)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if principal.Subject != "billing" {
+		t.Errorf("wrong subject, want: %s, got: %s", "billing", principal.Subject)
+	}
+	if !principal.HasScope(auth.ScopeWrite) || principal.HasScope(auth.ScopeAdmin) {
+		t.Errorf("wrong scopes: %v", principal.Scopes)
+	}
+	if !principal.AllowsNamespace("billing") || principal.AllowsNamespace("default") {
+		t.Errorf("wrong namespaces: %v", principal.Namespaces)
+	}
+	if !principal.AllowsKey("invoices/1") || principal.AllowsKey("users/1") {
+		t.Errorf("wrong prefixes: %v", principal.Prefixes)
+	}
+}
+
+func TestJWTVerifierRejects(t *testing.T) {
+	v := newHS256Verifier(t)
+	header := map[string]any{"alg": "HS256"}
+
+	with := func(key string, value any) map[string]any {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tcs := []struct {
+		name   string
+		token  string
+		reason string
+	}{
+		{"expired", signHS256(t, testSecret, header, with("exp", testNow.Add(-time.Minute).Unix())), "token is expired"},
+		{"no exp", signHS256(t, testSecret, header, with("exp", nil)), "token has no exp"},
+		{"not valid yet", signHS256(t, testSecret, header, with("nbf", testNow.Add(time.Minute).Unix())), "token is not valid yet"},
+		{"wrong issuer", signHS256(t, testSecret, header, with("iss", "other")), "wrong token issuer"},
+		{"wrong audience", signHS256(t, testSecret, header, with("aud", []string{"a", "b"})), "wrong token audience"},
+		{"no sub", signHS256(t, testSecret, header, with("sub", nil)), "token has no sub"},
+		{"wrong secret", signHS256(t, strings.Repeat("x", 32), header, validClaims()), "invalid token signature"},
+		{"alg none", encodeSegment(t, map[string]any{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + ".", "unsupported alg 'none'"},
+		{"malformed header", "e30K!.e30.e30", "malformed token header"},
+	}
+
+	for _, tc := range tcs {
+		_, err := v.Authenticate(tc.token)
+		if !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("%s: wrong error, want: %v, got: %v", tc.name, auth.ErrInvalidCredentials, err)
+			continue
+		}
+		if !strings.HasSuffix(err.Error(), tc.reason) {
+			t.Errorf("%s: wrong reason, want: %s, got: %s", tc.name, tc.reason, err)
+		}
+	}
+
+	// within leeway
+	if _, err := v.Authenticate(signHS256(t, testSecret, header, with("exp", testNow.Add(-10*time.Second).Unix()))); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+	// audience array
+	if _, err := v.Authenticate(signHS256(t, testSecret, header, with("aud", []string{"a", "kvstore"}))); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+
+	if _, err := v.Authenticate("api-key"); err != auth.ErrInvalidCredentials { // nolint:errorlint
+		t.Errorf("wrong error, want: %v, got: %v", auth.ErrInvalidCredentials, err)
+	}
+}
+
+func TestJWTVerifierRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	jwks, err := auth.ParseJWKS([]byte(`{"keys": [
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": "", "y": ""},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "", "e": ""},
+		{"kty": "RSA", "kid": "k1", "alg": "RS256", "use": "sig",
+		 "n": "` + base64.RawURLEncoding.EncodeToString(key.N.Bytes()) + `",
+		 "e": "` + base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()) + `"}
+	]}`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if jwks.Len() != 1 {
+		t.Errorf("wrong number of keys, want: %d, got: %d", 1, jwks.Len())
+	}
+
+	v, err := auth.NewJWTVerifier(auth.WithJWKS(jwks), auth.WithClock(func() time.Time { return testNow }))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	principal, err := v.Authenticate(signRS256(t, key, map[string]any{"alg": "RS256", "kid": "k1"}, validClaims()))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if principal.Subject != "billing" {
+		t.Errorf("wrong subject, want: %s, got: %s", "billing", principal.Subject)
+	}
+
+	tcs := []struct {
+		name  string
+		token string
+	}{
+		{"other key", signRS256(t, other, map[string]any{"alg": "RS256", "kid": "k1"}, validClaims())},
+		{"unknown kid", signRS256(t, key, map[string]any{"alg": "RS256", "kid": "k2"}, validClaims())},
+		// public key must not be usable as hmac secret
+		{"alg confusion", signHS256(t, string(key.N.Bytes()), map[string]any{"alg": "HS256", "kid": "k1"}, validClaims())},
+	}
+	for _, tc := range tcs {
+		if _, err = v.Authenticate(tc.token); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("%s: wrong error, want: %v, got: %v", tc.name, auth.ErrInvalidCredentials, err)
+		}
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	if _, err := auth.NewJWTVerifier(); err == nil {
+		t.Error("error expected for verifier without keys")
+	}
+	if _, err := auth.NewJWTVerifier(auth.WithHMACSecret("short")); err == nil {
+		t.Error("error expected for short secret")
+	}
+	if _, err := auth.ParseJWKS([]byte(`{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`)); err == nil {
+		t.Error("error expected for short rsa key")
+	}
+}
+
+func TestAuthenticators(t *testing.T) {
+	keys, err := auth.ParseKeys([]byte(`[{"name": "ci", "key": "secret", "scopes": ["read"]}]`))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	authenticators := auth.Authenticators{keys, newHS256Verifier(t)}
+
+	principal, err := authenticators.Authenticate("secret")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if principal.Subject != "ci" {
+		t.Errorf("wrong subject, want: %s, got: %s", "ci", principal.Subject)
+	}
+
+	principal, err = authenticators.Authenticate(signHS256(t, testSecret, map[string]any{"alg": "HS256"}, validClaims()))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if principal.Subject != "billing" {
+		t.Errorf("wrong subject, want: %s, got: %s", "billing", principal.Subject)
+	}
+
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-time.Hour).Unix()
+	_, err = authenticators.Authenticate(signHS256(t, testSecret, map[string]any{"alg": "HS256"}, claims))
+	if err == nil || !strings.HasSuffix(err.Error(), "token is expired") {
+		t.Errorf("wrong error, want: %s, got: %v", "token is expired", err)
+	}
+
+	if _, err = authenticators.Authenticate(""); !errors.Is(err, auth.ErrNoCredentials) {
+		t.Errorf("wrong error, want: %v, got: %v", auth.ErrNoCredentials, err)
+	}
+	if _, err = authenticators.Authenticate("wrong"); err != auth.ErrInvalidCredentials { // nolint:errorlint
+		t.Errorf("wrong error, want: %v, got: %v", auth.ErrInvalidCredentials, err)
+	}
+}
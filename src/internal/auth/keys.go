@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// APIKey is a configured api key. Either Key or SHA256 (hex encoded sha-256
+// of the key) is set, hashes keep plain keys out of config files.
+type APIKey struct {
+	Name       string   `json:"name"`
+	Key        string   `json:"key,omitempty"`
+	SHA256     string   `json:"sha256,omitempty"`
+	Scopes     []Scope  `json:"scopes"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Prefixes   []string `json:"prefixes,omitempty"`
+}
+
+var _ Authenticator = (*KeyStore)(nil) // compile time proof
+
+type storedKey struct {
+	hash      [sha256.Size]byte
+	principal Principal
+}
+
+// KeyStore authenticates api keys.
+type KeyStore struct {
+	keys []storedKey
+}
+
+// ParseKeys parses json array of APIKey.
+func ParseKeys(data []byte) (*KeyStore, error) {
+	var apiKeys []APIKey
+	if err := json.Unmarshal(data, &apiKeys); err != nil {
+		return nil, fmt.Errorf("auth.ParseKeys json.Unmarshal err: %w", err)
+	}
+
+	ks := &KeyStore{keys: make([]storedKey, 0, len(apiKeys))}
+	for i, k := range apiKeys {
+		sk, err := newStoredKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("auth.ParseKeys key %d err: %w", i, err)
+		}
+		ks.keys = append(ks.keys, sk)
+	}
+	return ks, nil
+}
+
+// LoadKeys loads keys from json file, see ParseKeys.
+func LoadKeys(path string) (*KeyStore, error) {
+	data, err := os.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("auth.LoadKeys os.ReadFile err: %w", err)
+	}
+	return ParseKeys(data)
+}
+
+// Merge returns store holding keys of both stores.
+func (ks *KeyStore) Merge(other *KeyStore) *KeyStore {
+	merged := &KeyStore{keys: make([]storedKey, 0, len(ks.keys)+len(other.keys))}
+	merged.keys = append(merged.keys, ks.keys...)
+	merged.keys = append(merged.keys, other.keys...)
+	return merged
+}
+
+// Len returns number of keys.
+func (ks *KeyStore) Len() int {
+	return len(ks.keys)
+}
+
+// Authenticate returns principal of api key. All keys are compared in
+// constant time so timing does not tell which key is close.
+func (ks *KeyStore) Authenticate(key string) (*Principal, error) {
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	var found *Principal
+	for i := range ks.keys {
+		if subtle.ConstantTimeCompare(hash[:], ks.keys[i].hash[:]) == 1 && found == nil {
+			principal := ks.keys[i].principal
+			found = &principal
+		}
+	}
+
+	if found == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return found, nil
+}
+
+func newStoredKey(k APIKey) (storedKey, error) {
+	if k.Name == "" {
+		return storedKey{}, errors.New("name is empty")
+	}
+	if len(k.Scopes) == 0 {
+		return storedKey{}, fmt.Errorf("'%s' has no scopes", k.Name)
+	}
+	for _, s := range k.Scopes {
+		if !s.Valid() {
+			return storedKey{}, fmt.Errorf("'%s' has unknown scope '%s'", k.Name, s)
+		}
+	}
+
+	sk := storedKey{
+		principal: Principal{
+			Subject:    k.Name,
+			Scopes:     k.Scopes,
+			Namespaces: k.Namespaces,
+			Prefixes:   k.Prefixes,
+		},
+	}
+
+	switch {
+	case k.Key != "" && k.SHA256 != "":
+		return storedKey{}, fmt.Errorf("'%s' has both key and sha256", k.Name)
+	case k.Key != "":
+		sk.hash = sha256.Sum256([]byte(k.Key))
+	case k.SHA256 != "":
+		b, err := hex.DecodeString(k.SHA256)
+		if err != nil || len(b) != sha256.Size {
+			return storedKey{}, fmt.Errorf("'%s' has invalid sha256", k.Name)
+		}
+		copy(sk.hash[:], b)
+	default:
+		return storedKey{}, fmt.Errorf("'%s' has no key", k.Name)
+	}
+
+	return sk, nil
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+)
+
+var _ slog.Handler = (*logHandler)(nil) // compile time proof
+
+// logHandler adds subject of the principal of the context to records.
+type logHandler struct {
+	next slog.Handler
+}
+
+// NewLogHandler returns handler adding subject attribute to records which
+// are logged with a context carrying principal, e.g. for auditing who
+// caused an error.
+func NewLogHandler(next slog.Handler) slog.Handler {
+	if h, ok := next.(*logHandler); ok {
+		return h
+	}
+	return &logHandler{next: next}
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if principal, ok := FromContext(ctx); ok {
+		record = record.Clone()
+		record.AddAttrs(slog.String("subject", principal.Subject))
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{next: h.next.WithGroup(name)}
+}
@@ -1,68 +1,145 @@
+// Package kverror defines errors of kvstore operations. Sentinels are
+// comparable codes, every failure is a fresh *Error carrying its code, so
+// errors never share mutable state between requests.
 package kverror
 
+import (
+	"errors"
+	"strconv"
+)
+
 var (
-	_ error   = (*Error)(nil) // compile time proof
-	_ KVError = (*Error)(nil) // compile time proof
+	_ error = Code("")      // compile time proof
+	_ error = (*Error)(nil) // compile time proof
 )
 
+// Code identifies kind of error. Codes are sentinel errors, use errors.Is to
+// check them, e.g. errors.Is(err, kverror.ErrKeyNotFound).
+type Code string
+
 // sentinel errors.
-var (
-	ErrKeyExists   = New("key exist", true)
-	ErrKeyNotFound = New("key not found", false)
-	ErrUnknown     = New("unknown error", true)
+const (
+	ErrKeyExists         Code = "key_exists"
+	ErrKeyNotFound       Code = "key_not_found"
+	ErrVersionMismatch   Code = "version_mismatch"
+	ErrInvalidTxn        Code = "invalid_txn"
+	ErrNamespaceExists   Code = "namespace_exists"
+	ErrNamespaceNotFound Code = "namespace_not_found"
+	ErrInvalidNamespace  Code = "invalid_namespace"
+	ErrRevisionCompacted Code = "revision_compacted"
+	ErrUnknown           Code = "unknown"
 )
 
-// KVError defines custom error behaviours.
-type KVError interface {
-	Wrap(err error) KVError
-	Unwrap() error
-	AddData(any) KVError
-	DestoryData() KVError
-	Error() string
+var codeMessages = map[Code]string{
+	ErrKeyExists:         "key exist",
+	ErrKeyNotFound:       "key not found",
+	ErrVersionMismatch:   "version mismatch",
+	ErrInvalidTxn:        "invalid transaction",
+	ErrNamespaceExists:   "namespace exist",
+	ErrNamespaceNotFound: "namespace not found",
+	ErrInvalidNamespace:  "invalid namespace",
+	ErrRevisionCompacted: "revision compacted",
+	ErrUnknown:           "unknown error",
 }
 
-// Error is a custom type definition uses struct, custom error.
-type Error struct {
-	Err      error
-	Message  string
-	Data     any `json:"-"`
-	Loggable bool
+// Message returns human readable message of code.
+func (c Code) Message() string {
+	if m, ok := codeMessages[c]; ok {
+		return m
+	}
+	return string(c)
 }
 
-// AddData adds extra data to error.
-func (e *Error) AddData(data any) KVError {
-	e.Data = data
-	return e
+// Loggable reports whether errors of code should be logged by transports.
+func (c Code) Loggable() bool {
+	return c == ErrKeyExists || c == ErrUnknown
 }
 
-// Unwrap unwraps error.
-func (e *Error) Unwrap() error {
-	return e.Err
+func (c Code) Error() string {
+	return c.Message()
 }
 
-// DestoryData removes added data from error.
-func (e *Error) DestoryData() KVError {
-	e.Data = nil
-	return e
+// Error is a failed operation.
+type Error struct {
+	Code   Code
+	Op     string // e.g. get, set, txn
+	Key    string // key or namespace name, empty if not applicable
+	Detail string // client safe detail, e.g. 'key' does not exist
+	Err    error  // cause, nil if there is none
+}
+
+// New instantiates new Error of code.
+func New(code Code, op, key, detail string) error {
+	return &Error{
+		Code:   code,
+		Op:     op,
+		Key:    key,
+		Detail: detail,
+	}
 }
 
-// Wrap wraps given error.
-func (e *Error) Wrap(err error) KVError {
-	e.Err = err
-	return e
+// Wrap instantiates new Error of code caused by err.
+func Wrap(code Code, op, key string, err error) error {
+	return &Error{
+		Code: code,
+		Op:   op,
+		Key:  key,
+		Err:  err,
+	}
+}
+
+// Message returns client message: message of code and detail.
+func (e *Error) Message() string {
+	if e.Detail == "" {
+		return e.Code.Message()
+	}
+	return e.Code.Message() + ", " + e.Detail
 }
 
 func (e *Error) Error() string {
+	s := e.Message()
+	switch {
+	case e.Op != "" && e.Key != "":
+		s = e.Op + " " + strconv.Quote(e.Key) + ": " + s
+	case e.Op != "":
+		s = e.Op + ": " + s
+	}
 	if e.Err != nil {
-		return e.Err.Error() + ", " + e.Message
+		s += ": " + e.Err.Error()
 	}
-	return e.Message
+	return s
 }
 
-// New instantiates new Error instance.
-func New(m string, l bool) KVError {
-	return &Error{
-		Message:  m,
-		Loggable: l,
+// Is reports whether target is the code of error.
+func (e *Error) Is(target error) bool {
+	code, ok := target.(Code)
+	return ok && code == e.Code
+}
+
+// Unwrap returns cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// From returns kverror of err, bare codes are returned as Error without
+// detail. ok is false if err is not a kverror.
+func From(err error) (*Error, bool) {
+	var kvErr *Error
+	if errors.As(err, &kvErr) {
+		return kvErr, true
+	}
+
+	var code Code
+	if errors.As(err, &code) {
+		return &Error{Code: code}, true
+	}
+	return nil, false
+}
+
+// CodeOf returns code of err, empty if err is not a kverror.
+func CodeOf(err error) Code {
+	if kvErr, ok := From(err); ok {
+		return kvErr.Code
 	}
+	return ""
 }
@@ -2,101 +2,119 @@ package kverror_test
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 )
 
 func TestError(t *testing.T) {
-	err := kverror.New("some error", true)
+	err := kverror.New(kverror.ErrKeyNotFound, "get", "a", "'a' does not exist")
 	var kvErr *kverror.Error
 
 	if !errors.As(err, &kvErr) {
 		t.Errorf("error does not match the target type, want: %T, got: %v", kvErr, err)
 	}
 
-	shouldEqual := "some error"
-	if kvErr.Message != shouldEqual {
-		t.Errorf("error message does not match, want: %s, got: %s", shouldEqual, kvErr.Message)
+	shouldEqual := "key not found, 'a' does not exist"
+	if kvErr.Message() != shouldEqual {
+		t.Errorf("error message does not match, want: %s, got: %s", shouldEqual, kvErr.Message())
 	}
 
-	shouldLoggable := true
-	if kvErr.Loggable != shouldLoggable {
-		t.Errorf("error should be loggable, want: %t, got: %t", shouldLoggable, kvErr.Loggable)
-	}
-}
-
-func TestWrap(t *testing.T) {
-	err := kverror.New("some error", false)
-	wrappedErr := err.Wrap(errors.New("inner")) // nolint
-
-	var kvErr *kverror.Error
-
-	if !errors.As(wrappedErr, &kvErr) {
-		t.Errorf("error does not match the target type, want: %T, got: %v", kvErr, err)
+	shouldEqual = `get "a": key not found, 'a' does not exist`
+	if err.Error() != shouldEqual {
+		t.Errorf("error does not match, want: %s, got: %s", shouldEqual, err.Error())
 	}
 
-	if kvErr.Err == nil {
-		t.Errorf("wrapped error can not be nil, want: %v, got: nil", kvErr.Err)
+	if kvErr.Code.Loggable() {
+		t.Errorf("error should not be loggable, want: %t, got: %t", false, kvErr.Code.Loggable())
 	}
 
-	shouldEqual := "inner, some error"
-	if err.Error() != shouldEqual {
-		t.Errorf("wrapped error does not match, want: %s, got: %s", shouldEqual, err.Error())
+	if !kverror.ErrUnknown.Loggable() {
+		t.Errorf("error should be loggable, want: %t, got: %t", true, kverror.ErrUnknown.Loggable())
 	}
 }
 
-func TestUnwrap(t *testing.T) {
-	err := kverror.New("some error", false)
-	wrappedErr := err.Wrap(errors.New("inner")) // nolint
+func TestIs(t *testing.T) {
+	err := fmt.Errorf("service err: %w", kverror.New(kverror.ErrKeyExists, "set", "a", ""))
 
-	var kvErr *kverror.Error
+	if !errors.Is(err, kverror.ErrKeyExists) {
+		t.Errorf("error does not match, want: %v, got: %v", kverror.ErrKeyExists, err)
+	}
 
-	if !errors.As(wrappedErr, &kvErr) {
-		t.Errorf("error does not match the target type, want: %T, got: %v", kvErr, err)
+	if errors.Is(err, kverror.ErrKeyNotFound) {
+		t.Errorf("error should not match, want: %v, got: %v", kverror.ErrKeyExists, err)
 	}
 
-	shouldEqual := "inner"
-	unwrappedErr := kvErr.Unwrap()
-	if unwrappedErr.Error() != shouldEqual {
-		t.Errorf("unwrapped error does not match, want: %s, got: %s", shouldEqual, unwrappedErr.Error())
+	if code := kverror.CodeOf(err); code != kverror.ErrKeyExists {
+		t.Errorf("wrong code, want: %s, got: %s", kverror.ErrKeyExists, code)
+	}
+
+	if code := kverror.CodeOf(errors.New("other")); code != "" { // nolint
+		t.Errorf("wrong code, want: empty, got: %s", code)
 	}
 }
 
-func TestAddDataDestroyData(t *testing.T) {
-	err := kverror.New("some error", false).AddData("hello")
+func TestWrapUnwrap(t *testing.T) {
+	inner := errors.New("inner") // nolint
+	err := kverror.Wrap(kverror.ErrUnknown, "set", "", inner)
 
-	var kvErr *kverror.Error
+	if !errors.Is(err, inner) {
+		t.Errorf("error does not wrap cause, want: %v, got: %v", inner, err)
+	}
 
-	if !errors.As(err, &kvErr) {
-		t.Errorf("error does not match the target type, want: %T, got: %v", kvErr, err)
+	if !errors.Is(err, kverror.ErrUnknown) {
+		t.Errorf("error does not match, want: %v, got: %v", kverror.ErrUnknown, err)
 	}
 
-	if kvErr.Data == nil {
-		t.Errorf("data should not be nil, want: %v, got: nil", kvErr.Data)
+	shouldEqual := "set: unknown error: inner"
+	if err.Error() != shouldEqual {
+		t.Errorf("wrapped error does not match, want: %s, got: %s", shouldEqual, err.Error())
 	}
+}
 
-	shouldEqual := "hello"
-	data, ok := kvErr.Data.(string)
+func TestFrom(t *testing.T) {
+	kvErr, ok := kverror.From(fmt.Errorf("%w", kverror.ErrKeyNotFound))
 	if !ok {
-		t.Error("data should be assertable to string")
+		t.Fatalf("bare code should be a kverror, want: %t, got: %t", true, ok)
 	}
 
-	if data != shouldEqual {
-		t.Errorf("data does not match, want: %s, got: %s", shouldEqual, data)
+	if kvErr.Message() != "key not found" {
+		t.Errorf("wrong message, want: %s, got: %s", "key not found", kvErr.Message())
 	}
 
-	shouldEqual = "some error"
-	if err.Error() != shouldEqual {
-		t.Errorf("error does not match, want: %s, got: %s", shouldEqual, err.Error())
+	if _, ok = kverror.From(errors.New("other")); ok { // nolint
+		t.Errorf("error should not be a kverror, want: %t, got: %t", false, ok)
 	}
+}
 
-	err = err.DestoryData()
-	if !errors.As(err, &kvErr) {
-		t.Errorf("error does not match the target type, want: %T, got: %v", kvErr, err)
+func TestErrorsAreNotShared(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make([]error, 100)
+
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i)
+			errs[i] = kverror.New(kverror.ErrKeyNotFound, "get", key, "'"+key+"' does not exist")
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		var kvErr *kverror.Error
+		if !errors.As(err, &kvErr) {
+			t.Fatalf("error does not match the target type, want: %T, got: %v", kvErr, err)
+		}
+		if kvErr.Key != strconv.Itoa(i) {
+			t.Errorf("wrong key, want: %d, got: %s", i, kvErr.Key)
+		}
 	}
 
-	if kvErr.Data != nil {
-		t.Errorf("data should be nil, want: nil, got: %v", kvErr.Data)
+	if kverror.ErrKeyNotFound.Error() != "key not found" {
+		t.Errorf("sentinel should not change, want: %s, got: %s", "key not found", kverror.ErrKeyNotFound.Error())
 	}
 }
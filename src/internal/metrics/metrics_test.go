@@ -0,0 +1,70 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/metrics"
+)
+
+func TestWrite(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	requests := registry.NewCounter("requests_total", "Number of requests.", "route", "code")
+	requests.Inc("/b/", "200")
+	requests.Inc("/a/", "404")
+	requests.Add(2, "/a/", "404")
+
+	duration := registry.NewHistogram("duration_seconds", "Request duration.", []float64{0.1, 1})
+	duration.Observe(0.05)
+	duration.Observe(0.1)
+	duration.Observe(5)
+
+	registry.NewGaugeFunc("keys", "Number of keys.", func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{`a"b\c` + "\n"}, Value: 3}}
+	}, "namespace")
+
+	var buf bytes.Buffer
+	if err := registry.Write(&buf); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	want := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{route="/a/",code="404"} 3
+requests_total{route="/b/",code="200"} 1
+# HELP duration_seconds Request duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1"} 2
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 5.15
+duration_seconds_count 3
+# HELP keys Number of keys.
+# TYPE keys gauge
+keys{namespace="a\"b\\c\n"} 3
+`
+	if buf.String() != want {
+		t.Errorf("wrong output, want: %s, got: %s", want, buf.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.RegisterGoCollector()
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := w.Header().Get("Content-Type"); got != metrics.ContentType {
+		t.Errorf("wrong content type, want: %s, got: %s", metrics.ContentType, got)
+	}
+	for _, name := range []string{"go_goroutines ", "go_memstats_alloc_bytes ", "go_gc_cycles_total ", `go_info{version="go`} {
+		if !strings.Contains(w.Body.String(), "\n"+name) {
+			t.Errorf("%s is missing: %s", name, w.Body.String())
+		}
+	}
+}
@@ -13,12 +13,21 @@ type KVStoreService interface {
 	Set(context.Context, *SetRequest) (*ItemResponse, error)
 	Get(context.Context, string) (*ItemResponse, error)
 	Update(context.Context, *UpdateRequest) (*ItemResponse, error)
-	Delete(context.Context, string) error
-	List(context.Context) (*ListResponse, error)
+	Delete(context.Context, *DeleteRequest) error
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	Txn(context.Context, *TxnRequest) (*TxnResponse, error)
+	Batch(context.Context, *BatchRequest) (*BatchResponse, error)
+	Namespace(string) KVStoreService
+	CreateNamespace(context.Context, string) error
+	DropNamespace(context.Context, string) error
+	ListNamespaces(context.Context) ([]string, error)
+	Watch(context.Context, *WatchRequest) (<-chan WatchEvent, error)
+	Stats(context.Context) (*StatsResponse, error)
 }
 
 type kvStoreService struct {
-	storage kvstorage.Storer
+	storage   kvstorage.Storer
+	namespace string // namespace storage is bound to
 }
 
 // ServiceOption represents service option type.
@@ -33,7 +42,7 @@ func WithStorage(strg kvstorage.Storer) ServiceOption {
 
 // New instantiates new service instance.
 func New(options ...ServiceOption) KVStoreService {
-	kvs := &kvStoreService{}
+	kvs := &kvStoreService{namespace: kvstorage.DefaultNamespace}
 
 	for _, o := range options {
 		o(kvs)
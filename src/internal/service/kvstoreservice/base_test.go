@@ -1,6 +1,11 @@
 package kvstoreservice_test
 
 import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
@@ -11,11 +16,22 @@ type mockStorage struct {
 	getErr    error
 	updateErr error
 	setErr    error
+	txnErr    error
+	listErr   error
+	nsErr     error
+
+	txnResponse *kvstorage.TxnResponse
 
-	memoryDB kvstorage.MemoryDB
+	memoryDB    kvstorage.MemoryDB
+	namespace   string
+	namespaces  []string
+	stats       []kvstorage.NamespaceStats
+	scanOptions kvstorage.ScanOptions
+	watcher     *mockWatcher
+	watchErr    error
 }
 
-func (m *mockStorage) Delete(k string) error {
+func (m *mockStorage) Delete(k string, _ uint64) error {
 	if m.deleteErr == nil {
 		delete(m.memoryDB, k)
 		return nil
@@ -23,7 +39,7 @@ func (m *mockStorage) Delete(k string) error {
 	return m.deleteErr
 }
 
-func (m *mockStorage) Get(k string) (any, error) {
+func (m *mockStorage) Get(k string) (*kvstorage.Item, error) {
 	if m.getErr == nil {
 		v, ok := m.memoryDB[k]
 		if !ok {
@@ -34,31 +50,142 @@ func (m *mockStorage) Get(k string) (any, error) {
 	return nil, m.getErr
 }
 
-func (m *mockStorage) List() kvstorage.MemoryDB {
-	return m.memoryDB
+func (m *mockStorage) List() (kvstorage.MemoryDB, error) {
+	return m.memoryDB, m.listErr
 }
 
-func (m *mockStorage) Set(k string, v any) (any, error) {
+func (m *mockStorage) Set(k string, v any, ttl time.Duration) (*kvstorage.Item, error) {
 	if m.setErr == nil {
 		if _, ok := m.memoryDB[k]; ok {
 			return nil, m.setErr
 		}
 
-		m.memoryDB[k] = v
-		return v, nil
+		m.memoryDB[k] = &kvstorage.Item{Value: v}
+		if ttl > 0 {
+			m.memoryDB[k].ExpiresAt = time.Now().Add(ttl)
+		}
+		return m.memoryDB[k], nil
 
 	}
 	return nil, m.setErr
 }
 
-func (m *mockStorage) Update(k string, v any) (any, error) {
+func (m *mockStorage) Update(k string, v any, _ time.Duration, _ uint64) (*kvstorage.Item, error) {
 	if m.updateErr == nil {
 		if _, ok := m.memoryDB[k]; !ok {
 			return nil, m.updateErr
 		}
 
-		m.memoryDB[k] = v
-		return v, nil
+		m.memoryDB[k] = &kvstorage.Item{Value: v}
+		return m.memoryDB[k], nil
 	}
 	return nil, m.updateErr
 }
+
+func (m *mockStorage) Snapshot() error {
+	return nil
+}
+
+func (m *mockStorage) DeleteExpired() int {
+	return 0
+}
+
+func (m *mockStorage) Txn(_ []kvstorage.TxnCompare, _, _ []kvstorage.TxnOp) (*kvstorage.TxnResponse, error) {
+	return m.txnResponse, m.txnErr
+}
+
+func (m *mockStorage) GetMany(keys []string) []kvstorage.BatchResult {
+	results := make([]kvstorage.BatchResult, len(keys))
+	for i, key := range keys {
+		results[i].Key = key
+
+		item, ok := m.memoryDB[key]
+		if !ok {
+			results[i].Err = kverror.ErrKeyNotFound
+			continue
+		}
+		results[i].Item = item
+	}
+	return results
+}
+
+func (m *mockStorage) SetMany(items []kvstorage.BatchSetItem) []kvstorage.BatchResult {
+	results := make([]kvstorage.BatchResult, len(items))
+	for i, item := range items {
+		results[i].Key = item.Key
+		results[i].Item, results[i].Err = m.Set(item.Key, item.Value, item.TTL)
+	}
+	return results
+}
+
+func (m *mockStorage) DeleteMany(keys []string) []kvstorage.BatchResult {
+	results := make([]kvstorage.BatchResult, len(keys))
+	for i, key := range keys {
+		results[i].Key = key
+		results[i].Err = m.Delete(key, 0)
+	}
+	return results
+}
+
+func (m *mockStorage) Namespace(name string) kvstorage.Storer {
+	m.namespace = name
+	return m
+}
+
+func (m *mockStorage) CreateNamespace(_ string) error {
+	return m.nsErr
+}
+
+func (m *mockStorage) DropNamespace(_ string) error {
+	return m.nsErr
+}
+
+func (m *mockStorage) Namespaces() []string {
+	return m.namespaces
+}
+
+func (m *mockStorage) Scan(opts kvstorage.ScanOptions) (*kvstorage.ScanResult, error) {
+	m.scanOptions = opts
+
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+
+	keys := make([]string, 0, len(m.memoryDB))
+	for key := range m.memoryDB {
+		if strings.HasPrefix(key, opts.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := &kvstorage.ScanResult{}
+	for _, key := range keys {
+		result.Items = append(result.Items, kvstorage.ScanItem{Key: key, Item: m.memoryDB[key]})
+	}
+	return result, nil
+}
+
+type mockWatcher struct {
+	events chan kvstorage.Event
+	closed bool
+}
+
+func (w *mockWatcher) Events() <-chan kvstorage.Event {
+	return w.events
+}
+
+func (w *mockWatcher) Close() {
+	w.closed = true
+}
+
+func (m *mockStorage) Watch(_ kvstorage.WatchOptions) (kvstorage.Watcher, error) {
+	if m.watchErr != nil {
+		return nil, m.watchErr
+	}
+	return m.watcher, nil
+}
+
+func (m *mockStorage) Stats() []kvstorage.NamespaceStats {
+	return m.stats
+}
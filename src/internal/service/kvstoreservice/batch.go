@@ -0,0 +1,63 @@
+package kvstoreservice
+
+import (
+	"context"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+)
+
+func (s *kvStoreService) Batch(ctx context.Context, br *BatchRequest) (*BatchResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		keys := make([]string, len(br.Items))
+		for i, item := range br.Items {
+			keys[i] = item.Key
+		}
+
+		var results []kvstorage.BatchResult
+
+		switch br.Op {
+		case BatchOpGet:
+			results = s.storage.GetMany(keys)
+		case BatchOpSet:
+			items := make([]kvstorage.BatchSetItem, len(br.Items))
+			for i, item := range br.Items {
+				items[i] = kvstorage.BatchSetItem{
+					Key:   item.Key,
+					Value: item.Value,
+					TTL:   item.TTL,
+				}
+			}
+			results = s.storage.SetMany(items)
+		case BatchOpDelete:
+			results = s.storage.DeleteMany(keys)
+		default:
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Batch unknown op '%s'", br.Op)
+		}
+
+		response := &BatchResponse{
+			Results: make([]BatchItemResponse, len(results)),
+		}
+		for i, r := range results {
+			response.Results[i] = BatchItemResponse{Key: r.Key}
+
+			if r.Err != nil {
+				response.Results[i].Err = tracing.Errorf(ctx, "kvstoreservice.Batch storage err: %w", r.Err)
+				continue
+			}
+
+			if r.Item != nil {
+				response.Results[i].Item = &ItemResponse{
+					Key:       r.Key,
+					Value:     r.Item.Value,
+					ExpiresAt: r.Item.ExpiresAt,
+					Version:   r.Item.Version,
+				}
+			}
+		}
+		return response, nil
+	}
+}
@@ -0,0 +1,101 @@
+package kvstoreservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestBatchWithCancel(t *testing.T) {
+	mockStorage := &mockStorage{}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := kvsStoreService.Batch(ctx, nil); !errors.Is(err, ctx.Err()) {
+		t.Error("error not occurred")
+	}
+}
+
+func TestBatchUnknownOp(t *testing.T) {
+	mockStorage := &mockStorage{}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	if _, err := kvsStoreService.Batch(context.Background(), &kvstoreservice.BatchRequest{Op: "rename"}); err == nil {
+		t.Error("error not occurred")
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{
+			"a": {Value: "1", Version: 1},
+		},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	res, err := kvsStoreService.Batch(context.Background(), &kvstoreservice.BatchRequest{
+		Op:    kvstoreservice.BatchOpGet,
+		Items: []kvstoreservice.BatchItem{{Key: "a"}, {Key: "b"}},
+	})
+	if err != nil {
+		t.Fatalf("batch err: %v", err)
+	}
+
+	if res.Results[0].Item == nil || res.Results[0].Item.Value != "1" || res.Results[0].Item.Version != 1 {
+		t.Errorf("want: 1, got: %+v", res.Results[0].Item)
+	}
+
+	if !errors.Is(res.Results[1].Err, kverror.ErrKeyNotFound) {
+		t.Error("error must be kverror.ErrKeyNotFound")
+	}
+}
+
+func TestBatchSet(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	res, err := kvsStoreService.Batch(context.Background(), &kvstoreservice.BatchRequest{
+		Op:    kvstoreservice.BatchOpSet,
+		Items: []kvstoreservice.BatchItem{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+	})
+	if err != nil {
+		t.Fatalf("batch err: %v", err)
+	}
+
+	if len(res.Results) != 2 || len(mockStorage.memoryDB) != 2 {
+		t.Errorf("want: 2 keys, got: %d", len(mockStorage.memoryDB))
+	}
+}
+
+func TestBatchDelete(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{
+			"a": {Value: "1"},
+		},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	res, err := kvsStoreService.Batch(context.Background(), &kvstoreservice.BatchRequest{
+		Op:    kvstoreservice.BatchOpDelete,
+		Items: []kvstoreservice.BatchItem{{Key: "a"}},
+	})
+	if err != nil {
+		t.Fatalf("batch err: %v", err)
+	}
+
+	if res.Results[0].Err != nil || res.Results[0].Item != nil {
+		t.Errorf("unexpected result: %+v", res.Results[0])
+	}
+
+	if len(mockStorage.memoryDB) != 0 {
+		t.Error("key must be deleted")
+	}
+}
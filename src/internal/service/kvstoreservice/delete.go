@@ -2,16 +2,17 @@ package kvstoreservice
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
 )
 
-func (s *kvStoreService) Delete(ctx context.Context, key string) error {
+func (s *kvStoreService) Delete(ctx context.Context, dr *DeleteRequest) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
-		if err := s.storage.Delete(key); err != nil {
-			return fmt.Errorf("kvstoreservice.Set storage.Delete err: %w", err)
+		if err := s.storage.Delete(dr.Key, dr.Version); err != nil {
+			return tracing.Errorf(ctx, "kvstoreservice.Set storage.Delete err: %w", err)
 		}
 		return nil
 	}
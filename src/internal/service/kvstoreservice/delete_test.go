@@ -7,6 +7,7 @@ import (
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
 func TestDeleteWithCancel(t *testing.T) {
@@ -18,7 +19,7 @@ func TestDeleteWithCancel(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	if err := kvsStoreService.Delete(ctx, "key"); !errors.Is(err, ctx.Err()) {
+	if err := kvsStoreService.Delete(ctx, &kvstoreservice.DeleteRequest{Key: "key"}); !errors.Is(err, ctx.Err()) {
 		t.Error("error not occurred")
 	}
 }
@@ -31,22 +32,20 @@ func TestDeleteWithStorageError(t *testing.T) {
 		kvstoreservice.WithStorage(mockStorage),
 	)
 
-	err := kvsStoreService.Delete(context.Background(), "key")
+	err := kvsStoreService.Delete(context.Background(), &kvstoreservice.DeleteRequest{Key: "key"})
 	if err == nil {
 		t.Error("error not occurred")
 	}
 
-	var kvErr *kverror.Error
-
-	if !errors.As(err, &kvErr) {
+	if !errors.Is(err, kverror.ErrKeyNotFound) {
 		t.Error("error must be kverror.ErrKeyNotFound")
 	}
 }
 
 func TestDelete(t *testing.T) {
 	mockStorage := &mockStorage{
-		memoryDB: map[string]any{
-			"key": "value",
+		memoryDB: kvstorage.MemoryDB{
+			"key": {Value: "value"},
 		},
 	}
 
@@ -54,7 +53,7 @@ func TestDelete(t *testing.T) {
 		kvstoreservice.WithStorage(mockStorage),
 	)
 
-	if err := kvsStoreService.Delete(context.Background(), "key"); err != nil {
+	if err := kvsStoreService.Delete(context.Background(), &kvstoreservice.DeleteRequest{Key: "key"}); err != nil {
 		t.Error("error occurred")
 	}
 
@@ -2,7 +2,8 @@ package kvstoreservice
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
 )
 
 func (s *kvStoreService) Get(ctx context.Context, key string) (*ItemResponse, error) {
@@ -10,13 +11,15 @@ func (s *kvStoreService) Get(ctx context.Context, key string) (*ItemResponse, er
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		value, err := s.storage.Get(key)
+		item, err := s.storage.Get(key)
 		if err != nil {
-			return nil, fmt.Errorf("kvstoreservice.Set storage.Get err: %w", err)
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Set storage.Get err: %w", err)
 		}
 		return &ItemResponse{
-			Key:   key,
-			Value: value,
+			Key:       key,
+			Value:     item.Value,
+			ExpiresAt: item.ExpiresAt,
+			Version:   item.Version,
 		}, nil
 	}
 }
@@ -2,24 +2,43 @@ package kvstoreservice
 
 import (
 	"context"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
 )
 
-func (s *kvStoreService) List(ctx context.Context) (*ListResponse, error) {
+func (s *kvStoreService) List(ctx context.Context, lr *ListRequest) (*ListResponse, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		items := s.storage.List()
-		response := make(ListResponse, len(items))
+		if lr == nil {
+			lr = &ListRequest{}
+		}
 
-		var i int
-		for k, v := range items {
-			response[i] = ItemResponse{
-				Key:   k,
-				Value: v,
+		result, err := s.storage.Scan(kvstorage.ScanOptions{
+			Prefix: lr.Prefix,
+			Start:  lr.Start,
+			End:    lr.End,
+			After:  lr.After,
+			Limit:  lr.Limit,
+		})
+		if err != nil {
+			return nil, tracing.Errorf(ctx, "kvstoreservice.List storage.Scan err: %w", err)
+		}
+
+		response := &ListResponse{
+			Items: make([]ItemResponse, len(result.Items)),
+			Next:  result.Next,
+		}
+		for i, si := range result.Items {
+			response.Items[i] = ItemResponse{
+				Key:       si.Key,
+				Value:     si.Item.Value,
+				ExpiresAt: si.Item.ExpiresAt,
+				Version:   si.Item.Version,
 			}
-			i++
 		}
-		return &response, nil
+		return response, nil
 	}
 }
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
 func TestListWithCancel(t *testing.T) {
@@ -15,20 +17,62 @@ func TestListWithCancel(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	if _, err := kvsStoreService.List(ctx); !errors.Is(err, ctx.Err()) {
+	if _, err := kvsStoreService.List(ctx, nil); !errors.Is(err, ctx.Err()) {
 		t.Error("error not occurred")
 	}
 }
 
 func TestList(t *testing.T) {
 	mockStorage := &mockStorage{
-		memoryDB: map[string]any{
-			"key": "value",
+		memoryDB: kvstorage.MemoryDB{
+			"key": {Value: "value"},
 		},
 	}
 	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
 
-	if _, err := kvsStoreService.List(context.Background()); err != nil {
+	if _, err := kvsStoreService.List(context.Background(), nil); err != nil {
 		t.Error("error occurred")
 	}
 }
+
+func TestListWithStorageError(t *testing.T) {
+	mockStorage := &mockStorage{
+		listErr: kverror.ErrNamespaceNotFound,
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	if _, err := kvsStoreService.List(context.Background(), nil); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Error("error must be kverror.ErrNamespaceNotFound")
+	}
+}
+
+func TestListWithRequest(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{
+			"user:2": {Value: "2"},
+			"user:1": {Value: "1"},
+			"order":  {Value: "order"},
+		},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	res, err := kvsStoreService.List(context.Background(), &kvstoreservice.ListRequest{
+		Prefix: "user:",
+		Start:  "user:0",
+		End:    "user:9",
+		After:  "user:0",
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	want := kvstorage.ScanOptions{Prefix: "user:", Start: "user:0", End: "user:9", After: "user:0", Limit: 10}
+	if mockStorage.scanOptions != want {
+		t.Errorf("want: %+v, got: %+v", want, mockStorage.scanOptions)
+	}
+
+	if len(res.Items) != 2 || res.Items[0].Key != "user:1" {
+		t.Errorf("wrong items: %+v", res.Items)
+	}
+}
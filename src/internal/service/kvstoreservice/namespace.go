@@ -0,0 +1,48 @@
+package kvstoreservice
+
+import (
+	"context"
+
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+)
+
+// Namespace returns service bound to given namespace.
+func (s *kvStoreService) Namespace(name string) KVStoreService {
+	return &kvStoreService{
+		storage:   s.storage.Namespace(name),
+		namespace: name,
+	}
+}
+
+func (s *kvStoreService) CreateNamespace(ctx context.Context, name string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		if err := s.storage.CreateNamespace(name); err != nil {
+			return tracing.Errorf(ctx, "kvstoreservice.CreateNamespace storage.CreateNamespace err: %w", err)
+		}
+		return nil
+	}
+}
+
+func (s *kvStoreService) DropNamespace(ctx context.Context, name string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		if err := s.storage.DropNamespace(name); err != nil {
+			return tracing.Errorf(ctx, "kvstoreservice.DropNamespace storage.DropNamespace err: %w", err)
+		}
+		return nil
+	}
+}
+
+func (s *kvStoreService) ListNamespaces(ctx context.Context) ([]string, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		return s.storage.Namespaces(), nil
+	}
+}
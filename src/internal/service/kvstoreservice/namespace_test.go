@@ -0,0 +1,91 @@
+package kvstoreservice_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestNamespace(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{
+			"key": {Value: "value"},
+		},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	if _, err := kvsStoreService.Namespace("team-a").Get(context.Background(), "key"); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+
+	if mockStorage.namespace != "team-a" {
+		t.Errorf("want: team-a, got: %s", mockStorage.namespace)
+	}
+}
+
+func TestCreateNamespaceWithCancel(t *testing.T) {
+	mockStorage := &mockStorage{}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := kvsStoreService.CreateNamespace(ctx, "team-a"); !errors.Is(err, ctx.Err()) {
+		t.Error("error not occurred")
+	}
+}
+
+func TestCreateNamespaceWithStorageError(t *testing.T) {
+	mockStorage := &mockStorage{
+		nsErr: kverror.ErrNamespaceExists,
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	if err := kvsStoreService.CreateNamespace(context.Background(), "team-a"); !errors.Is(err, kverror.ErrNamespaceExists) {
+		t.Error("error must be kverror.ErrNamespaceExists")
+	}
+}
+
+func TestDropNamespaceWithCancel(t *testing.T) {
+	mockStorage := &mockStorage{}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := kvsStoreService.DropNamespace(ctx, "team-a"); !errors.Is(err, ctx.Err()) {
+		t.Error("error not occurred")
+	}
+}
+
+func TestDropNamespaceWithStorageError(t *testing.T) {
+	mockStorage := &mockStorage{
+		nsErr: kverror.ErrNamespaceNotFound,
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	if err := kvsStoreService.DropNamespace(context.Background(), "team-a"); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Error("error must be kverror.ErrNamespaceNotFound")
+	}
+}
+
+func TestListNamespaces(t *testing.T) {
+	mockStorage := &mockStorage{
+		namespaces: []string{"default", "team-a"},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	names, err := kvsStoreService.ListNamespaces(context.Background())
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if !reflect.DeepEqual(names, mockStorage.namespaces) {
+		t.Errorf("want: %v, got: %v", mockStorage.namespaces, names)
+	}
+}
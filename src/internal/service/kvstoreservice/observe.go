@@ -0,0 +1,109 @@
+package kvstoreservice
+
+import (
+	"context"
+)
+
+var _ KVStoreService = (*observedService)(nil) // compile time proof
+
+// Observer is called after every operation with operation name and its
+// error, nil on success. Failed items of batch are reported one by one
+// with op "batch_<op>".
+type Observer func(op string, err error)
+
+type observedService struct {
+	next    KVStoreService
+	observe Observer
+}
+
+// Observe returns service calling observe after every operation of next.
+func Observe(next KVStoreService, observe Observer) KVStoreService {
+	return &observedService{
+		next:    next,
+		observe: observe,
+	}
+}
+
+func (s *observedService) Set(ctx context.Context, sr *SetRequest) (*ItemResponse, error) {
+	response, err := s.next.Set(ctx, sr)
+	s.observe("set", err)
+	return response, err
+}
+
+func (s *observedService) Get(ctx context.Context, key string) (*ItemResponse, error) {
+	response, err := s.next.Get(ctx, key)
+	s.observe("get", err)
+	return response, err
+}
+
+func (s *observedService) Update(ctx context.Context, ur *UpdateRequest) (*ItemResponse, error) {
+	response, err := s.next.Update(ctx, ur)
+	s.observe("update", err)
+	return response, err
+}
+
+func (s *observedService) Delete(ctx context.Context, dr *DeleteRequest) error {
+	err := s.next.Delete(ctx, dr)
+	s.observe("delete", err)
+	return err
+}
+
+func (s *observedService) List(ctx context.Context, lr *ListRequest) (*ListResponse, error) {
+	response, err := s.next.List(ctx, lr)
+	s.observe("list", err)
+	return response, err
+}
+
+func (s *observedService) Txn(ctx context.Context, tr *TxnRequest) (*TxnResponse, error) {
+	response, err := s.next.Txn(ctx, tr)
+	s.observe("txn", err)
+	return response, err
+}
+
+func (s *observedService) Batch(ctx context.Context, br *BatchRequest) (*BatchResponse, error) {
+	response, err := s.next.Batch(ctx, br)
+	s.observe("batch", err)
+
+	if err == nil {
+		for _, r := range response.Results {
+			if r.Err != nil {
+				s.observe("batch_"+br.Op, r.Err)
+			}
+		}
+	}
+	return response, err
+}
+
+func (s *observedService) Namespace(name string) KVStoreService {
+	return Observe(s.next.Namespace(name), s.observe)
+}
+
+func (s *observedService) CreateNamespace(ctx context.Context, name string) error {
+	err := s.next.CreateNamespace(ctx, name)
+	s.observe("create_namespace", err)
+	return err
+}
+
+func (s *observedService) DropNamespace(ctx context.Context, name string) error {
+	err := s.next.DropNamespace(ctx, name)
+	s.observe("drop_namespace", err)
+	return err
+}
+
+func (s *observedService) ListNamespaces(ctx context.Context) ([]string, error) {
+	names, err := s.next.ListNamespaces(ctx)
+	s.observe("list_namespaces", err)
+	return names, err
+}
+
+func (s *observedService) Watch(ctx context.Context, wr *WatchRequest) (<-chan WatchEvent, error) {
+	events, err := s.next.Watch(ctx, wr)
+	s.observe("watch", err)
+	return events, err
+}
+
+func (s *observedService) Stats(ctx context.Context) (*StatsResponse, error) {
+	response, err := s.next.Stats(ctx)
+	s.observe("stats", err)
+	return response, err
+}
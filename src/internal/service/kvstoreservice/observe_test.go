@@ -0,0 +1,51 @@
+package kvstoreservice_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestObserve(t *testing.T) {
+	type observation struct {
+		op  string
+		err error
+	}
+	var observations []observation
+
+	storage := kvstorage.New()
+	kvsStoreService := kvstoreservice.Observe(
+		kvstoreservice.New(kvstoreservice.WithStorage(storage)),
+		func(op string, err error) {
+			observations = append(observations, observation{op, err})
+		},
+	)
+
+	ctx := context.Background()
+	_, _ = kvsStoreService.Set(ctx, &kvstoreservice.SetRequest{Key: "key", Value: "value"})
+	_, _ = kvsStoreService.Get(ctx, "missing")
+	_, _ = kvsStoreService.Batch(ctx, &kvstoreservice.BatchRequest{
+		Op:    kvstoreservice.BatchOpGet,
+		Items: []kvstoreservice.BatchItem{{Key: "key"}, {Key: "missing"}},
+	})
+	_, _ = kvsStoreService.Namespace("team-a").Get(ctx, "key")
+
+	ops := make([]string, len(observations))
+	for i, o := range observations {
+		ops[i] = o.op
+	}
+	if want := []string{"set", "get", "batch", "batch_get", "get"}; !reflect.DeepEqual(ops, want) {
+		t.Fatalf("wrong observations, want: %v, got: %v", want, ops)
+	}
+
+	for i, want := range []error{nil, kverror.ErrKeyNotFound, nil, kverror.ErrKeyNotFound, kverror.ErrNamespaceNotFound} {
+		if got := observations[i].err; (want == nil && got != nil) || !errors.Is(got, want) {
+			t.Errorf("wrong error of %s, want: %v, got: %v", ops[i], want, got)
+		}
+	}
+}
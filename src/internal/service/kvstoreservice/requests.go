@@ -1,13 +1,90 @@
 package kvstoreservice
 
+import (
+	"time"
+)
+
+// ListRequest is an input payload for List behaviour, all fields are
+// optional.
+type ListRequest struct {
+	Prefix string
+	Start  string // inclusive
+	End    string // exclusive
+	After  string // exclusive, Next of the previous page
+	Limit  int    // zero means unlimited
+}
+
 // SetRequest is an input payload for Set behaviour.
 type SetRequest struct {
 	Key   string
 	Value any
+	TTL   time.Duration // zero means never expires
 }
 
 // UpdateRequest is an input payload for Update behaviour.
 type UpdateRequest struct {
+	Key     string
+	Value   any
+	TTL     time.Duration // zero means never expires
+	Version uint64        // expected version, zero means unconditional
+}
+
+// DeleteRequest is an input payload for Delete behaviour.
+type DeleteRequest struct {
+	Key     string
+	Version uint64 // expected version, zero means unconditional
+}
+
+// TxnCompare is a condition of TxnRequest.
+type TxnCompare struct {
+	Key     string
+	Target  string // version or value
+	Result  string // =, !=, > or <
+	Version uint64
+	Value   any
+}
+
+// TxnOp is an operation of TxnRequest.
+type TxnOp struct {
+	Type  string // get, put or delete
+	Key   string
+	Value any
+	TTL   time.Duration // zero means never expires
+}
+
+// TxnRequest is an input payload for Txn behaviour. Success operations are
+// applied if all compares hold, failure operations otherwise.
+type TxnRequest struct {
+	Compares []TxnCompare
+	Success  []TxnOp
+	Failure  []TxnOp
+}
+
+// batch operations.
+const (
+	BatchOpGet    = "get"
+	BatchOpSet    = "set"
+	BatchOpDelete = "delete"
+)
+
+// BatchItem is an element of BatchRequest, Value and TTL are used by set
+// only.
+type BatchItem struct {
 	Key   string
 	Value any
+	TTL   time.Duration // zero means never expires
+}
+
+// BatchRequest is an input payload for Batch behaviour.
+type BatchRequest struct {
+	Op    string // get, set or delete
+	Items []BatchItem
+}
+
+// WatchRequest is an input payload for Watch behaviour. Key watches single
+// key, Prefix watches keys starting with it.
+type WatchRequest struct {
+	Key      string
+	Prefix   string
+	Revision uint64 // first revision to receive, zero means only new events
 }
@@ -1,10 +1,65 @@
 package kvstoreservice
 
+import (
+	"time"
+)
+
 // ItemResponse represents common k/v response element.
 type ItemResponse struct {
-	Key   string
-	Value any
+	Key       string
+	Value     any
+	ExpiresAt time.Time // zero value means never expires
+	Version   uint64
+}
+
+// ListResponse is a page of ItemResponse in key order. Next is the last key
+// of the page if there are more items, empty otherwise.
+type ListResponse struct {
+	Items []ItemResponse
+	Next  string
+}
+
+// TxnOpResponse represents result of transaction operation.
+type TxnOpResponse struct {
+	Type    string
+	Key     string
+	Item    *ItemResponse // nil for delete and for get of missing key
+	Deleted bool
+}
+
+// TxnResponse represents result of transaction.
+type TxnResponse struct {
+	Succeeded bool
+	Revision  uint64
+	Results   []TxnOpResponse
 }
 
-// ListResponse is a collection on ItemResponse.
-type ListResponse []ItemResponse
+// BatchItemResponse represents per-key result of batch, Err is set when
+// operation fails for the key.
+type BatchItemResponse struct {
+	Key  string
+	Item *ItemResponse // nil for delete and for failures
+	Err  error
+}
+
+// BatchResponse represents result of batch, results are in request order.
+type BatchResponse struct {
+	Results []BatchItemResponse
+}
+
+// StatsResponse represents size of the namespace, expired keys which are
+// not removed yet are included.
+type StatsResponse struct {
+	Keys    int
+	Expires int   // keys with ttl
+	Bytes   int64 // approximate size of keys and values
+}
+
+// WatchEvent represents a change of watched keys.
+type WatchEvent struct {
+	Type     string // put, delete, expire or drop_namespace
+	Key      string
+	Revision uint64
+	Item     *ItemResponse // new item, put only
+	PrevItem *ItemResponse // nil if key did not exist
+}
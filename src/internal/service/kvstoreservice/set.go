@@ -2,7 +2,9 @@ package kvstoreservice
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
 )
 
 func (s *kvStoreService) Set(ctx context.Context, sr *SetRequest) (*ItemResponse, error) {
@@ -10,13 +12,19 @@ func (s *kvStoreService) Set(ctx context.Context, sr *SetRequest) (*ItemResponse
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		if _, err := s.storage.Set(sr.Key, sr.Value); err != nil {
-			return nil, fmt.Errorf("kvstoreservice.Set storage.Set err: %w", err)
+		item, err := s.storage.Set(sr.Key, sr.Value, sr.TTL)
+		if err != nil {
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Set storage.Set err: %w", err)
+		}
+		if item == nil {
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Set err: %w", kverror.New(kverror.ErrUnknown, "set", sr.Key, "no item written"))
 		}
 
 		return &ItemResponse{
-			Key:   sr.Key,
-			Value: sr.Value,
+			Key:       sr.Key,
+			Value:     item.Value,
+			ExpiresAt: item.ExpiresAt,
+			Version:   item.Version,
 		}, nil
 	}
 }
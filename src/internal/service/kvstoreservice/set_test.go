@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
 func TestSetWithCancel(t *testing.T) {
@@ -42,16 +44,14 @@ func TestSetWithStorageError(t *testing.T) {
 		t.Errorf("response must be nil!")
 	}
 
-	var kvErr *kverror.Error
-
-	if !errors.As(err, &kvErr) {
+	if !errors.Is(err, kverror.ErrKeyExists) {
 		t.Error("error must be kverror.ErrKeyExists")
 	}
 }
 
 func TestSet(t *testing.T) {
 	mockStorage := &mockStorage{
-		memoryDB: map[string]any{},
+		memoryDB: kvstorage.MemoryDB{},
 	}
 	kvsStoreService := kvstoreservice.New(
 		kvstoreservice.WithStorage(mockStorage),
@@ -79,3 +79,69 @@ func TestSet(t *testing.T) {
 		}
 	}
 }
+
+func TestSetWithTTL(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{},
+	}
+	kvsStoreService := kvstoreservice.New(
+		kvstoreservice.WithStorage(mockStorage),
+	)
+
+	setRequest := kvstoreservice.SetRequest{
+		Key:   "session",
+		Value: "vigo",
+		TTL:   time.Minute,
+	}
+
+	res, err := kvsStoreService.Set(context.Background(), &setRequest)
+	if err != nil {
+		t.Fatalf("error occurred, err: %v", err)
+	}
+
+	if res.ExpiresAt.IsZero() {
+		t.Error("expires at should be set")
+	}
+}
+
+func TestSetWithExpiredTTL(t *testing.T) {
+	kvsStoreService := kvstoreservice.New(
+		kvstoreservice.WithStorage(kvstorage.New()),
+	)
+
+	setRequest := kvstoreservice.SetRequest{
+		Key:   "session",
+		Value: "vigo",
+		TTL:   time.Nanosecond,
+	}
+
+	res, err := kvsStoreService.Set(context.Background(), &setRequest)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if res.Value != "vigo" {
+		t.Errorf("want: vigo, got: %v", res.Value)
+	}
+}
+
+func TestSetWithoutItem(t *testing.T) {
+	mockStorage := &mockStorage{
+		memoryDB: kvstorage.MemoryDB{"username": {Value: "vigo"}},
+	}
+	kvsStoreService := kvstoreservice.New(
+		kvstoreservice.WithStorage(mockStorage),
+	)
+
+	setRequest := kvstoreservice.SetRequest{
+		Key:   "username",
+		Value: "vigo",
+	}
+
+	res, err := kvsStoreService.Set(context.Background(), &setRequest)
+	if res != nil {
+		t.Errorf("response must be nil!")
+	}
+	if !errors.Is(err, kverror.ErrUnknown) {
+		t.Errorf("wrong error, want: %v, got: %v", kverror.ErrUnknown, err)
+	}
+}
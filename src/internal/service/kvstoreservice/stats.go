@@ -0,0 +1,25 @@
+package kvstoreservice
+
+import (
+	"context"
+)
+
+// Stats returns size of the namespace from storage counters, items are not
+// walked.
+func (s *kvStoreService) Stats(ctx context.Context) (*StatsResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		for _, stats := range s.storage.Stats() {
+			if stats.Name == s.namespace {
+				return &StatsResponse{
+					Keys:    stats.Keys,
+					Expires: stats.Expires,
+					Bytes:   stats.Bytes,
+				}, nil
+			}
+		}
+		return &StatsResponse{}, nil
+	}
+}
@@ -0,0 +1,53 @@
+package kvstoreservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestStatsWithCancel(t *testing.T) {
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(&mockStorage{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := kvsStoreService.Stats(ctx); !errors.Is(err, ctx.Err()) {
+		t.Error("error not occurred")
+	}
+}
+
+func TestStats(t *testing.T) {
+	mockStorage := &mockStorage{
+		stats: []kvstorage.NamespaceStats{
+			{Name: "default", Keys: 3, Expires: 1, Bytes: 30},
+			{Name: "team-a", Keys: 2, Bytes: 20},
+		},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	tcs := []struct {
+		testName string
+		service  kvstoreservice.KVStoreService
+		want     kvstoreservice.StatsResponse
+	}{
+		{"default", kvsStoreService, kvstoreservice.StatsResponse{Keys: 3, Expires: 1, Bytes: 30}},
+		{"namespace", kvsStoreService.Namespace("team-a"), kvstoreservice.StatsResponse{Keys: 2, Bytes: 20}},
+		{"missing namespace", kvsStoreService.Namespace("team-b"), kvstoreservice.StatsResponse{}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.testName, func(t *testing.T) {
+			res, err := tc.service.Stats(context.Background())
+			if err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+			if *res != tc.want {
+				t.Errorf("wrong stats, want: %+v, got: %+v", tc.want, *res)
+			}
+		})
+	}
+}
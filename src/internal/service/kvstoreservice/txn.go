@@ -0,0 +1,66 @@
+package kvstoreservice
+
+import (
+	"context"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+)
+
+func (s *kvStoreService) Txn(ctx context.Context, tr *TxnRequest) (*TxnResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		compares := make([]kvstorage.TxnCompare, len(tr.Compares))
+		for i, c := range tr.Compares {
+			compares[i] = kvstorage.TxnCompare{
+				Key:     c.Key,
+				Target:  kvstorage.CompareTarget(c.Target),
+				Result:  kvstorage.CompareResult(c.Result),
+				Version: c.Version,
+				Value:   c.Value,
+			}
+		}
+
+		result, err := s.storage.Txn(compares, txnOps(tr.Success), txnOps(tr.Failure))
+		if err != nil {
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Txn storage.Txn err: %w", err)
+		}
+
+		response := &TxnResponse{
+			Succeeded: result.Succeeded,
+			Revision:  result.Revision,
+			Results:   make([]TxnOpResponse, len(result.Results)),
+		}
+		for i, r := range result.Results {
+			response.Results[i] = TxnOpResponse{
+				Type:    string(r.Type),
+				Key:     r.Key,
+				Deleted: r.Deleted,
+			}
+			if r.Item != nil {
+				response.Results[i].Item = &ItemResponse{
+					Key:       r.Key,
+					Value:     r.Item.Value,
+					ExpiresAt: r.Item.ExpiresAt,
+					Version:   r.Item.Version,
+				}
+			}
+		}
+		return response, nil
+	}
+}
+
+func txnOps(ops []TxnOp) []kvstorage.TxnOp {
+	storageOps := make([]kvstorage.TxnOp, len(ops))
+	for i, op := range ops {
+		storageOps[i] = kvstorage.TxnOp{
+			Type:  kvstorage.TxnOpType(op.Type),
+			Key:   op.Key,
+			Value: op.Value,
+			TTL:   op.TTL,
+		}
+	}
+	return storageOps
+}
@@ -0,0 +1,75 @@
+package kvstoreservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestTxnWithCancel(t *testing.T) {
+	mockStorage := &mockStorage{}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := kvsStoreService.Txn(ctx, nil); !errors.Is(err, ctx.Err()) {
+		t.Error("error not occurred")
+	}
+}
+
+func TestTxnWithStorageError(t *testing.T) {
+	mockStorage := &mockStorage{
+		txnErr: kverror.ErrInvalidTxn,
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	res, err := kvsStoreService.Txn(context.Background(), &kvstoreservice.TxnRequest{})
+	if res != nil {
+		t.Errorf("response must be nil!")
+	}
+
+	if !errors.Is(err, kverror.ErrInvalidTxn) {
+		t.Error("error must be kverror.ErrInvalidTxn")
+	}
+}
+
+func TestTxn(t *testing.T) {
+	mockStorage := &mockStorage{
+		txnResponse: &kvstorage.TxnResponse{
+			Succeeded: true,
+			Revision:  5,
+			Results: []kvstorage.TxnOpResult{
+				{Type: kvstorage.TxnOpPut, Key: "key", Item: &kvstorage.Item{Value: "value", Version: 5}},
+				{Type: kvstorage.TxnOpDelete, Key: "other", Deleted: true},
+			},
+		},
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	res, err := kvsStoreService.Txn(context.Background(), &kvstoreservice.TxnRequest{
+		Success: []kvstoreservice.TxnOp{
+			{Type: "put", Key: "key", Value: "value"},
+			{Type: "delete", Key: "other"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("error occurred, err: %v", err)
+	}
+
+	if !res.Succeeded || res.Revision != 5 {
+		t.Errorf("unexpected response: %+v", res)
+	}
+
+	if res.Results[0].Item == nil || res.Results[0].Item.Key != "key" || res.Results[0].Item.Version != 5 {
+		t.Errorf("unexpected put result: %+v", res.Results[0])
+	}
+
+	if !res.Results[1].Deleted || res.Results[1].Item != nil {
+		t.Errorf("unexpected delete result: %+v", res.Results[1])
+	}
+}
@@ -2,7 +2,9 @@ package kvstoreservice
 
 import (
 	"context"
-	"fmt"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
 )
 
 func (s *kvStoreService) Update(ctx context.Context, sr *UpdateRequest) (*ItemResponse, error) {
@@ -10,13 +12,18 @@ func (s *kvStoreService) Update(ctx context.Context, sr *UpdateRequest) (*ItemRe
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
-		value, err := s.storage.Update(sr.Key, sr.Value)
+		item, err := s.storage.Update(sr.Key, sr.Value, sr.TTL, sr.Version)
 		if err != nil {
-			return nil, fmt.Errorf("kvstoreservice.Set storage.Update err: %w", err)
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Set storage.Update err: %w", err)
+		}
+		if item == nil {
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Update err: %w", kverror.New(kverror.ErrUnknown, "update", sr.Key, "no item written"))
 		}
 		return &ItemResponse{
-			Key:   sr.Key,
-			Value: value,
+			Key:       sr.Key,
+			Value:     item.Value,
+			ExpiresAt: item.ExpiresAt,
+			Version:   item.Version,
 		}, nil
 	}
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
 func TestUpdateWithCancel(t *testing.T) {
@@ -41,17 +42,15 @@ func TestUpdateWithStorageError(t *testing.T) {
 		t.Errorf("response must be nil!")
 	}
 
-	var kvErr *kverror.Error
-
-	if !errors.As(err, &kvErr) {
+	if !errors.Is(err, kverror.ErrKeyNotFound) {
 		t.Error("error must be kverror.ErrKeyNotFound")
 	}
 }
 
 func TestUpdate(t *testing.T) {
 	mockStorage := &mockStorage{
-		memoryDB: map[string]any{
-			"key": "value",
+		memoryDB: kvstorage.MemoryDB{
+			"key": {Value: "value"},
 		},
 	}
 	kvsStoreService := kvstoreservice.New(
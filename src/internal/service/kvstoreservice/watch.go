@@ -0,0 +1,80 @@
+package kvstoreservice
+
+import (
+	"context"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+)
+
+// Watch streams changes until ctx is done or storage closes the watcher
+// (slow consumer), returned channel is closed in both cases.
+func (s *kvStoreService) Watch(ctx context.Context, wr *WatchRequest) (<-chan WatchEvent, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		watcher, err := s.storage.Watch(kvstorage.WatchOptions{
+			Key:      wr.Key,
+			Prefix:   wr.Prefix,
+			Revision: wr.Revision,
+		})
+		if err != nil {
+			return nil, tracing.Errorf(ctx, "kvstoreservice.Watch storage.Watch err: %w", err)
+		}
+
+		events := make(chan WatchEvent)
+
+		go func() {
+			defer close(events)
+			defer watcher.Close()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event, ok := <-watcher.Events():
+					if !ok {
+						return
+					}
+
+					select {
+					case events <- watchEvent(event):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+
+		return events, nil
+	}
+}
+
+func watchEvent(event kvstorage.Event) WatchEvent {
+	we := WatchEvent{
+		Type:     string(event.Type),
+		Key:      event.Key,
+		Revision: event.Revision,
+	}
+
+	if event.Item != nil {
+		we.Item = &ItemResponse{
+			Key:       event.Key,
+			Value:     event.Item.Value,
+			ExpiresAt: event.Item.ExpiresAt,
+			Version:   event.Item.Version,
+		}
+	}
+
+	if event.PrevItem != nil {
+		we.PrevItem = &ItemResponse{
+			Key:       event.Key,
+			Value:     event.PrevItem.Value,
+			ExpiresAt: event.PrevItem.ExpiresAt,
+			Version:   event.PrevItem.Version,
+		}
+	}
+
+	return we
+}
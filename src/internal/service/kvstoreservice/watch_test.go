@@ -0,0 +1,71 @@
+package kvstoreservice_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestWatchWithCancel(t *testing.T) {
+	mockStorage := &mockStorage{}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := kvsStoreService.Watch(ctx, &kvstoreservice.WatchRequest{}); !errors.Is(err, ctx.Err()) {
+		t.Error("error not occurred")
+	}
+}
+
+func TestWatchWithStorageError(t *testing.T) {
+	mockStorage := &mockStorage{
+		watchErr: kverror.ErrRevisionCompacted,
+	}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	if _, err := kvsStoreService.Watch(context.Background(), &kvstoreservice.WatchRequest{Revision: 1}); !errors.Is(err, kverror.ErrRevisionCompacted) {
+		t.Error("error must be kverror.ErrRevisionCompacted")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	watcher := &mockWatcher{events: make(chan kvstorage.Event, 2)}
+	mockStorage := &mockStorage{watcher: watcher}
+	kvsStoreService := kvstoreservice.New(kvstoreservice.WithStorage(mockStorage))
+
+	watcher.events <- kvstorage.Event{
+		Type:     kvstorage.EventPut,
+		Key:      "key",
+		Revision: 2,
+		Item:     &kvstorage.Item{Value: "new", Version: 2},
+		PrevItem: &kvstorage.Item{Value: "old", Version: 1},
+	}
+	close(watcher.events)
+
+	events, err := kvsStoreService.Watch(context.Background(), &kvstoreservice.WatchRequest{Key: "key"})
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	event, ok := <-events
+	if !ok {
+		t.Fatal("events channel is closed")
+	}
+
+	if event.Type != "put" || event.Item.Value != "new" || event.PrevItem.Value != "old" || event.Revision != 2 {
+		t.Errorf("wrong event: %+v", event)
+	}
+
+	if _, ok = <-events; ok {
+		t.Error("events channel should be closed with storage watcher")
+	}
+
+	if !watcher.closed {
+		t.Error("storage watcher should be closed")
+	}
+}
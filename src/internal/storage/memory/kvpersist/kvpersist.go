@@ -0,0 +1,239 @@
+// Package kvpersist opens kvstorage with its snapshots and append-only file
+// and runs its background jobs, shared by the kvstore server and the
+// embeddable kvstore.
+package kvpersist
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+// defaults.
+const (
+	DefaultSnapshotInterval    = 5 * time.Minute
+	DefaultExpireSweepInterval = 1 * time.Second
+)
+
+// Storage is a kvstorage.Storer with its persistence. Storage which is
+// opened (not injected by WithStorage) is snapshotted periodically and on
+// Close, its append-only file is closed on Close.
+type Storage struct {
+	storage             kvstorage.Storer
+	db                  kvstorage.MemoryDB
+	injected            bool
+	aof                 *kvstorage.AOF
+	aofPath             string
+	aofFsync            kvstorage.FsyncPolicy
+	snapshotDir         string
+	snapshotInterval    time.Duration
+	expireSweepInterval time.Duration
+	logger              *slog.Logger
+
+	stopJobs []func()
+}
+
+// Option represents storage option type.
+type Option func(*Storage)
+
+// WithStorage sets storage option, injected storage is used as is: nothing
+// is loaded and persisted, only expired keys are swept.
+func WithStorage(strg kvstorage.Storer) Option {
+	return func(s *Storage) {
+		s.storage = strg
+		s.injected = strg != nil
+	}
+}
+
+// WithMemoryDB sets initial items option.
+func WithMemoryDB(db kvstorage.MemoryDB) Option {
+	return func(s *Storage) {
+		s.db = db
+	}
+}
+
+// WithSnapshotDir sets snapshot directory option. Storage is restored from
+// the latest snapshot of dir, snapshots are saved periodically and on
+// Close.
+func WithSnapshotDir(dir string) Option {
+	return func(s *Storage) {
+		s.snapshotDir = dir
+	}
+}
+
+// WithSnapshotInterval sets periodic snapshot interval option, defaults to
+// DefaultSnapshotInterval.
+func WithSnapshotInterval(d time.Duration) Option {
+	return func(s *Storage) {
+		s.snapshotInterval = d
+	}
+}
+
+// WithExpireSweepInterval sets interval of expired keys removal option,
+// defaults to DefaultExpireSweepInterval.
+func WithExpireSweepInterval(d time.Duration) Option {
+	return func(s *Storage) {
+		s.expireSweepInterval = d
+	}
+}
+
+// WithAOF sets append-only file option, every mutation is logged to path
+// and replayed on Open.
+func WithAOF(path string, policy kvstorage.FsyncPolicy) Option {
+	return func(s *Storage) {
+		s.aofPath = path
+		s.aofFsync = policy
+	}
+}
+
+// WithLogger sets logger option, defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Storage) {
+		s.logger = l
+	}
+}
+
+// Open loads the latest snapshot and replays append-only file, unless
+// storage is injected. Background jobs are started by Start.
+func Open(options ...Option) (*Storage, error) {
+	s := &Storage{
+		aofFsync:            kvstorage.FsyncEverySecond,
+		snapshotInterval:    DefaultSnapshotInterval,
+		expireSweepInterval: DefaultExpireSweepInterval,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+
+	if s.injected {
+		return s, nil
+	}
+
+	storageOptions := []kvstorage.StorageOption{
+		kvstorage.WithMemoryDB(s.db),
+		kvstorage.WithSnapshotDir(s.snapshotDir),
+	}
+
+	if s.snapshotDir != "" {
+		if err := os.MkdirAll(s.snapshotDir, 0o750); err != nil {
+			return nil, fmt.Errorf("kvpersist.Open os.MkdirAll err: %w", err)
+		}
+
+		snapshot, err := kvstorage.LoadSnapshot(s.snapshotDir)
+		if err != nil {
+			return nil, fmt.Errorf("kvpersist.Open kvstorage.LoadSnapshot err: %w", err)
+		}
+
+		s.logger.Info("snapshot loaded", "dir", s.snapshotDir, "keys", len(snapshot.Items))
+		storageOptions = append(storageOptions, kvstorage.WithSnapshot(snapshot))
+	}
+
+	if s.aofPath != "" {
+		aof, err := kvstorage.OpenAOF(s.aofPath, s.aofFsync)
+		if err != nil {
+			return nil, fmt.Errorf("kvpersist.Open kvstorage.OpenAOF err: %w", err)
+		}
+
+		s.logger.Info("append-only file enabled", "path", s.aofPath)
+		storageOptions = append(storageOptions, kvstorage.WithAOF(aof))
+		s.aof = aof
+	}
+
+	s.storage = kvstorage.New(storageOptions...)
+
+	return s, nil
+}
+
+// Storer returns the storage.
+func (s *Storage) Storer() kvstorage.Storer {
+	return s.storage
+}
+
+// Persisted reports whether snapshots are saved.
+func (s *Storage) Persisted() bool {
+	return !s.injected && s.snapshotDir != ""
+}
+
+// Start starts periodic snapshots (if persisted) and expired keys removal,
+// they run until Close.
+func (s *Storage) Start() {
+	if s.Persisted() {
+		s.stopJobs = append(s.stopJobs, runPeriodically(s.snapshotInterval, func() {
+			if err := s.storage.Snapshot(); err != nil {
+				s.logger.Error("periodic snapshot", "err", err)
+			}
+		}))
+	}
+
+	s.stopJobs = append(s.stopJobs, runPeriodically(s.expireSweepInterval, func() {
+		if removed := s.storage.DeleteExpired(); removed > 0 {
+			s.logger.Debug("expired keys removed", "count", removed)
+		}
+	}))
+}
+
+// Close stops background jobs, saves the final snapshot and closes
+// append-only file. Append-only file is closed even if the snapshot fails,
+// errors are joined.
+func (s *Storage) Close() error {
+	for _, stop := range s.stopJobs {
+		stop()
+	}
+	s.stopJobs = nil
+
+	var errs []error
+
+	if s.Persisted() {
+		if err := s.storage.Snapshot(); err != nil {
+			errs = append(errs, fmt.Errorf("kvpersist.Close storage.Snapshot err: %w", err))
+		} else {
+			s.logger.Info("shutdown snapshot saved", "dir", s.snapshotDir)
+		}
+	}
+
+	if s.aof != nil {
+		if err := s.aof.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("kvpersist.Close aof.Close err: %w", err))
+		}
+		s.aof = nil
+	}
+
+	return errors.Join(errs...)
+}
+
+// runPeriodically calls fn on every interval in background, returned func
+// stops it and waits until running call (if any) is finished.
+func runPeriodically(interval time.Duration, fn func()) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
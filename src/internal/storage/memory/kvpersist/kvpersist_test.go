@@ -0,0 +1,106 @@
+package kvpersist_test
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvpersist"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func open(t *testing.T, options ...kvpersist.Option) *kvpersist.Storage {
+	t.Helper()
+
+	options = append(options, kvpersist.WithLogger(discardLogger))
+
+	storage, err := kvpersist.Open(options...)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return storage
+}
+
+func TestOpenRestores(t *testing.T) {
+	dir := t.TempDir()
+	options := []kvpersist.Option{
+		kvpersist.WithSnapshotDir(filepath.Join(dir, "snapshots")),
+		kvpersist.WithAOF(filepath.Join(dir, "kvstore.aof"), kvstorage.FsyncAlways),
+	}
+
+	storage := open(t, options...)
+	storage.Start()
+
+	if _, err := storage.Storer().Set("snapshotted", "value", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err := storage.Storer().Snapshot(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err := storage.Storer().Set("logged", "value", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err := storage.Close(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	storage = open(t, options...)
+	defer func() { _ = storage.Close() }()
+
+	for _, key := range []string{"snapshotted", "logged"} {
+		if _, err := storage.Storer().Get(key); err != nil {
+			t.Errorf("key %q must be restored: %v", key, err)
+		}
+	}
+}
+
+func TestCloseAfterFailedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snapshotDir := filepath.Join(dir, "snapshots")
+
+	storage := open(
+		t,
+		kvpersist.WithSnapshotDir(snapshotDir),
+		kvpersist.WithAOF(filepath.Join(dir, "kvstore.aof"), kvstorage.FsyncAlways),
+	)
+	storage.Start()
+
+	if err := os.RemoveAll(snapshotDir); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if err := storage.Close(); err == nil {
+		t.Error("snapshot error expected")
+	}
+
+	// append-only file is closed anyway, writes can not be logged.
+	if _, err := storage.Storer().Set("key", "value", 0); err == nil {
+		t.Error("append-only file must be closed")
+	}
+}
+
+func TestInjectedStorage(t *testing.T) {
+	dir := t.TempDir()
+	strg := kvstorage.New(kvstorage.WithSnapshotDir(dir))
+
+	storage := open(t, kvpersist.WithStorage(strg), kvpersist.WithSnapshotDir(dir))
+	if storage.Storer() != strg {
+		t.Error("injected storage must be used as is")
+	}
+	if storage.Persisted() {
+		t.Error("injected storage must not be persisted")
+	}
+
+	storage.Start()
+	if err := storage.Close(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("injected storage must not be snapshotted, got: %v", files)
+	}
+}
@@ -0,0 +1,207 @@
+package kvstorage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// FsyncPolicy represents append-only file fsync policy type.
+type FsyncPolicy int
+
+// fsync policies.
+const (
+	FsyncEverySecond FsyncPolicy = iota // fsync once per second, default
+	FsyncAlways                         // fsync after every write
+	FsyncNever                          // let operating system decide
+)
+
+const (
+	aofOpSet    = "set"
+	aofOpDelete = "delete"
+	aofOpExpire = "expire" // removal of expired item
+	aofOpTxn    = "txn"    // nested records are applied atomically
+
+	aofOpCreateNamespace = "create_namespace"
+	aofOpDropNamespace   = "drop_namespace"
+
+	aofFilePerm = 0o600
+)
+
+type aofRecord struct {
+	Op        string      `json:"op"`
+	Namespace string      `json:"ns,omitempty"` // empty means DefaultNamespace
+	Key       string      `json:"key"`
+	Value     any         `json:"value,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	Version   uint64      `json:"version"`
+	Records   []aofRecord `json:"records,omitempty"`
+}
+
+// AOF represents append-only file which logs every mutation of storage.
+type AOF struct {
+	mu      sync.Mutex // guarding file and dirty
+	file    *os.File
+	dirty   bool
+	policy  FsyncPolicy
+	records []aofRecord // read on open, replayed by storage
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// OpenAOF opens (or creates) append-only file at given path and reads
+// existing records for replay. Partially written last record (crash during
+// write) is discarded, any other corruption is reported as error.
+func OpenAOF(path string, policy FsyncPolicy) (*AOF, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, aofFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("kvstorage.OpenAOF os.OpenFile err: %w", err)
+	}
+
+	records, offset, err := readAOFRecords(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if err = file.Truncate(offset); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("kvstorage.OpenAOF file.Truncate err: %w", err)
+	}
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("kvstorage.OpenAOF file.Seek err: %w", err)
+	}
+
+	aof := &AOF{
+		file:    file,
+		policy:  policy,
+		records: records,
+		done:    make(chan struct{}),
+	}
+
+	if policy == FsyncEverySecond {
+		aof.wg.Add(1)
+		go aof.syncEverySecond()
+	}
+
+	return aof, nil
+}
+
+func readAOFRecords(r io.Reader) ([]aofRecord, int64, error) {
+	var records []aofRecord
+	var offset int64
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// anything left without newline is a partially written record.
+			return records, offset, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("kvstorage.OpenAOF reader.ReadBytes err: %w", err)
+		}
+
+		var record aofRecord
+		if err = json.Unmarshal(bytes.TrimSpace(line), &record); err != nil {
+			return nil, 0, fmt.Errorf("kvstorage.OpenAOF corrupted record at offset %d err: %w", offset, err)
+		}
+
+		records = append(records, record)
+		offset += int64(len(line))
+	}
+}
+
+func (a *AOF) syncEverySecond() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-ticker.C:
+			a.mu.Lock()
+			if a.dirty {
+				if err := a.file.Sync(); err == nil {
+					a.dirty = false
+				}
+			}
+			a.mu.Unlock()
+		}
+	}
+}
+
+func (a *AOF) append(record aofRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("kvstorage aof json.Marshal err: %w", err)
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err = a.file.Write(line); err != nil {
+		return fmt.Errorf("kvstorage aof file.Write err: %w", err)
+	}
+
+	switch a.policy {
+	case FsyncAlways:
+		if err = a.file.Sync(); err != nil {
+			return fmt.Errorf("kvstorage aof file.Sync err: %w", err)
+		}
+	case FsyncEverySecond:
+		a.dirty = true
+	case FsyncNever:
+	}
+
+	return nil
+}
+
+// truncate drops all records, called after a snapshot covers them.
+func (a *AOF) truncate() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.file.Truncate(0); err != nil {
+		return fmt.Errorf("kvstorage aof file.Truncate err: %w", err)
+	}
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("kvstorage aof file.Seek err: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("kvstorage aof file.Sync err: %w", err)
+	}
+
+	a.dirty = false
+	return nil
+}
+
+// Close flushes and closes append-only file.
+func (a *AOF) Close() error {
+	close(a.done)
+	a.wg.Wait()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.file.Sync(); err != nil {
+		_ = a.file.Close()
+		return fmt.Errorf("kvstorage.AOF.Close file.Sync err: %w", err)
+	}
+	if err := a.file.Close(); err != nil {
+		return fmt.Errorf("kvstorage.AOF.Close file.Close err: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package kvstorage_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestAOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncAlways)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof))
+	if _, err = storage.Set("key", "value", 0); err != nil {
+		t.Errorf("set err: %v", err)
+	}
+	if _, err = storage.Set("other", "value", 0); err != nil {
+		t.Errorf("set err: %v", err)
+	}
+	if _, err = storage.Update("key", "value2", time.Hour, 0); err != nil {
+		t.Errorf("update err: %v", err)
+	}
+	if err = storage.Delete("other", 0); err != nil {
+		t.Errorf("delete err: %v", err)
+	}
+
+	if err = aof.Close(); err != nil {
+		t.Errorf("close aof err: %v", err)
+	}
+
+	aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("reopen aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage = kvstorage.New(kvstorage.WithAOF(aof))
+
+	item, err := storage.Get("key")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Value != "value2" {
+		t.Errorf("want: value2, got: %v", item.Value)
+	}
+	if item.ExpiresAt.IsZero() {
+		t.Error("expiration should be replayed")
+	}
+
+	if _, err = storage.Get("other"); err == nil {
+		t.Error("deleted key should not be replayed")
+	}
+}
+
+func TestAOFPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	content := `{"op":"set","key":"key","value":"value"}` + "\n" + `{"op":"set","key":"ot`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file err: %v", err)
+	}
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncEverySecond)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof))
+
+	if db, _ := storage.List(); len(db) != 1 {
+		t.Errorf("want: 1 item, got: %d", len(db))
+	}
+}
+
+func TestAOFCorruptedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	content := `{"op":"set","key":"ke` + "\n" + `{"op":"set","key":"other","value":"value"}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file err: %v", err)
+	}
+
+	if _, err := kvstorage.OpenAOF(path, kvstorage.FsyncNever); err == nil {
+		t.Error("error not occurred")
+	}
+}
+
+func TestAOFReplayExpire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncAlways)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof))
+	if _, err = storage.Namespace("default").Set("session", "token", 10*time.Millisecond); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if err = storage.CreateNamespace("team"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+	if _, err = storage.Namespace("team").Set("session", "token", 10*time.Millisecond); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if removed := storage.DeleteExpired(); removed != 2 {
+		t.Fatalf("want: 2 removed, got: %d", removed)
+	}
+
+	if err = aof.Close(); err != nil {
+		t.Errorf("close aof err: %v", err)
+	}
+
+	aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("reopen aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage = kvstorage.New(kvstorage.WithAOF(aof))
+
+	if removed := storage.DeleteExpired(); removed != 0 {
+		t.Errorf("expirations should be replayed, want: 0 removed, got: %d", removed)
+	}
+
+	item, err := storage.Set("key", "value", 0)
+	if err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if item.Version != 6 {
+		t.Errorf("revision of expirations should be replayed, want: version 6, got: %d", item.Version)
+	}
+}
@@ -1,45 +1,262 @@
 package kvstorage
 
 import (
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
 )
 
 var _ Storer = (*memoryStorage)(nil) // compile time proof
 
-// MemoryDB is a custom type definition uses map[string]any for in memory-db type.
-type MemoryDB map[string]any
+// Item represents stored value with its metadata.
+type Item struct {
+	Value     any       `json:"value"`
+	ExpiresAt time.Time `json:"expires_at"` // zero value means never expires
+	Version   uint64    `json:"version"`    // storage revision of the last write
+}
+
+// expired reports whether item is expired at given time.
+func (i *Item) expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt)
+}
+
+// clone returns a copy of item, stored items are never handed out.
+func (i *Item) clone() *Item {
+	c := *i
+	return &c
+}
+
+// MemoryDB is a custom type definition uses map[string]*Item for in memory-db type.
+type MemoryDB map[string]*Item
 
-// Storer defines storage behaviours.
+// DefaultNamespace is the namespace used when no namespace is given, it
+// always exists and can not be dropped.
+const DefaultNamespace = "default"
+
+// Storer defines storage behaviours. Zero version means unconditional
+// Update/Delete, otherwise it must match the current version of the item.
+// Key operations work on a single namespace, DefaultNamespace unless the
+// Storer is obtained via Namespace. Snapshot, DeleteExpired and Stats
+// cover the whole storage.
 type Storer interface {
-	Set(key string, value any) (any, error)
-	Get(key string) (any, error)
-	Update(key string, value any) (any, error)
-	Delete(key string) error
-	List() MemoryDB
+	Set(key string, value any, ttl time.Duration) (*Item, error)
+	Get(key string) (*Item, error)
+	Update(key string, value any, ttl time.Duration, version uint64) (*Item, error)
+	Delete(key string, version uint64) error
+	List() (MemoryDB, error)
+	Scan(opts ScanOptions) (*ScanResult, error)
+	Snapshot() error
+	DeleteExpired() int
+	Txn(compares []TxnCompare, success, failure []TxnOp) (*TxnResponse, error)
+	GetMany(keys []string) []BatchResult
+	SetMany(items []BatchSetItem) []BatchResult
+	DeleteMany(keys []string) []BatchResult
+	Namespace(name string) Storer
+	CreateNamespace(name string) error
+	DropNamespace(name string) error
+	Namespaces() []string
+	Watch(opts WatchOptions) (Watcher, error)
+	Stats() []NamespaceStats
+}
+
+// store is shared by all namespace bound memoryStorage values.
+type store struct {
+	mu          sync.RWMutex // guarding namespaces and revision
+	namespaces  map[string]*keyspace
+	revision    uint64 // monotonically increasing, bumped by every mutation
+	aof         *AOF
+	snapshotDir string
+	watch       *watchHub
 }
 
 type memoryStorage struct {
-	mu sync.RWMutex // guarding db only
-	db MemoryDB
+	*store
+	namespace string
 }
 
 // StorageOption represents storage option type.
 type StorageOption func(*memoryStorage)
 
-// WithMemoryDB sets db option.
+// WithMemoryDB sets db of DefaultNamespace option.
 func WithMemoryDB(db MemoryDB) StorageOption {
 	return func(s *memoryStorage) {
-		s.db = db
+		s.namespaces[DefaultNamespace] = newKeyspace(db)
+	}
+}
+
+// WithAOF sets append-only file option. Records of the file are replayed
+// on top of db and every mutation is logged to it afterwards.
+func WithAOF(aof *AOF) StorageOption {
+	return func(s *memoryStorage) {
+		s.aof = aof
 	}
 }
 
-// New instantiates new storage instance.
+// New instantiates new storage instance bound to DefaultNamespace.
 func New(options ...StorageOption) Storer {
-	ms := &memoryStorage{}
+	ms := &memoryStorage{
+		store: &store{
+			namespaces: make(map[string]*keyspace),
+			watch:      newWatchHub(),
+		},
+		namespace: DefaultNamespace,
+	}
 
 	for _, o := range options {
 		o(ms)
 	}
 
+	if ms.namespaces[DefaultNamespace] == nil {
+		ms.namespaces[DefaultNamespace] = newKeyspace(nil)
+	}
+
+	if ms.aof != nil {
+		for _, record := range ms.aof.records {
+			ms.apply(record)
+		}
+		ms.aof.records = nil
+	}
+
+	ms.assignMissingVersions()
+	ms.watch.since = ms.revision + 1
+
 	return ms
 }
+
+// assignMissingVersions versions items which are seeded or restored from
+// data written before versioning existed.
+func (ms *memoryStorage) assignMissingVersions() {
+	names := make([]string, 0, len(ms.namespaces))
+	for name, ks := range ms.namespaces {
+		names = append(names, name)
+		for _, item := range ks.items {
+			if item.Version > ms.revision {
+				ms.revision = item.Version
+			}
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		db := ms.namespaces[name].items
+
+		keys := make([]string, 0, len(db))
+		for key, item := range db {
+			if item.Version == 0 {
+				keys = append(keys, key)
+			}
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			ms.revision++
+			db[key].Version = ms.revision
+		}
+	}
+}
+
+// expiresAt calculates expiration time for given ttl, zero ttl means no expiration.
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// checkNamespace reports error of op if bound namespace does not exist
+// (anymore), caller must hold the lock.
+func (ms *memoryStorage) checkNamespace(op string) error {
+	if _, ok := ms.namespaces[ms.namespace]; !ok {
+		return kverror.New(kverror.ErrNamespaceNotFound, op, ms.namespace, "'"+ms.namespace+"' does not exist")
+	}
+	return nil
+}
+
+// lookup returns live item for given key in bound namespace, caller must
+// hold the lock.
+func (ms *memoryStorage) lookup(key string) (*Item, bool) {
+	ks, ok := ms.namespaces[ms.namespace]
+	if !ok {
+		return nil, false
+	}
+
+	item, ok := ks.items[key]
+	if !ok || item.expired(time.Now()) {
+		return nil, false
+	}
+	return item, true
+}
+
+// apply applies given record to db, caller must hold the lock.
+func (ms *memoryStorage) apply(record aofRecord) {
+	if record.Version > ms.revision {
+		ms.revision = record.Version
+	}
+
+	namespace := record.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	switch record.Op {
+	case aofOpSet:
+		ks, ok := ms.namespaces[namespace]
+		if !ok {
+			ks = newKeyspace(nil)
+			ms.namespaces[namespace] = ks
+		}
+		ks.set(record.Key, &Item{
+			Value:     record.Value,
+			ExpiresAt: record.ExpiresAt,
+			Version:   record.Version,
+		})
+	case aofOpDelete, aofOpExpire:
+		if ks, ok := ms.namespaces[namespace]; ok {
+			ks.delete(record.Key)
+		}
+	case aofOpTxn:
+		for _, nested := range record.Records {
+			nested.Namespace = record.Namespace
+			nested.Version = record.Version
+			ms.apply(nested)
+		}
+	case aofOpCreateNamespace:
+		if _, ok := ms.namespaces[namespace]; !ok {
+			ms.namespaces[namespace] = newKeyspace(nil)
+		}
+	case aofOpDropNamespace:
+		delete(ms.namespaces, namespace)
+	}
+}
+
+// commit stamps record with the next revision, logs it to append-only file
+// (if any) then applies it to db and notifies watchers, caller must hold the
+// lock.
+func (ms *memoryStorage) commit(record aofRecord) (*Item, error) {
+	record.Version = ms.revision + 1
+	if record.Namespace == "" && ms.namespace != DefaultNamespace {
+		record.Namespace = ms.namespace
+	}
+
+	if ms.aof != nil {
+		if err := ms.aof.append(record); err != nil {
+			return nil, err
+		}
+	}
+
+	ms.watch.publish(ms.applyWithEvents(record)...)
+
+	if record.Op != aofOpSet {
+		return nil, nil
+	}
+
+	// written item is returned even if its ttl has already run out.
+	if ks, ok := ms.namespaces[ms.namespace]; ok {
+		if item, ok := ks.items[record.Key]; ok {
+			return item.clone(), nil
+		}
+	}
+	return nil, nil
+}
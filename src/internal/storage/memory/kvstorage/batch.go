@@ -0,0 +1,110 @@
+package kvstorage
+
+import (
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
+
+// BatchSetItem is an input of SetMany.
+type BatchSetItem struct {
+	Key   string
+	Value any
+	TTL   time.Duration
+}
+
+// BatchResult is per-key result of bulk operation. Err wraps one of kverror
+// sentinels without data since key is already part of the result.
+type BatchResult struct {
+	Key  string
+	Item *Item
+	Err  error
+}
+
+// GetMany gets given keys under single read lock, expired items are reported
+// as not found and left to DeleteExpired.
+func (ms *memoryStorage) GetMany(keys []string) []BatchResult {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	results := make([]BatchResult, len(keys))
+	if err := ms.checkNamespace("get_many"); err != nil {
+		return failBatch(results, keys, err)
+	}
+
+	for i, key := range keys {
+		results[i].Key = key
+
+		item, ok := ms.lookup(key)
+		if !ok {
+			results[i].Err = kverror.New(kverror.ErrKeyNotFound, "get_many", key, "")
+			continue
+		}
+		results[i].Item = item.clone()
+	}
+	return results
+}
+
+// SetMany sets given items under single lock with Set semantics, existing
+// keys are not overwritten.
+func (ms *memoryStorage) SetMany(items []BatchSetItem) []BatchResult {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	results := make([]BatchResult, len(items))
+	if err := ms.checkNamespace("set_many"); err != nil {
+		keys := make([]string, len(items))
+		for i, bi := range items {
+			keys[i] = bi.Key
+		}
+		return failBatch(results, keys, err)
+	}
+
+	for i, bi := range items {
+		results[i].Key = bi.Key
+
+		if _, ok := ms.lookup(bi.Key); ok {
+			results[i].Err = kverror.New(kverror.ErrKeyExists, "set_many", bi.Key, "")
+			continue
+		}
+
+		results[i].Item, results[i].Err = ms.commit(aofRecord{
+			Op:        aofOpSet,
+			Key:       bi.Key,
+			Value:     bi.Value,
+			ExpiresAt: expiresAt(bi.TTL),
+		})
+	}
+	return results
+}
+
+// DeleteMany deletes given keys unconditionally under single lock.
+func (ms *memoryStorage) DeleteMany(keys []string) []BatchResult {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	results := make([]BatchResult, len(keys))
+	if err := ms.checkNamespace("delete_many"); err != nil {
+		return failBatch(results, keys, err)
+	}
+
+	for i, key := range keys {
+		results[i].Key = key
+
+		if _, ok := ms.lookup(key); !ok {
+			results[i].Err = kverror.New(kverror.ErrKeyNotFound, "delete_many", key, "")
+			continue
+		}
+
+		_, results[i].Err = ms.commit(aofRecord{Op: aofOpDelete, Key: key})
+	}
+	return results
+}
+
+// failBatch fails every key of the batch with given error.
+func failBatch(results []BatchResult, keys []string, err error) []BatchResult {
+	for i, key := range keys {
+		results[i] = BatchResult{Key: key, Err: err}
+	}
+	return results
+}
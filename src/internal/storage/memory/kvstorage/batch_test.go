@@ -0,0 +1,87 @@
+package kvstorage_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestGetMany(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"a": {Value: "1"},
+		}),
+	)
+
+	results := storage.GetMany([]string{"a", "b"})
+	if len(results) != 2 {
+		t.Fatalf("want: 2 results, got: %d", len(results))
+	}
+
+	if results[0].Err != nil || results[0].Item.Value != "1" {
+		t.Errorf("want: 1, got: %+v", results[0])
+	}
+
+	if !errors.Is(results[1].Err, kverror.ErrKeyNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrKeyNotFound, results[1].Err)
+	}
+}
+
+func TestSetMany(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"a": {Value: "1"},
+		}),
+	)
+
+	results := storage.SetMany([]kvstorage.BatchSetItem{
+		{Key: "a", Value: "2"},
+		{Key: "b", Value: "2"},
+		{Key: "b", Value: "3"},
+	})
+
+	if !errors.Is(results[0].Err, kverror.ErrKeyExists) {
+		t.Errorf("want: %v, got: %v", kverror.ErrKeyExists, results[0].Err)
+	}
+
+	if results[1].Err != nil || results[1].Item.Version != 2 {
+		t.Errorf("want: version 2, got: %+v", results[1])
+	}
+
+	if !errors.Is(results[2].Err, kverror.ErrKeyExists) {
+		t.Errorf("duplicate key should fail, got: %v", results[2].Err)
+	}
+
+	item, err := storage.Get("b")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Value != "2" {
+		t.Errorf("want: 2, got: %v", item.Value)
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"a": {Value: "1"},
+			"b": {Value: "2"},
+		}),
+	)
+
+	results := storage.DeleteMany([]string{"a", "c"})
+
+	if results[0].Err != nil {
+		t.Errorf("delete err: %v", results[0].Err)
+	}
+
+	if !errors.Is(results[1].Err, kverror.ErrKeyNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrKeyNotFound, results[1].Err)
+	}
+
+	if db, _ := storage.List(); len(db) != 1 {
+		t.Errorf("want: 1 key left, got: %d", len(db))
+	}
+}
@@ -1,13 +1,26 @@
 package kvstorage
 
-func (ms *memoryStorage) Delete(key string) error {
-	if _, err := ms.Get(key); err != nil { // can not delete! key doesn't exist
-		return err
-	}
+import (
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
 
+func (ms *memoryStorage) Delete(key string, version uint64) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	delete(ms.db, key)
-	return nil
+	if err := ms.checkNamespace("delete"); err != nil {
+		return err
+	}
+
+	item, ok := ms.lookup(key)
+	if !ok { // can not delete! key doesn't exist
+		return kverror.New(kverror.ErrKeyNotFound, "delete", key, "'"+key+"' does not exist")
+	}
+
+	if err := checkVersion("delete", key, item, version); err != nil {
+		return err
+	}
+
+	_, err := ms.commit(aofRecord{Op: aofOpDelete, Key: key})
+	return err
 }
@@ -1,29 +1,47 @@
 package kvstorage_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
 func TestDeleteEmpty(t *testing.T) {
 	storage := kvstorage.New()
 
-	if err := storage.Delete("key"); err == nil {
+	if err := storage.Delete("key", 0); err == nil {
 		t.Error("error not occurred")
 	}
 }
 
+func TestDeleteVersionMismatch(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"key": {Value: "value", Version: 7},
+		}),
+	)
+
+	if err := storage.Delete("key", 6); !errors.Is(err, kverror.ErrVersionMismatch) {
+		t.Errorf("want: %v, got: %v", kverror.ErrVersionMismatch, err)
+	}
+
+	if err := storage.Delete("key", 7); err != nil {
+		t.Errorf("delete err: %v", err)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	key := "key"
-	memoryStorage := map[string]any{
-		key: "value",
+	memoryStorage := kvstorage.MemoryDB{
+		key: {Value: "value"},
 	}
 	storage := kvstorage.New(
 		kvstorage.WithMemoryDB(memoryStorage),
 	)
 
-	if err := storage.Delete(key); err != nil {
+	if err := storage.Delete(key, 0); err != nil {
 		t.Error("error occurred")
 	}
 }
@@ -0,0 +1,48 @@
+package kvstorage
+
+import (
+	"time"
+)
+
+// DeleteExpired removes expired items of all namespaces and returns number
+// of removed items.
+func (ms *memoryStorage) DeleteExpired() int {
+	now := time.Now()
+
+	expired := make(map[string][]string)
+
+	ms.mu.RLock()
+	for name, ks := range ms.namespaces {
+		for key, item := range ks.items {
+			if item.expired(now) {
+				expired[name] = append(expired[name], key)
+			}
+		}
+	}
+	ms.mu.RUnlock()
+
+	if len(expired) == 0 {
+		return 0
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var removed int
+	for name, keys := range expired {
+		ks, ok := ms.namespaces[name]
+		if !ok { // dropped meanwhile
+			continue
+		}
+
+		for _, key := range keys {
+			if item, exists := ks.items[key]; exists && item.expired(now) {
+				if err := ms.expire(name, key); err != nil { // retried by next call
+					continue
+				}
+				removed++
+			}
+		}
+	}
+	return removed
+}
@@ -0,0 +1,71 @@
+package kvstorage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestGetExpired(t *testing.T) {
+	storage := kvstorage.New()
+
+	if _, err := storage.Set("key", "value", 10*time.Millisecond); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	if _, err := storage.Get("key"); err != nil {
+		t.Errorf("key should not be expired yet, err: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := storage.Get("key"); err == nil {
+		t.Error("error not occurred")
+	}
+
+	if _, err := storage.Set("key", "value", 0); err != nil {
+		t.Errorf("expired key should be settable, err: %v", err)
+	}
+}
+
+func TestDeleteExpired(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"expired":   {Value: "value", ExpiresAt: time.Now().Add(-time.Second)},
+			"not-yet":   {Value: "value", ExpiresAt: time.Now().Add(time.Hour)},
+			"permanent": {Value: "value"},
+		}),
+	)
+
+	if removed := storage.DeleteExpired(); removed != 1 {
+		t.Errorf("want: 1, got: %d", removed)
+	}
+
+	if db, _ := storage.List(); len(db) != 2 {
+		t.Errorf("want: 2 items, got: %d", len(db))
+	}
+}
+
+func TestWriteExpiredTTL(t *testing.T) {
+	storage := kvstorage.New()
+
+	item, err := storage.Set("key", "value", time.Nanosecond)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item == nil || item.Value != "value" {
+		t.Fatalf("written item must be returned, got: %v", item)
+	}
+
+	if _, err = storage.Set("live", "value", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	item, err = storage.Update("live", "updated", time.Nanosecond, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item == nil || item.Value != "updated" {
+		t.Errorf("written item must be returned, got: %v", item)
+	}
+}
@@ -1,18 +1,35 @@
 package kvstorage
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 )
 
-func (ms *memoryStorage) Get(key string) (any, error) {
+func (ms *memoryStorage) Get(key string) (*Item, error) {
 	ms.mu.RLock()
-	defer ms.mu.RUnlock()
+	if err := ms.checkNamespace("get"); err != nil {
+		ms.mu.RUnlock()
+		return nil, err
+	}
+	item, ok := ms.namespaces[ms.namespace].items[key]
+	ms.mu.RUnlock()
 
-	value, ok := ms.db[key]
 	if !ok {
-		return nil, fmt.Errorf("%w", kverror.ErrKeyNotFound.AddData("'"+key+"' does not exist"))
+		return nil, kverror.New(kverror.ErrKeyNotFound, "get", key, "'"+key+"' does not exist")
 	}
-	return value, nil
+
+	if item.expired(time.Now()) {
+		ms.mu.Lock()
+		if ks, exists := ms.namespaces[ms.namespace]; exists { // may be dropped meanwhile
+			if item, ok = ks.items[key]; ok && item.expired(time.Now()) { // may be set again meanwhile
+				_ = ms.expire(ms.namespace, key) // retried by DeleteExpired
+			}
+		}
+		ms.mu.Unlock()
+
+		return nil, kverror.New(kverror.ErrKeyNotFound, "get", key, "'"+key+"' does not exist")
+	}
+
+	return item.clone(), nil
 }
@@ -16,19 +16,19 @@ func TestGetEmpty(t *testing.T) {
 
 func TestGet(t *testing.T) {
 	key := "key"
-	memoryStorage := map[string]any{
-		key: "value",
+	memoryStorage := kvstorage.MemoryDB{
+		key: {Value: "value"},
 	}
 	storage := kvstorage.New(
 		kvstorage.WithMemoryDB(memoryStorage),
 	)
 
-	value, err := storage.Get(key)
+	item, err := storage.Get(key)
 	if err != nil {
-		t.Error("error occurred")
+		t.Fatal("error occurred")
 	}
 
-	if value != "value" {
+	if item.Value != "value" {
 		t.Error("value not equal")
 	}
 }
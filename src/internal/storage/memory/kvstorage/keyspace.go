@@ -0,0 +1,97 @@
+package kvstorage
+
+import (
+	"encoding/json"
+)
+
+// keyspace holds items of a namespace with their ordered key index, every
+// mutation of items must go through it to keep the index and size in sync.
+type keyspace struct {
+	items   MemoryDB
+	keys    *skiplist
+	bytes   int64 // approximate size of keys and values
+	expires int   // number of items with ttl
+}
+
+func newKeyspace(items MemoryDB) *keyspace {
+	if items == nil {
+		items = make(MemoryDB)
+	}
+
+	ks := &keyspace{items: items, keys: newSkiplist()}
+	for key, item := range items {
+		ks.keys.insert(key)
+		ks.bytes += itemSize(key, item)
+		ks.expires += expiresCount(item)
+	}
+	return ks
+}
+
+func (ks *keyspace) set(key string, item *Item) {
+	if prev, ok := ks.items[key]; ok {
+		ks.bytes -= itemSize(key, prev)
+		ks.expires -= expiresCount(prev)
+	} else {
+		ks.keys.insert(key)
+	}
+	ks.items[key] = item
+	ks.bytes += itemSize(key, item)
+	ks.expires += expiresCount(item)
+}
+
+func (ks *keyspace) delete(key string) {
+	if item, ok := ks.items[key]; ok {
+		ks.bytes -= itemSize(key, item)
+		ks.expires -= expiresCount(item)
+		delete(ks.items, key)
+		ks.keys.remove(key)
+	}
+}
+
+// expiresCount returns 1 if item has ttl, 0 otherwise.
+func expiresCount(item *Item) int {
+	if item.ExpiresAt.IsZero() {
+		return 0
+	}
+	return 1
+}
+
+// itemSize returns approximate size of key and value of item.
+func itemSize(key string, item *Item) int64 {
+	return int64(len(key)) + valueSize(item.Value)
+}
+
+// valueSize returns approximate size of value, json decoded types are
+// walked, others are measured by their json encoding.
+func valueSize(value any) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	case bool:
+		return 1
+	case float64, int, int64, uint64:
+		return 8
+	case []any:
+		var size int64
+		for _, e := range v {
+			size += valueSize(e)
+		}
+		return size
+	case map[string]any:
+		var size int64
+		for k, e := range v {
+			size += int64(len(k)) + valueSize(e)
+		}
+		return size
+	}
+
+	j, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return int64(len(j))
+}
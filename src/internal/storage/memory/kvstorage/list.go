@@ -1,7 +1,25 @@
 package kvstorage
 
-func (ms *memoryStorage) List() MemoryDB {
+import (
+	"time"
+)
+
+func (ms *memoryStorage) List() (MemoryDB, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
-	return ms.db
+
+	if err := ms.checkNamespace("list"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := ms.namespaces[ms.namespace].items
+	db := make(MemoryDB, len(items))
+	for key, item := range items {
+		if item.expired(now) {
+			continue
+		}
+		db[key] = item.clone()
+	}
+	return db, nil
 }
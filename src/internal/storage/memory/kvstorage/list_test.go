@@ -9,14 +9,17 @@ import (
 
 func TestList(t *testing.T) {
 	key := "key"
-	memoryStorage := kvstorage.MemoryDB(map[string]any{
-		key: "value",
-	})
+	memoryStorage := kvstorage.MemoryDB{
+		key: {Value: "value"},
+	}
 	storage := kvstorage.New(
 		kvstorage.WithMemoryDB(memoryStorage),
 	)
 
-	value := storage.List()
+	value, err := storage.List()
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
 
 	if !reflect.DeepEqual(value, memoryStorage) {
 		t.Error("value not equal")
@@ -0,0 +1,70 @@
+package kvstorage
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
+
+var namespaceNameRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,64}$`)
+
+// Namespace returns storage bound to given namespace, it shares lock,
+// revision and append-only file with the receiver. Operations fail with
+// kverror.ErrNamespaceNotFound until the namespace is created.
+func (ms *memoryStorage) Namespace(name string) Storer {
+	return &memoryStorage{
+		store:     ms.store,
+		namespace: name,
+	}
+}
+
+// CreateNamespace creates empty namespace, name must match
+// [a-zA-Z0-9_.-]{1,64}.
+func (ms *memoryStorage) CreateNamespace(name string) error {
+	if !namespaceNameRe.MatchString(name) {
+		return kverror.New(kverror.ErrInvalidNamespace, "create_namespace", name, "'"+name+"' must match "+namespaceNameRe.String())
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.namespaces[name]; ok {
+		return kverror.New(kverror.ErrNamespaceExists, "create_namespace", name, "'"+name+"' already exist")
+	}
+
+	_, err := ms.commit(aofRecord{Op: aofOpCreateNamespace, Namespace: name})
+	return err
+}
+
+// DropNamespace drops namespace with all of its items at once, storage
+// bound to the namespace fails afterwards.
+func (ms *memoryStorage) DropNamespace(name string) error {
+	if name == DefaultNamespace {
+		return kverror.New(kverror.ErrInvalidNamespace, "drop_namespace", name, "'"+name+"' can not be dropped")
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, ok := ms.namespaces[name]; !ok {
+		return kverror.New(kverror.ErrNamespaceNotFound, "drop_namespace", name, "'"+name+"' does not exist")
+	}
+
+	_, err := ms.commit(aofRecord{Op: aofOpDropNamespace, Namespace: name})
+	return err
+}
+
+// Namespaces returns sorted namespace names.
+func (ms *memoryStorage) Namespaces() []string {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	names := make([]string, 0, len(ms.namespaces))
+	for name := range ms.namespaces {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,181 @@
+package kvstorage_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestCreateNamespace(t *testing.T) {
+	storage := kvstorage.New()
+
+	if err := storage.CreateNamespace("team-a"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+
+	if err := storage.CreateNamespace("team-a"); !errors.Is(err, kverror.ErrNamespaceExists) {
+		t.Errorf("want: %v, got: %v", kverror.ErrNamespaceExists, err)
+	}
+
+	if err := storage.CreateNamespace("team/a"); !errors.Is(err, kverror.ErrInvalidNamespace) {
+		t.Errorf("want: %v, got: %v", kverror.ErrInvalidNamespace, err)
+	}
+
+	want := []string{kvstorage.DefaultNamespace, "team-a"}
+	if got := storage.Namespaces(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	storage := kvstorage.New()
+	if err := storage.CreateNamespace("team-a"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+
+	teamA := storage.Namespace("team-a")
+
+	if _, err := storage.Set("key", "default", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err := teamA.Set("key", "team-a", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	item, err := teamA.Get("key")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Value != "team-a" {
+		t.Errorf("want: team-a, got: %v", item.Value)
+	}
+
+	db, err := teamA.List()
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+	if len(db) != 1 {
+		t.Errorf("want: 1 item, got: %d", len(db))
+	}
+
+	if _, err = storage.Namespace("missing").Get("key"); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrNamespaceNotFound, err)
+	}
+}
+
+func TestDropNamespace(t *testing.T) {
+	storage := kvstorage.New()
+	if err := storage.CreateNamespace("team-a"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+
+	teamA := storage.Namespace("team-a")
+	if _, err := teamA.Set("key", "value", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	if err := storage.DropNamespace(kvstorage.DefaultNamespace); !errors.Is(err, kverror.ErrInvalidNamespace) {
+		t.Errorf("want: %v, got: %v", kverror.ErrInvalidNamespace, err)
+	}
+
+	if err := storage.DropNamespace("team-a"); err != nil {
+		t.Fatalf("drop namespace err: %v", err)
+	}
+
+	if err := storage.DropNamespace("team-a"); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrNamespaceNotFound, err)
+	}
+
+	if _, err := teamA.Set("key", "value", 0); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrNamespaceNotFound, err)
+	}
+
+	// recreated namespace starts empty.
+	if err := storage.CreateNamespace("team-a"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+	if _, err := teamA.Get("key"); !errors.Is(err, kverror.ErrKeyNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrKeyNotFound, err)
+	}
+}
+
+func TestNamespaceAOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof))
+	for _, name := range []string{"team-a", "team-b"} {
+		if err = storage.CreateNamespace(name); err != nil {
+			t.Fatalf("create namespace err: %v", err)
+		}
+		if _, err = storage.Namespace(name).Set("key", name, 0); err != nil {
+			t.Fatalf("set err: %v", err)
+		}
+	}
+	if err = storage.DropNamespace("team-b"); err != nil {
+		t.Fatalf("drop namespace err: %v", err)
+	}
+
+	if err = aof.Close(); err != nil {
+		t.Fatalf("close aof err: %v", err)
+	}
+
+	aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("reopen aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage = kvstorage.New(kvstorage.WithAOF(aof))
+
+	want := []string{kvstorage.DefaultNamespace, "team-a"}
+	if got := storage.Namespaces(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+
+	item, err := storage.Namespace("team-a").Get("key")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Value != "team-a" {
+		t.Errorf("want: team-a, got: %v", item.Value)
+	}
+}
+
+func TestNamespaceSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	storage := kvstorage.New(kvstorage.WithSnapshotDir(dir))
+	if err := storage.CreateNamespace("team-a"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+	if _, err := storage.Namespace("team-a").Set("key", "value", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if err := storage.Snapshot(); err != nil {
+		t.Fatalf("snapshot err: %v", err)
+	}
+
+	snapshot, err := kvstorage.LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("load snapshot err: %v", err)
+	}
+
+	restored := kvstorage.New(kvstorage.WithSnapshot(snapshot))
+
+	item, err := restored.Namespace("team-a").Get("key")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Value != "value" {
+		t.Errorf("want: value, got: %v", item.Value)
+	}
+}
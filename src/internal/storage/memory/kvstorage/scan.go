@@ -0,0 +1,78 @@
+package kvstorage
+
+import (
+	"strings"
+	"time"
+)
+
+// ScanOptions filters keys of Scan, all bounds are optional and combined.
+type ScanOptions struct {
+	Prefix string // only keys starting with prefix
+	Start  string // inclusive lower bound
+	End    string // exclusive upper bound
+	After  string // exclusive lower bound, last key of the previous page
+	Limit  int    // zero means unlimited
+}
+
+// ScanItem is an element of ScanResult.
+type ScanItem struct {
+	Key  string
+	Item *Item
+}
+
+// ScanResult represents page of Scan. Next is the last key of the page if
+// there are more keys, pass it as After to get the next page.
+type ScanResult struct {
+	Items []ScanItem
+	Next  string
+}
+
+// Scan returns live items of bound namespace in lexicographic key order.
+func (ms *memoryStorage) Scan(opts ScanOptions) (*ScanResult, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	if err := ms.checkNamespace("scan"); err != nil {
+		return nil, err
+	}
+
+	from := opts.Start
+	if opts.Prefix > from {
+		from = opts.Prefix
+	}
+	if opts.After > from {
+		from = opts.After
+	}
+
+	ks := ms.namespaces[ms.namespace]
+	now := time.Now()
+	result := &ScanResult{}
+
+	for node := ks.keys.seek(from); node != nil; node = node.next[0] {
+		key := node.key
+
+		if opts.After != "" && key <= opts.After {
+			continue
+		}
+		if opts.End != "" && key >= opts.End {
+			break
+		}
+		if !strings.HasPrefix(key, opts.Prefix) { // keys with prefix are contiguous
+			break
+		}
+
+		item := ks.items[key]
+		if item.expired(now) {
+			continue
+		}
+
+		if opts.Limit > 0 && len(result.Items) == opts.Limit {
+			result.Next = result.Items[len(result.Items)-1].Key
+			break
+		}
+
+		result.Items = append(result.Items, ScanItem{Key: key, Item: item.clone()})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,170 @@
+package kvstorage_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func scanKeys(result *kvstorage.ScanResult) []string {
+	keys := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+func TestScan(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"user:3":   {Value: "3"},
+			"user:1":   {Value: "1"},
+			"order:1":  {Value: "1"},
+			"user:2":   {Value: "2"},
+			"user:22":  {Value: "22", ExpiresAt: time.Now().Add(-time.Second)},
+			"userdata": {Value: "data"},
+		}),
+	)
+
+	tests := []struct {
+		name string
+		opts kvstorage.ScanOptions
+		want []string
+	}{
+		{"all", kvstorage.ScanOptions{}, []string{"order:1", "user:1", "user:2", "user:3", "userdata"}},
+		{"prefix", kvstorage.ScanOptions{Prefix: "user:"}, []string{"user:1", "user:2", "user:3"}},
+		{"range", kvstorage.ScanOptions{Start: "user:2", End: "user:3"}, []string{"user:2"}},
+		{"after", kvstorage.ScanOptions{Prefix: "user:", After: "user:1"}, []string{"user:2", "user:3"}},
+		{"start before prefix", kvstorage.ScanOptions{Prefix: "user:", Start: "a"}, []string{"user:1", "user:2", "user:3"}},
+		{"start after prefix", kvstorage.ScanOptions{Prefix: "order:", Start: "user:"}, []string{}},
+	}
+
+	for _, tc := range tests {
+		result, err := storage.Scan(tc.opts)
+		if err != nil {
+			t.Fatalf("%s: scan err: %v", tc.name, err)
+		}
+
+		if got := scanKeys(result); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: want: %v, got: %v", tc.name, tc.want, got)
+		}
+
+		if result.Next != "" {
+			t.Errorf("%s: next should be empty, got: %s", tc.name, result.Next)
+		}
+	}
+}
+
+func TestScanPagination(t *testing.T) {
+	storage := kvstorage.New()
+	for i := 0; i < 25; i++ {
+		if _, err := storage.Set(fmt.Sprintf("key:%02d", i), i, 0); err != nil {
+			t.Fatalf("set err: %v", err)
+		}
+	}
+
+	var keys []string
+	var pages int
+
+	opts := kvstorage.ScanOptions{Prefix: "key:", Limit: 10}
+	for {
+		result, err := storage.Scan(opts)
+		if err != nil {
+			t.Fatalf("scan err: %v", err)
+		}
+		pages++
+		keys = append(keys, scanKeys(result)...)
+
+		if result.Next == "" {
+			break
+		}
+		opts.After = result.Next
+	}
+
+	if pages != 3 {
+		t.Errorf("want: 3 pages, got: %d", pages)
+	}
+
+	if len(keys) != 25 || keys[0] != "key:00" || keys[24] != "key:24" {
+		t.Errorf("wrong keys: %v", keys)
+	}
+}
+
+func TestScanIndexFollowsMutations(t *testing.T) {
+	storage := kvstorage.New()
+
+	for _, key := range []string{"c", "a", "b"} {
+		if _, err := storage.Set(key, key, 0); err != nil {
+			t.Fatalf("set err: %v", err)
+		}
+	}
+
+	if err := storage.Delete("b", 0); err != nil {
+		t.Fatalf("delete err: %v", err)
+	}
+
+	if _, err := storage.Txn(nil, []kvstorage.TxnOp{
+		{Type: kvstorage.TxnOpPut, Key: "d", Value: "d"},
+		{Type: kvstorage.TxnOpDelete, Key: "a"},
+	}, nil); err != nil {
+		t.Fatalf("txn err: %v", err)
+	}
+
+	result, err := storage.Scan(kvstorage.ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan err: %v", err)
+	}
+
+	if want, got := []string{"c", "d"}, scanKeys(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestScanNamespaceNotFound(t *testing.T) {
+	storage := kvstorage.New()
+
+	if _, err := storage.Namespace("missing").Scan(kvstorage.ScanOptions{}); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrNamespaceNotFound, err)
+	}
+}
+
+func TestScanMatchesList(t *testing.T) {
+	storage := kvstorage.New()
+	rnd := rand.New(rand.NewSource(1)) // nolint:gosec
+
+	for i := 0; i < 2000; i++ {
+		key := fmt.Sprintf("%d", rnd.Intn(500))
+		if rnd.Intn(3) == 0 {
+			_ = storage.Delete(key, 0)
+			continue
+		}
+		_, _ = storage.Set(key, i, 0)
+	}
+
+	db, err := storage.List()
+	if err != nil {
+		t.Fatalf("list err: %v", err)
+	}
+
+	want := make([]string, 0, len(db))
+	for key := range db {
+		want = append(want, key)
+	}
+	sort.Strings(want)
+
+	result, err := storage.Scan(kvstorage.ScanOptions{})
+	if err != nil {
+		t.Fatalf("scan err: %v", err)
+	}
+
+	if got := scanKeys(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("scan order does not match sorted list, want: %d keys, got: %d keys", len(want), len(got))
+	}
+}
@@ -1,19 +1,22 @@
 package kvstorage
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 )
 
-func (ms *memoryStorage) Set(key string, value any) (any, error) {
-	if _, err := ms.Get(key); err == nil {
-		return nil, fmt.Errorf("%w", kverror.ErrKeyExists.AddData("'"+key+"' already exist"))
-	}
-
+func (ms *memoryStorage) Set(key string, value any, ttl time.Duration) (*Item, error) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	ms.db[key] = value
-	return value, nil
+	if err := ms.checkNamespace("set"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := ms.lookup(key); ok {
+		return nil, kverror.New(kverror.ErrKeyExists, "set", key, "'"+key+"' already exist")
+	}
+
+	return ms.commit(aofRecord{Op: aofOpSet, Key: key, Value: value, ExpiresAt: expiresAt(ttl)})
 }
@@ -8,17 +8,17 @@ import (
 
 func TestSet(t *testing.T) {
 	key := "key"
-	memoryStorage := kvstorage.MemoryDB(map[string]any{})
+	memoryStorage := kvstorage.MemoryDB{}
 	storage := kvstorage.New(
 		kvstorage.WithMemoryDB(memoryStorage),
 	)
 
-	val, err := storage.Set(key, "value")
+	item, err := storage.Set(key, "value", 0)
 	if err != nil {
-		t.Errorf("want: value, got: %v, err: %v", val, err)
+		t.Errorf("want: value, got: %v, err: %v", item, err)
 	}
 
-	if _, err := storage.Set(key, "xxx"); err == nil {
+	if _, err := storage.Set(key, "xxx", 0); err == nil {
 		t.Error("error not occurred")
 	}
 }
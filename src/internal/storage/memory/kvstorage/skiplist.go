@@ -0,0 +1,105 @@
+package kvstorage
+
+import (
+	"math/rand"
+)
+
+const (
+	skiplistMaxLevel = 24 // enough for 4^24 keys with p = 1/4
+	skiplistP        = 4
+)
+
+type skiplistNode struct {
+	key  string
+	next []*skiplistNode
+}
+
+// skiplist keeps keys in lexicographic order, it is not safe for concurrent
+// use, guarded by the storage lock.
+type skiplist struct {
+	head  *skiplistNode
+	level int
+	rnd   *rand.Rand
+}
+
+func newSkiplist() *skiplist {
+	return &skiplist{
+		head:  &skiplistNode{next: make([]*skiplistNode, skiplistMaxLevel)},
+		level: 1,
+		rnd:   rand.New(rand.NewSource(rand.Int63())), // nolint:gosec
+	}
+}
+
+func (s *skiplist) randomLevel() int {
+	level := 1
+	for level < skiplistMaxLevel && s.rnd.Intn(skiplistP) == 0 {
+		level++
+	}
+	return level
+}
+
+// predecessors returns the last node before key on every level.
+func (s *skiplist) predecessors(key string) []*skiplistNode {
+	update := make([]*skiplistNode, skiplistMaxLevel)
+
+	node := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && node.next[i].key < key {
+			node = node.next[i]
+		}
+		update[i] = node
+	}
+	return update
+}
+
+// insert adds key, no-op if key already exists.
+func (s *skiplist) insert(key string) {
+	update := s.predecessors(key)
+	if next := update[0].next[0]; next != nil && next.key == key {
+		return
+	}
+
+	level := s.randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			update[i] = s.head
+		}
+		s.level = level
+	}
+
+	node := &skiplistNode{key: key, next: make([]*skiplistNode, level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+}
+
+// remove deletes key, no-op if key does not exist.
+func (s *skiplist) remove(key string) {
+	update := s.predecessors(key)
+
+	node := update[0].next[0]
+	if node == nil || node.key != key {
+		return
+	}
+
+	for i := 0; i < len(node.next); i++ {
+		update[i].next[i] = node.next[i]
+	}
+
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+}
+
+// seek returns the first node with key greater than or equal to given key,
+// nil if there is none.
+func (s *skiplist) seek(key string) *skiplistNode {
+	node := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && node.next[i].key < key {
+			node = node.next[i]
+		}
+	}
+	return node.next[0]
+}
@@ -0,0 +1,276 @@
+package kvstorage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// snapshot file layout:
+//
+//	magic (4 bytes) | format version (uint32) | payload length (uint64) | payload | crc32 of payload (uint32)
+//
+// all integers are big endian, payload is json encoded Snapshot.
+// format version 1 has no item metadata, items are plain values. format
+// version 2 has no revision, items are versioned while loading. format
+// version 3 has no namespaces, items belong to DefaultNamespace. format
+// version 4 has no binary values.
+const (
+	snapshotMagic         = "KVSS"
+	snapshotFormatVersion = 5
+	snapshotFilePrefix    = "snapshot-"
+	snapshotFileExt       = ".kvs"
+	snapshotRetain        = 3
+	snapshotHeaderSize    = 16
+	snapshotFooterSize    = 4
+)
+
+// Snapshot represents point-in-time copy of storage. Items belong to
+// DefaultNamespace, Namespaces holds the others.
+type Snapshot struct {
+	CreatedAt  time.Time           `json:"created_at"`
+	Revision   uint64              `json:"revision"`
+	Items      MemoryDB            `json:"items"`
+	Namespaces map[string]MemoryDB `json:"namespaces,omitempty"`
+}
+
+type snapshotPayloadV1 struct {
+	CreatedAt time.Time      `json:"created_at"`
+	Items     map[string]any `json:"items"`
+}
+
+// WithSnapshot sets namespaces and revision from given snapshot.
+func WithSnapshot(snapshot *Snapshot) StorageOption {
+	return func(s *memoryStorage) {
+		s.namespaces = map[string]*keyspace{DefaultNamespace: newKeyspace(snapshot.Items)}
+		for name, db := range snapshot.Namespaces {
+			s.namespaces[name] = newKeyspace(db)
+		}
+		s.revision = snapshot.Revision
+	}
+}
+
+// WithSnapshotDir sets snapshot directory option, Snapshot writes files to
+// this directory.
+func WithSnapshotDir(dir string) StorageOption {
+	return func(s *memoryStorage) {
+		s.snapshotDir = dir
+	}
+}
+
+// Snapshot writes point-in-time copy of all namespaces to snapshot directory
+// atomically (temp file + rename) and truncates append-only file since all
+// of its records are included in the snapshot.
+func (ms *memoryStorage) Snapshot() error {
+	if ms.snapshotDir == "" {
+		return errors.New("kvstorage.Snapshot err: snapshot directory is not set")
+	}
+
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	now := time.Now().UTC()
+	snapshot := Snapshot{
+		CreatedAt:  now,
+		Revision:   ms.revision,
+		Items:      ms.namespaces[DefaultNamespace].items,
+		Namespaces: make(map[string]MemoryDB, len(ms.namespaces)-1),
+	}
+	for name, ks := range ms.namespaces {
+		if name != DefaultNamespace {
+			snapshot.Namespaces[name] = ks.items
+		}
+	}
+
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("kvstorage.Snapshot json.Marshal err: %w", err)
+	}
+
+	name := snapshotFilePrefix + fmt.Sprintf("%020d", now.UnixNano()) + snapshotFileExt
+	if err = writeFileAtomic(filepath.Join(ms.snapshotDir, name), encodeSnapshot(payload)); err != nil {
+		return err
+	}
+
+	if ms.aof != nil {
+		if err = ms.aof.truncate(); err != nil {
+			return err
+		}
+	}
+
+	return removeStaleSnapshots(ms.snapshotDir)
+}
+
+// LoadSnapshot loads the newest snapshot in given directory. Empty snapshot
+// is returned if there is no snapshot yet. Truncated or corrupted snapshot is
+// refused with an error.
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	files, err := snapshotFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return &Snapshot{Items: make(MemoryDB)}, nil
+	}
+
+	path := filepath.Join(dir, files[len(files)-1])
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("kvstorage.LoadSnapshot os.ReadFile err: %w", err)
+	}
+
+	version, payload, err := decodeSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("kvstorage.LoadSnapshot '%s' err: %w", path, err)
+	}
+
+	snapshot := &Snapshot{Items: make(MemoryDB)}
+
+	if version == 1 {
+		var snapshotV1 snapshotPayloadV1
+		if err = json.Unmarshal(payload, &snapshotV1); err != nil {
+			return nil, fmt.Errorf("kvstorage.LoadSnapshot json.Unmarshal err: %w", err)
+		}
+
+		snapshot.CreatedAt = snapshotV1.CreatedAt
+		for key, value := range snapshotV1.Items {
+			snapshot.Items[key] = &Item{Value: value}
+		}
+		return snapshot, nil
+	}
+
+	if err = json.Unmarshal(payload, snapshot); err != nil {
+		return nil, fmt.Errorf("kvstorage.LoadSnapshot json.Unmarshal err: %w", err)
+	}
+	return snapshot, nil
+}
+
+func encodeSnapshot(payload []byte) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, snapshotHeaderSize+len(payload)+snapshotFooterSize))
+
+	buf.WriteString(snapshotMagic)
+	_ = binary.Write(buf, binary.BigEndian, uint32(snapshotFormatVersion))
+	_ = binary.Write(buf, binary.BigEndian, uint64(len(payload)))
+	buf.Write(payload)
+	_ = binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(payload))
+
+	return buf.Bytes()
+}
+
+func decodeSnapshot(data []byte) (uint32, []byte, error) {
+	if len(data) < snapshotHeaderSize+snapshotFooterSize {
+		return 0, nil, fmt.Errorf("truncated snapshot: %w", io.ErrUnexpectedEOF)
+	}
+
+	if string(data[:4]) != snapshotMagic {
+		return 0, nil, errors.New("invalid snapshot magic")
+	}
+
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version == 0 || version > snapshotFormatVersion {
+		return 0, nil, fmt.Errorf("unsupported snapshot format version: %d", version)
+	}
+
+	length := binary.BigEndian.Uint64(data[8:16])
+	if uint64(len(data)-snapshotHeaderSize-snapshotFooterSize) != length {
+		return 0, nil, fmt.Errorf("truncated snapshot: %w", io.ErrUnexpectedEOF)
+	}
+
+	payload := data[snapshotHeaderSize : len(data)-snapshotFooterSize]
+	checksum := binary.BigEndian.Uint32(data[len(data)-snapshotFooterSize:])
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return 0, nil, errors.New("snapshot checksum mismatch")
+	}
+
+	return version, payload, nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("kvstorage os.CreateTemp err: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() // no-op after successful rename
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("kvstorage tmp.Write err: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("kvstorage tmp.Sync err: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("kvstorage tmp.Close err: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("kvstorage os.Rename err: %w", err)
+	}
+
+	// persist rename itself.
+	d, err := os.Open(filepath.Clean(dir))
+	if err != nil {
+		return fmt.Errorf("kvstorage os.Open err: %w", err)
+	}
+	defer func() { _ = d.Close() }()
+
+	if err = d.Sync(); err != nil {
+		return fmt.Errorf("kvstorage dir.Sync err: %w", err)
+	}
+	return nil
+}
+
+// snapshotFiles returns snapshot file names in given directory, oldest first.
+func snapshotFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("kvstorage os.ReadDir err: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotFilePrefix) || !strings.HasSuffix(name, snapshotFileExt) {
+			continue
+		}
+
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, snapshotFilePrefix), snapshotFileExt)
+		if _, err = strconv.ParseInt(ts, 10, 64); err != nil {
+			continue
+		}
+		files = append(files, name)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func removeStaleSnapshots(dir string) error {
+	files, err := snapshotFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for len(files) > snapshotRetain {
+		if err = os.Remove(filepath.Join(dir, files[0])); err != nil {
+			return fmt.Errorf("kvstorage os.Remove err: %w", err)
+		}
+		files = files[1:]
+	}
+	return nil
+}
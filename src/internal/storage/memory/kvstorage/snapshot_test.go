@@ -0,0 +1,154 @@
+package kvstorage_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestSnapshotWithoutDir(t *testing.T) {
+	storage := kvstorage.New()
+
+	if err := storage.Snapshot(); err == nil {
+		t.Error("error not occurred")
+	}
+}
+
+func TestLoadSnapshotEmptyDir(t *testing.T) {
+	snapshot, err := kvstorage.LoadSnapshot(t.TempDir())
+	if err != nil {
+		t.Fatalf("load snapshot err: %v", err)
+	}
+
+	if len(snapshot.Items) != 0 {
+		t.Errorf("want: empty db, got: %v", snapshot.Items)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	dir := t.TempDir()
+	memoryStorage := kvstorage.MemoryDB{
+		"key":   {Value: "value"},
+		"other": {Value: float64(42), ExpiresAt: time.Now().Add(time.Hour).Round(0).UTC()},
+	}
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(memoryStorage),
+		kvstorage.WithSnapshotDir(dir),
+	)
+
+	for i := 0; i < 5; i++ {
+		if err := storage.Snapshot(); err != nil {
+			t.Fatalf("snapshot err: %v", err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "snapshot-*.kvs"))
+	if len(files) != 3 {
+		t.Errorf("stale snapshots should be removed, want: 3, got: %d", len(files))
+	}
+
+	if err := storage.Delete("key", 0); err != nil {
+		t.Fatalf("delete err: %v", err)
+	}
+	if err := storage.Snapshot(); err != nil {
+		t.Fatalf("snapshot err: %v", err)
+	}
+
+	snapshot, err := kvstorage.LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("load snapshot err: %v", err)
+	}
+
+	if !reflect.DeepEqual(snapshot.Items, memoryStorage) {
+		t.Errorf("want: %v, got: %v", memoryStorage, snapshot.Items)
+	}
+
+	if snapshot.Revision != 3 {
+		t.Errorf("want: revision 3, got: %d", snapshot.Revision)
+	}
+
+	storage = kvstorage.New(kvstorage.WithSnapshot(snapshot))
+
+	item, err := storage.Set("key", "value", 0)
+	if err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if item.Version != 4 {
+		t.Errorf("revision should continue after restore, want: 4, got: %d", item.Version)
+	}
+}
+
+func TestSnapshotTruncatesAOF(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kvstore.aof")
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncAlways)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage := kvstorage.New(
+		kvstorage.WithAOF(aof),
+		kvstorage.WithSnapshotDir(dir),
+	)
+	if _, err = storage.Set("key", "value", 0); err != nil {
+		t.Errorf("set err: %v", err)
+	}
+
+	if err = storage.Snapshot(); err != nil {
+		t.Errorf("snapshot err: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat err: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("aof should be truncated, got size: %d", info.Size())
+	}
+}
+
+func TestLoadSnapshotCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{"key": {Value: "value"}}),
+		kvstorage.WithSnapshotDir(dir),
+	)
+
+	if err := storage.Snapshot(); err != nil {
+		t.Fatalf("snapshot err: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "snapshot-*.kvs"))
+	if len(files) != 1 {
+		t.Fatalf("want: 1 snapshot, got: %d", len(files))
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read file err: %v", err)
+	}
+
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)-6] ^= 0xff
+	if err = os.WriteFile(files[0], corrupted, 0o600); err != nil {
+		t.Fatalf("write file err: %v", err)
+	}
+
+	if _, err = kvstorage.LoadSnapshot(dir); err == nil {
+		t.Error("corrupted snapshot should be refused")
+	}
+
+	if err = os.WriteFile(files[0], data[:len(data)-10], 0o600); err != nil {
+		t.Fatalf("write file err: %v", err)
+	}
+
+	if _, err = kvstorage.LoadSnapshot(dir); err == nil {
+		t.Error("truncated snapshot should be refused")
+	}
+}
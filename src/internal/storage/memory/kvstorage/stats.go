@@ -0,0 +1,33 @@
+package kvstorage
+
+import (
+	"sort"
+)
+
+// NamespaceStats represents size of a namespace. Expired items which are
+// not removed yet are included.
+type NamespaceStats struct {
+	Name    string
+	Keys    int
+	Expires int   // keys with ttl
+	Bytes   int64 // approximate size of keys and values
+}
+
+// Stats returns stats of all namespaces in name order.
+func (ms *memoryStorage) Stats() []NamespaceStats {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	stats := make([]NamespaceStats, 0, len(ms.namespaces))
+	for name, ks := range ms.namespaces {
+		stats = append(stats, NamespaceStats{
+			Name:    name,
+			Keys:    len(ks.items),
+			Expires: ks.expires,
+			Bytes:   ks.bytes,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+
+	return stats
+}
@@ -0,0 +1,47 @@
+package kvstorage_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestStats(t *testing.T) {
+	storage := kvstorage.New(kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+		"seed": {Value: "abc"},
+	}))
+
+	if err := storage.CreateNamespace("team"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+	team := storage.Namespace("team")
+
+	if _, err := storage.Set("key", map[string]any{"ab": []any{"cd", float64(1), true, nil}}, 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err := team.Set("a", "value", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err := team.Update("a", "v", 0, 0); err != nil {
+		t.Fatalf("update err: %v", err)
+	}
+	if _, err := team.Set("c", "v", time.Hour); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err := team.Set("b", "value", time.Hour); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if err := team.Delete("b", 0); err != nil {
+		t.Fatalf("delete err: %v", err)
+	}
+
+	want := []kvstorage.NamespaceStats{
+		{Name: "default", Keys: 2, Bytes: 4 + 3 + 3 + 2 + 2 + 8 + 1}, // seed, abc, key, ab, cd, 1, true
+		{Name: "team", Keys: 2, Expires: 1, Bytes: 2 + 2},
+	}
+	if got := storage.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong stats, want: %+v, got: %+v", want, got)
+	}
+}
@@ -0,0 +1,282 @@
+package kvstorage
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
+
+// MaxTxnOps is the maximum number of compares or operations in a transaction.
+const MaxTxnOps = 128
+
+// CompareTarget represents which field of item is compared.
+type CompareTarget string
+
+// compare targets.
+const (
+	CompareVersion CompareTarget = "version" // zero version means key does not exist
+	CompareValue   CompareTarget = "value"
+)
+
+// CompareResult represents compare operator.
+type CompareResult string
+
+// compare operators.
+const (
+	CompareEqual    CompareResult = "="
+	CompareNotEqual CompareResult = "!="
+	CompareGreater  CompareResult = ">"
+	CompareLess     CompareResult = "<"
+)
+
+// TxnOpType represents transaction operation type.
+type TxnOpType string
+
+// transaction operation types.
+const (
+	TxnOpGet    TxnOpType = "get"
+	TxnOpPut    TxnOpType = "put" // creates or replaces
+	TxnOpDelete TxnOpType = "delete"
+)
+
+// TxnCompare is a condition of transaction.
+type TxnCompare struct {
+	Key     string
+	Target  CompareTarget
+	Result  CompareResult
+	Version uint64
+	Value   any
+}
+
+// TxnOp is an operation of transaction.
+type TxnOp struct {
+	Type  TxnOpType
+	Key   string
+	Value any
+	TTL   time.Duration
+}
+
+// TxnOpResult is the result of transaction operation. Item is nil for
+// delete and for get of missing key.
+type TxnOpResult struct {
+	Type    TxnOpType
+	Key     string
+	Item    *Item
+	Deleted bool
+}
+
+// TxnResponse is the result of transaction.
+type TxnResponse struct {
+	Succeeded bool
+	Revision  uint64
+	Results   []TxnOpResult
+}
+
+// Txn evaluates compares and applies success operations if all of them hold,
+// failure operations otherwise. Everything happens under the storage lock
+// and mutations share single revision, either all of them are applied or
+// none.
+func (ms *memoryStorage) Txn(compares []TxnCompare, success, failure []TxnOp) (*TxnResponse, error) {
+	if err := validateTxn(compares, success, failure); err != nil {
+		return nil, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if err := ms.checkNamespace("txn"); err != nil {
+		return nil, err
+	}
+
+	response := &TxnResponse{Succeeded: true}
+	for _, c := range compares {
+		item, _ := ms.lookup(c.Key)
+		if !evaluateCompare(c, item) {
+			response.Succeeded = false
+			break
+		}
+	}
+
+	ops := success
+	if !response.Succeeded {
+		ops = failure
+	}
+
+	txn := aofRecord{Op: aofOpTxn}
+	pending := make(map[string]*Item) // nil means deleted within transaction
+	lookup := func(key string) *Item {
+		if item, ok := pending[key]; ok {
+			return item
+		}
+		item, _ := ms.lookup(key)
+		return item
+	}
+
+	response.Results = make([]TxnOpResult, len(ops))
+	for i, op := range ops {
+		result := TxnOpResult{Type: op.Type, Key: op.Key}
+
+		switch op.Type {
+		case TxnOpGet:
+			if item := lookup(op.Key); item != nil {
+				result.Item = item.clone()
+			}
+		case TxnOpPut:
+			record := aofRecord{Op: aofOpSet, Key: op.Key, Value: op.Value, ExpiresAt: expiresAt(op.TTL)}
+			txn.Records = append(txn.Records, record)
+			pending[op.Key] = &Item{Value: record.Value, ExpiresAt: record.ExpiresAt}
+			result.Item = pending[op.Key].clone()
+		case TxnOpDelete:
+			if lookup(op.Key) != nil {
+				txn.Records = append(txn.Records, aofRecord{Op: aofOpDelete, Key: op.Key})
+				pending[op.Key] = nil
+				result.Deleted = true
+			}
+		}
+
+		response.Results[i] = result
+	}
+
+	if len(txn.Records) == 0 {
+		response.Revision = ms.revision
+		return response, nil
+	}
+
+	if _, err := ms.commit(txn); err != nil {
+		return nil, err
+	}
+
+	response.Revision = ms.revision
+	for _, result := range response.Results {
+		if result.Item != nil && result.Item.Version == 0 { // written within this transaction
+			result.Item.Version = ms.revision
+		}
+	}
+
+	return response, nil
+}
+
+func validateTxn(compares []TxnCompare, success, failure []TxnOp) error {
+	if len(compares) > MaxTxnOps || len(success) > MaxTxnOps || len(failure) > MaxTxnOps {
+		return kverror.New(kverror.ErrInvalidTxn, "txn", "", fmt.Sprintf("too many compares or operations, max: %d", MaxTxnOps))
+	}
+
+	for _, c := range compares {
+		if c.Key == "" {
+			return kverror.New(kverror.ErrInvalidTxn, "txn", "", "compare key is empty")
+		}
+
+		switch c.Target {
+		case CompareVersion, CompareValue:
+		default:
+			return kverror.New(kverror.ErrInvalidTxn, "txn", "", "unknown compare target '"+string(c.Target)+"'")
+		}
+
+		switch c.Result {
+		case CompareEqual, CompareNotEqual, CompareGreater, CompareLess:
+		default:
+			return kverror.New(kverror.ErrInvalidTxn, "txn", "", "unknown compare result '"+string(c.Result)+"'")
+		}
+	}
+
+	for _, ops := range [][]TxnOp{success, failure} {
+		for _, op := range ops {
+			if op.Key == "" {
+				return kverror.New(kverror.ErrInvalidTxn, "txn", "", "operation key is empty")
+			}
+
+			switch op.Type {
+			case TxnOpGet, TxnOpDelete:
+			case TxnOpPut:
+				if op.Value == nil {
+					return kverror.New(kverror.ErrInvalidTxn, "txn", "", "put value of '"+op.Key+"' is empty")
+				}
+			default:
+				return kverror.New(kverror.ErrInvalidTxn, "txn", "", "unknown operation type '"+string(op.Type)+"'")
+			}
+		}
+	}
+
+	return nil
+}
+
+// evaluateCompare evaluates compare against item, item is nil if key does
+// not exist.
+func evaluateCompare(c TxnCompare, item *Item) bool {
+	var cmp int
+
+	switch c.Target {
+	case CompareVersion:
+		var version uint64
+		if item != nil {
+			version = item.Version
+		}
+
+		switch {
+		case version < c.Version:
+			cmp = -1
+		case version > c.Version:
+			cmp = 1
+		}
+	case CompareValue:
+		if item == nil {
+			return false
+		}
+
+		if c.Result == CompareEqual || c.Result == CompareNotEqual {
+			return reflect.DeepEqual(item.Value, c.Value) == (c.Result == CompareEqual)
+		}
+
+		var ok bool
+		if cmp, ok = orderValues(item.Value, c.Value); !ok {
+			return false
+		}
+	}
+
+	switch c.Result {
+	case CompareEqual:
+		return cmp == 0
+	case CompareNotEqual:
+		return cmp != 0
+	case CompareGreater:
+		return cmp > 0
+	case CompareLess:
+		return cmp < 0
+	}
+	return false
+}
+
+// orderValues compares values, ordering is supported for numbers and strings
+// only, ok is false if values are not orderable.
+func orderValues(a, b any) (cmp int, ok bool) {
+	switch av := a.(type) {
+	case float64:
+		bv, isFloat := b.(float64)
+		if !isFloat {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, isString := b.(string)
+		if !isString {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,155 @@
+package kvstorage_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func TestTxnInvalid(t *testing.T) {
+	storage := kvstorage.New()
+
+	_, err := storage.Txn(nil, []kvstorage.TxnOp{{Type: "rename", Key: "key"}}, nil)
+	if !errors.Is(err, kverror.ErrInvalidTxn) {
+		t.Errorf("want: %v, got: %v", kverror.ErrInvalidTxn, err)
+	}
+}
+
+func TestTxnSuccess(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"stock":         {Value: float64(10), Version: 3},
+			"reservation:1": {Value: "user:1", Version: 4},
+		}),
+	)
+
+	response, err := storage.Txn(
+		[]kvstorage.TxnCompare{
+			{Key: "stock", Target: kvstorage.CompareVersion, Result: kvstorage.CompareEqual, Version: 3},
+			{Key: "stock", Target: kvstorage.CompareValue, Result: kvstorage.CompareGreater, Value: float64(0)},
+			{Key: "order:1", Target: kvstorage.CompareVersion, Result: kvstorage.CompareEqual, Version: 0},
+		},
+		[]kvstorage.TxnOp{
+			{Type: kvstorage.TxnOpPut, Key: "stock", Value: float64(9)},
+			{Type: kvstorage.TxnOpDelete, Key: "reservation:1"},
+			{Type: kvstorage.TxnOpPut, Key: "order:1", Value: "user:1"},
+			{Type: kvstorage.TxnOpGet, Key: "stock"},
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("txn err: %v", err)
+	}
+
+	if !response.Succeeded {
+		t.Fatal("txn should succeed")
+	}
+
+	if response.Revision != 5 {
+		t.Errorf("want: revision 5, got: %d", response.Revision)
+	}
+
+	if !response.Results[1].Deleted {
+		t.Error("reservation should be deleted")
+	}
+
+	if get := response.Results[3].Item; get == nil || get.Value != float64(9) || get.Version != 5 {
+		t.Errorf("get should see put of the same transaction, got: %+v", get)
+	}
+
+	if _, err = storage.Get("reservation:1"); err == nil {
+		t.Error("reservation should be deleted")
+	}
+
+	item, err := storage.Get("order:1")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Version != 5 {
+		t.Errorf("want: version 5, got: %d", item.Version)
+	}
+}
+
+func TestTxnFailure(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"stock": {Value: float64(0), Version: 3},
+		}),
+	)
+
+	response, err := storage.Txn(
+		[]kvstorage.TxnCompare{
+			{Key: "stock", Target: kvstorage.CompareValue, Result: kvstorage.CompareGreater, Value: float64(0)},
+		},
+		[]kvstorage.TxnOp{
+			{Type: kvstorage.TxnOpPut, Key: "stock", Value: float64(-1)},
+		},
+		[]kvstorage.TxnOp{
+			{Type: kvstorage.TxnOpGet, Key: "stock"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("txn err: %v", err)
+	}
+
+	if response.Succeeded {
+		t.Error("txn should fail")
+	}
+
+	if response.Results[0].Item.Value != float64(0) {
+		t.Errorf("want: 0, got: %v", response.Results[0].Item.Value)
+	}
+
+	if response.Revision != 3 {
+		t.Errorf("revision should not change, want: 3, got: %d", response.Revision)
+	}
+}
+
+func TestTxnAOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof))
+	if _, err = storage.Set("a", "1", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	_, err = storage.Txn(nil, []kvstorage.TxnOp{
+		{Type: kvstorage.TxnOpDelete, Key: "a"},
+		{Type: kvstorage.TxnOpPut, Key: "b", Value: "2"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("txn err: %v", err)
+	}
+
+	if err = aof.Close(); err != nil {
+		t.Fatalf("close aof err: %v", err)
+	}
+
+	aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("reopen aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage = kvstorage.New(kvstorage.WithAOF(aof))
+
+	if _, err = storage.Get("a"); err == nil {
+		t.Error("deleted key should not be replayed")
+	}
+
+	item, err := storage.Get("b")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Version != 2 {
+		t.Errorf("want: version 2, got: %d", item.Version)
+	}
+}
@@ -1,13 +1,43 @@
 package kvstorage
 
-func (ms *memoryStorage) Update(key string, value any) (any, error) {
-	if _, err := ms.Get(key); err != nil { // can not update! key doesn't exist
-		return nil, err
-	}
+import (
+	"fmt"
+	"time"
 
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
+
+func (ms *memoryStorage) Update(key string, value any, ttl time.Duration, version uint64) (*Item, error) {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	ms.db[key] = value
-	return value, nil
+	if err := ms.checkNamespace("update"); err != nil {
+		return nil, err
+	}
+
+	item, ok := ms.lookup(key)
+	if !ok { // can not update! key doesn't exist
+		return nil, kverror.New(kverror.ErrKeyNotFound, "update", key, "'"+key+"' does not exist")
+	}
+
+	if err := checkVersion("update", key, item, version); err != nil {
+		return nil, err
+	}
+
+	return ms.commit(aofRecord{Op: aofOpSet, Key: key, Value: value, ExpiresAt: expiresAt(ttl)})
+}
+
+// checkVersion checks expected version against item for op, zero version
+// matches any.
+func checkVersion(op, key string, item *Item, version uint64) error {
+	if version == 0 || item.Version == version {
+		return nil
+	}
+
+	return kverror.New(
+		kverror.ErrVersionMismatch,
+		op,
+		key,
+		fmt.Sprintf("'%s' expected version %d, current version %d", key, version, item.Version),
+	)
 }
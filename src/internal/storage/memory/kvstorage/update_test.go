@@ -1,34 +1,58 @@
 package kvstorage_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
 )
 
 func TestUpdateEmpty(t *testing.T) {
 	storage := kvstorage.New()
 
-	if _, err := storage.Update("key", "value"); err == nil {
+	if _, err := storage.Update("key", "value", 0, 0); err == nil {
 		t.Error("error not occurred")
 	}
 }
 
+func TestUpdateVersionMismatch(t *testing.T) {
+	storage := kvstorage.New()
+
+	item, err := storage.Set("key", "value", 0)
+	if err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	if _, err = storage.Update("key", "value2", 0, item.Version+1); !errors.Is(err, kverror.ErrVersionMismatch) {
+		t.Errorf("want: %v, got: %v", kverror.ErrVersionMismatch, err)
+	}
+
+	updated, err := storage.Update("key", "value2", 0, item.Version)
+	if err != nil {
+		t.Fatalf("update err: %v", err)
+	}
+
+	if updated.Version <= item.Version {
+		t.Errorf("version should increase, previous: %d, got: %d", item.Version, updated.Version)
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	key := "key"
-	memoryStorage := map[string]any{
-		key: "value",
+	memoryStorage := kvstorage.MemoryDB{
+		key: {Value: "value"},
 	}
 	storage := kvstorage.New(
 		kvstorage.WithMemoryDB(memoryStorage),
 	)
 
-	value, err := storage.Update(key, "value2")
+	item, err := storage.Update(key, "value2", 0, 0)
 	if err != nil {
-		t.Error("error occurred")
+		t.Fatal("error occurred")
 	}
 
-	if value != "value2" {
+	if item.Value != "value2" {
 		t.Error("value not equal")
 	}
 }
@@ -0,0 +1,78 @@
+package kvstorage
+
+import (
+	"encoding/json"
+)
+
+// Binary values ([]byte, e.g. set over Redis or memcached protocols) are
+// persisted base64 encoded in "bytes" field instead of "value", json would
+// turn them into strings and replace invalid utf-8 with U+FFFD.
+
+var (
+	_ json.Marshaler   = Item{}            // compile time proof
+	_ json.Unmarshaler = (*Item)(nil)      // compile time proof
+	_ json.Marshaler   = aofRecord{}       // compile time proof
+	_ json.Unmarshaler = (*aofRecord)(nil) // compile time proof
+)
+
+type jsonItem Item // without methods
+
+type binaryItem struct {
+	jsonItem
+	Bytes *[]byte `json:"bytes,omitempty"`
+}
+
+// MarshalJSON encodes item, binary value is encoded in bytes field.
+func (i Item) MarshalJSON() ([]byte, error) {
+	b, ok := i.Value.([]byte)
+	if !ok {
+		return json.Marshal(jsonItem(i))
+	}
+
+	i.Value = nil
+	return json.Marshal(binaryItem{jsonItem: jsonItem(i), Bytes: &b})
+}
+
+// UnmarshalJSON decodes item, binary value is decoded from bytes field.
+func (i *Item) UnmarshalJSON(data []byte) error {
+	var bi binaryItem
+	if err := json.Unmarshal(data, &bi); err != nil {
+		return err
+	}
+
+	*i = Item(bi.jsonItem)
+	if bi.Bytes != nil {
+		i.Value = *bi.Bytes
+	}
+	return nil
+}
+
+type jsonAOFRecord aofRecord // without methods
+
+type binaryAOFRecord struct {
+	jsonAOFRecord
+	Bytes *[]byte `json:"bytes,omitempty"`
+}
+
+func (r aofRecord) MarshalJSON() ([]byte, error) {
+	b, ok := r.Value.([]byte)
+	if !ok {
+		return json.Marshal(jsonAOFRecord(r))
+	}
+
+	r.Value = nil
+	return json.Marshal(binaryAOFRecord{jsonAOFRecord: jsonAOFRecord(r), Bytes: &b})
+}
+
+func (r *aofRecord) UnmarshalJSON(data []byte) error {
+	var br binaryAOFRecord
+	if err := json.Unmarshal(data, &br); err != nil {
+		return err
+	}
+
+	*r = aofRecord(br.jsonAOFRecord)
+	if br.Bytes != nil {
+		r.Value = *br.Bytes
+	}
+	return nil
+}
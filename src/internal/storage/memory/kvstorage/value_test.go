@@ -0,0 +1,88 @@
+package kvstorage_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+var binaryValue = []byte("\xff\xfe\x00a")
+
+func assertBinaryValue(t *testing.T, storage kvstorage.Storer, key string) {
+	t.Helper()
+
+	item, err := storage.Get(key)
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	value, ok := item.Value.([]byte)
+	if !ok {
+		t.Fatalf("wrong value type, want: []byte, got: %T", item.Value)
+	}
+	if !bytes.Equal(value, binaryValue) {
+		t.Errorf("wrong value, want: %q, got: %q", binaryValue, value)
+	}
+}
+
+func TestBinaryValueAOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncAlways)
+	if err != nil {
+		t.Fatalf("open aof err: %v", err)
+	}
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof))
+	if _, err = storage.Set("key", binaryValue, 0); err != nil {
+		t.Errorf("set err: %v", err)
+	}
+	if _, err = storage.Txn(nil, []kvstorage.TxnOp{{Type: kvstorage.TxnOpPut, Key: "other", Value: binaryValue}}, nil); err != nil {
+		t.Errorf("txn err: %v", err)
+	}
+	if err = aof.Close(); err != nil {
+		t.Errorf("close aof err: %v", err)
+	}
+
+	aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever)
+	if err != nil {
+		t.Fatalf("reopen aof err: %v", err)
+	}
+	defer func() { _ = aof.Close() }()
+
+	storage = kvstorage.New(kvstorage.WithAOF(aof))
+	assertBinaryValue(t, storage, "key")
+	assertBinaryValue(t, storage, "other")
+}
+
+func TestBinaryValueSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	storage := kvstorage.New(kvstorage.WithSnapshotDir(dir))
+	if _, err := storage.Set("key", binaryValue, 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err := storage.Set("text", "value", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if err := storage.Snapshot(); err != nil {
+		t.Fatalf("snapshot err: %v", err)
+	}
+
+	snapshot, err := kvstorage.LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("load snapshot err: %v", err)
+	}
+
+	storage = kvstorage.New(kvstorage.WithSnapshot(snapshot))
+	assertBinaryValue(t, storage, "key")
+
+	item, err := storage.Get("text")
+	if err != nil {
+		t.Fatalf("get err: %v", err)
+	}
+	if item.Value != "value" {
+		t.Errorf("wrong value, want: %s, got: %v", "value", item.Value)
+	}
+}
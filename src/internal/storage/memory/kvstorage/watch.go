@@ -0,0 +1,279 @@
+package kvstorage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
+
+// WatchHistorySize is the number of recent events kept for resuming watches.
+const WatchHistorySize = 4096
+
+// watcherBufferSize is the number of events buffered per watcher on top of
+// the replayed history, slow watchers are closed when it is full.
+const watcherBufferSize = 256
+
+// EventType represents change type.
+type EventType string
+
+// event types.
+const (
+	EventPut           EventType = "put"
+	EventDelete        EventType = "delete"
+	EventExpire        EventType = "expire"
+	EventDropNamespace EventType = "drop_namespace" // key is empty
+)
+
+// Event represents a change in storage.
+type Event struct {
+	Type      EventType
+	Namespace string
+	Key       string
+	Revision  uint64
+	Item      *Item // new item, put only
+	PrevItem  *Item // nil if key did not exist
+}
+
+// WatchOptions selects events of Watch. Key watches single key, Prefix
+// watches keys starting with it (empty prefix watches all keys).
+// Revision is the first revision to receive, zero means only new events.
+type WatchOptions struct {
+	Key      string
+	Prefix   string
+	Revision uint64
+}
+
+// Watcher delivers events of bound namespace in revision order. Events
+// channel is closed by Close or when the watcher can not keep up, resume
+// from the last received revision + 1 in that case.
+type Watcher interface {
+	Events() <-chan Event
+	Close()
+}
+
+type watcher struct {
+	hub       *watchHub
+	events    chan Event
+	namespace string
+	key       string
+	prefix    string
+}
+
+func (w *watcher) Events() <-chan Event {
+	return w.events
+}
+
+func (w *watcher) Close() {
+	w.hub.mu.Lock()
+	defer w.hub.mu.Unlock()
+
+	w.hub.remove(w)
+}
+
+func (w *watcher) matches(event Event) bool {
+	if event.Namespace != w.namespace {
+		return false
+	}
+
+	switch {
+	case event.Type == EventDropNamespace:
+		return true
+	case w.key != "":
+		return event.Key == w.key
+	default:
+		return strings.HasPrefix(event.Key, w.prefix)
+	}
+}
+
+// watchHub keeps watchers and recent events. Events are published under
+// the storage write lock, registration happens under the read lock so
+// history and live events never overlap.
+type watchHub struct {
+	mu       sync.Mutex // guarding watchers and history
+	watchers map[*watcher]struct{}
+	history  []Event
+	since    uint64 // every event with revision >= since is in history
+}
+
+func newWatchHub() *watchHub {
+	return &watchHub{
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// remove unregisters and closes watcher, caller must hold hub lock.
+func (h *watchHub) remove(w *watcher) {
+	if _, ok := h.watchers[w]; ok {
+		delete(h.watchers, w)
+		close(w.events)
+	}
+}
+
+func (h *watchHub) publish(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.history = append(h.history, events...)
+	if len(h.history) > WatchHistorySize {
+		trimmed := h.history[len(h.history)-WatchHistorySize-1]
+		h.since = trimmed.Revision + 1
+
+		// keep whole revisions only.
+		i := len(h.history) - WatchHistorySize
+		for i < len(h.history) && h.history[i].Revision < h.since {
+			i++
+		}
+		h.history = append([]Event(nil), h.history[i:]...)
+	}
+
+	for w := range h.watchers {
+		for _, event := range events {
+			if !w.matches(event) {
+				continue
+			}
+
+			select {
+			case w.events <- event:
+			default: // can not keep up
+				h.remove(w)
+			}
+
+			if _, ok := h.watchers[w]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// Watch watches changes of bound namespace. kverror.ErrRevisionCompacted is
+// returned if requested revision is no longer in history.
+func (ms *memoryStorage) Watch(opts WatchOptions) (Watcher, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	if err := ms.checkNamespace("watch"); err != nil {
+		return nil, err
+	}
+
+	hub := ms.watch
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	var backlog []Event
+
+	w := &watcher{
+		hub:       hub,
+		namespace: ms.namespace,
+		key:       opts.Key,
+		prefix:    opts.Prefix,
+	}
+
+	if opts.Revision > 0 {
+		if opts.Revision < hub.since {
+			return nil, kverror.New(
+				kverror.ErrRevisionCompacted,
+				"watch",
+				"",
+				fmt.Sprintf("requested revision %d, oldest available revision %d", opts.Revision, hub.since),
+			)
+		}
+
+		for _, event := range hub.history {
+			if event.Revision >= opts.Revision && w.matches(event) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	w.events = make(chan Event, len(backlog)+watcherBufferSize)
+	for _, event := range backlog {
+		w.events <- event
+	}
+
+	hub.watchers[w] = struct{}{}
+	return w, nil
+}
+
+// events builds events of given record before it is applied, caller must
+// hold the lock.
+func (ms *memoryStorage) events(record aofRecord) []Event {
+	namespace := record.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	event := Event{
+		Namespace: namespace,
+		Key:       record.Key,
+		Revision:  record.Version,
+	}
+
+	if ks, ok := ms.namespaces[namespace]; ok {
+		// expired item is the subject of expire event.
+		if prev, exists := ks.items[record.Key]; exists && (record.Op == aofOpExpire || !prev.expired(time.Now())) {
+			event.PrevItem = prev.clone()
+		}
+	}
+
+	switch record.Op {
+	case aofOpSet:
+		event.Type = EventPut
+		event.Item = &Item{Value: record.Value, ExpiresAt: record.ExpiresAt, Version: record.Version}
+	case aofOpDelete:
+		event.Type = EventDelete
+	case aofOpExpire:
+		event.Type = EventExpire
+	case aofOpDropNamespace:
+		event.Type = EventDropNamespace
+		event.PrevItem = nil
+	default:
+		return nil
+	}
+
+	return []Event{event}
+}
+
+// applyWithEvents applies record like apply and returns its events, nested
+// records of transaction are applied one by one so every event sees the
+// previous one. Caller must hold the lock.
+func (ms *memoryStorage) applyWithEvents(record aofRecord) []Event {
+	if record.Op != aofOpTxn {
+		events := ms.events(record)
+		ms.apply(record)
+		return events
+	}
+
+	var events []Event
+	for _, nested := range record.Records {
+		nested.Namespace = record.Namespace
+		nested.Version = record.Version
+		events = append(events, ms.applyWithEvents(nested)...)
+	}
+	return events
+}
+
+// expire removes expired item of namespace as a mutation of its own: it
+// bumps revision, is logged to append-only file and notifies watchers, so
+// resumed watches receive it too. Caller must hold the lock.
+func (ms *memoryStorage) expire(namespace string, key string) error {
+	record := aofRecord{Op: aofOpExpire, Key: key, Version: ms.revision + 1}
+	if namespace != DefaultNamespace {
+		record.Namespace = namespace
+	}
+
+	if ms.aof != nil {
+		if err := ms.aof.append(record); err != nil {
+			return err
+		}
+	}
+
+	ms.watch.publish(ms.applyWithEvents(record)...)
+	return nil
+}
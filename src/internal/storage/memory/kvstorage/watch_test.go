@@ -0,0 +1,274 @@
+package kvstorage_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+func receive(t *testing.T, w kvstorage.Watcher) kvstorage.Event {
+	t.Helper()
+
+	select {
+	case event, ok := <-w.Events():
+		if !ok {
+			t.Fatal("events channel is closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+	return kvstorage.Event{}
+}
+
+func TestWatchKey(t *testing.T) {
+	storage := kvstorage.New()
+
+	w, err := storage.Watch(kvstorage.WatchOptions{Key: "config"})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	if _, err = storage.Set("other", "value", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err = storage.Set("config", "v1", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err = storage.Update("config", "v2", 0, 0); err != nil {
+		t.Fatalf("update err: %v", err)
+	}
+	if err = storage.Delete("config", 0); err != nil {
+		t.Fatalf("delete err: %v", err)
+	}
+
+	event := receive(t, w)
+	if event.Type != kvstorage.EventPut || event.Revision != 2 || event.Item.Value != "v1" || event.PrevItem != nil {
+		t.Errorf("wrong put event: %+v", event)
+	}
+
+	event = receive(t, w)
+	if event.Type != kvstorage.EventPut || event.Item.Value != "v2" || event.PrevItem.Value != "v1" {
+		t.Errorf("wrong update event: %+v", event)
+	}
+
+	event = receive(t, w)
+	if event.Type != kvstorage.EventDelete || event.Revision != 4 || event.PrevItem.Value != "v2" {
+		t.Errorf("wrong delete event: %+v", event)
+	}
+}
+
+func TestWatchPrefixTxn(t *testing.T) {
+	storage := kvstorage.New()
+
+	w, err := storage.Watch(kvstorage.WatchOptions{Prefix: "app/"})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	if _, err = storage.Txn(nil, []kvstorage.TxnOp{
+		{Type: kvstorage.TxnOpPut, Key: "app/a", Value: "1"},
+		{Type: kvstorage.TxnOpPut, Key: "app/a", Value: "2"},
+		{Type: kvstorage.TxnOpPut, Key: "other", Value: "3"},
+	}, nil); err != nil {
+		t.Fatalf("txn err: %v", err)
+	}
+
+	first, second := receive(t, w), receive(t, w)
+	if first.Revision != 1 || second.Revision != 1 {
+		t.Errorf("txn events should share revision, got: %d, %d", first.Revision, second.Revision)
+	}
+	if second.PrevItem == nil || second.PrevItem.Value != "1" {
+		t.Errorf("second put should see the first one, got: %+v", second.PrevItem)
+	}
+
+	select {
+	case event := <-w.Events():
+		t.Errorf("unexpected event: %+v", event)
+	default:
+	}
+}
+
+func TestWatchResume(t *testing.T) {
+	storage := kvstorage.New()
+
+	for i := 1; i <= 3; i++ {
+		if _, err := storage.Set(fmt.Sprintf("key%d", i), i, 0); err != nil {
+			t.Fatalf("set err: %v", err)
+		}
+	}
+
+	w, err := storage.Watch(kvstorage.WatchOptions{Revision: 2})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	if _, err = storage.Set("key4", 4, 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+
+	for _, want := range []uint64{2, 3, 4} {
+		if event := receive(t, w); event.Revision != want {
+			t.Errorf("want: revision %d, got: %d", want, event.Revision)
+		}
+	}
+}
+
+func TestWatchCompacted(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{"seed": {Value: "value", Version: 10}}),
+	)
+
+	if _, err := storage.Watch(kvstorage.WatchOptions{Revision: 5}); !errors.Is(err, kverror.ErrRevisionCompacted) {
+		t.Errorf("want: %v, got: %v", kverror.ErrRevisionCompacted, err)
+	}
+
+	for i := 0; i < kvstorage.WatchHistorySize+10; i++ {
+		if _, err := storage.Set(fmt.Sprintf("key%d", i), i, 0); err != nil {
+			t.Fatalf("set err: %v", err)
+		}
+	}
+
+	if _, err := storage.Watch(kvstorage.WatchOptions{Revision: 12}); !errors.Is(err, kverror.ErrRevisionCompacted) {
+		t.Errorf("want: %v, got: %v", kverror.ErrRevisionCompacted, err)
+	}
+
+	w, err := storage.Watch(kvstorage.WatchOptions{Revision: 30})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	if event := receive(t, w); event.Revision != 30 {
+		t.Errorf("want: revision 30, got: %d", event.Revision)
+	}
+}
+
+func TestWatchSlowWatcherClosed(t *testing.T) {
+	storage := kvstorage.New()
+
+	w, err := storage.Watch(kvstorage.WatchOptions{})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 1000; i++ {
+		if _, err = storage.Set(fmt.Sprintf("key%d", i), i, 0); err != nil {
+			t.Fatalf("set err: %v", err)
+		}
+	}
+
+	var received int
+	for range w.Events() {
+		received++
+	}
+
+	if received == 0 || received >= 1000 {
+		t.Errorf("slow watcher should be closed after buffer is full, received: %d", received)
+	}
+}
+
+func TestWatchNamespace(t *testing.T) {
+	storage := kvstorage.New()
+	if err := storage.CreateNamespace("team-a"); err != nil {
+		t.Fatalf("create namespace err: %v", err)
+	}
+	teamA := storage.Namespace("team-a")
+
+	w, err := teamA.Watch(kvstorage.WatchOptions{})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	if _, err = storage.Set("key", "default", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err = teamA.Set("key", "team-a", 0); err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if err = storage.DropNamespace("team-a"); err != nil {
+		t.Fatalf("drop namespace err: %v", err)
+	}
+
+	if event := receive(t, w); event.Namespace != "team-a" || event.Item.Value != "team-a" {
+		t.Errorf("wrong event: %+v", event)
+	}
+
+	if event := receive(t, w); event.Type != kvstorage.EventDropNamespace {
+		t.Errorf("want: %s, got: %s", kvstorage.EventDropNamespace, event.Type)
+	}
+
+	if _, err = storage.Namespace("missing").Watch(kvstorage.WatchOptions{}); !errors.Is(err, kverror.ErrNamespaceNotFound) {
+		t.Errorf("want: %v, got: %v", kverror.ErrNamespaceNotFound, err)
+	}
+}
+
+func TestWatchExpire(t *testing.T) {
+	storage := kvstorage.New(
+		kvstorage.WithMemoryDB(kvstorage.MemoryDB{
+			"session": {Value: "token", ExpiresAt: time.Now().Add(-time.Second)},
+		}),
+	)
+
+	w, err := storage.Watch(kvstorage.WatchOptions{Key: "session"})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	if removed := storage.DeleteExpired(); removed != 1 {
+		t.Fatalf("want: 1 removed, got: %d", removed)
+	}
+
+	if event := receive(t, w); event.Type != kvstorage.EventExpire || event.PrevItem.Value != "token" {
+		t.Errorf("wrong event: %+v", event)
+	}
+}
+
+func TestWatchResumeAcrossExpire(t *testing.T) {
+	storage := kvstorage.New()
+
+	if _, err := storage.Set("session", "token", 10*time.Millisecond); err != nil { // revision 1
+		t.Fatalf("set err: %v", err)
+	}
+	if _, err := storage.Set("key", "value", 0); err != nil { // revision 2
+		t.Fatalf("set err: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if removed := storage.DeleteExpired(); removed != 1 { // revision 3
+		t.Fatalf("want: 1 removed, got: %d", removed)
+	}
+
+	item, err := storage.Set("other", "value", 0)
+	if err != nil {
+		t.Fatalf("set err: %v", err)
+	}
+	if item.Version != 4 {
+		t.Errorf("expire should bump revision, want: version 4, got: %d", item.Version)
+	}
+
+	// resuming after the last received event (revision 2) delivers expire.
+	w, err := storage.Watch(kvstorage.WatchOptions{Revision: 3})
+	if err != nil {
+		t.Fatalf("watch err: %v", err)
+	}
+	defer w.Close()
+
+	event := receive(t, w)
+	if event.Type != kvstorage.EventExpire || event.Revision != 3 || event.Key != "session" {
+		t.Errorf("wrong event: %+v", event)
+	}
+	if event = receive(t, w); event.Type != kvstorage.EventPut || event.Revision != 4 {
+		t.Errorf("wrong event: %+v", event)
+	}
+}
@@ -0,0 +1,93 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves certificate of files and reloads it when modification
+// time of either file changes. Files are checked at most once per interval,
+// during handshakes. A failed reload (e.g. key is not written yet) keeps
+// the current certificate and is retried on the next check.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   *slog.Logger
+
+	mu        sync.Mutex // guarding fields below
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	checkedAt time.Time
+}
+
+// NewReloader instantiates new reloader, certificate is loaded now. Nil
+// logger defaults to slog.Default().
+func NewReloader(certFile, keyFile string, interval time.Duration, logger *slog.Logger) (*Reloader, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   logger,
+	}
+
+	if err := r.load(); err != nil {
+		return nil, fmt.Errorf("tlsconfig.NewReloader err: %w", err)
+	}
+	return r, nil
+}
+
+// GetCertificate returns current certificate, see tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= r.interval {
+		r.checkedAt = time.Now()
+
+		if err := r.load(); err != nil {
+			r.logger.Error("tls certificate reload", "cert_file", r.certFile, "err", err)
+		}
+	}
+
+	return r.cert, nil
+}
+
+// load loads certificate if files are changed since last load.
+func (r *Reloader) load() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return fmt.Errorf("os.Stat err: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return fmt.Errorf("os.Stat err: %w", err)
+	}
+
+	if r.cert != nil && certInfo.ModTime().Equal(r.certMod) && keyInfo.ModTime().Equal(r.keyMod) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls.LoadX509KeyPair err: %w", err)
+	}
+
+	if r.cert != nil {
+		r.logger.Info("tls certificate reloaded", "cert_file", r.certFile)
+	}
+	r.cert = &cert
+	r.certMod = certInfo.ModTime()
+	r.keyMod = keyInfo.ModTime()
+
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package tlsconfig builds server tls configuration: certificates reloaded
+// on rotation, minimum tls version and client certificate verification.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// DefaultReloadInterval is the default minimum interval between checks of
+// certificate files.
+const DefaultReloadInterval = 10 * time.Second
+
+// client certificate modes.
+const (
+	ClientAuthRequire  = "require"  // handshake fails without a verified client certificate
+	ClientAuthOptional = "optional" // client certificate is verified if given
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+type config struct {
+	certFile       string
+	keyFile        string
+	minVersion     string
+	clientCAFile   string
+	clientAuth     string
+	reloadInterval time.Duration
+	logger         *slog.Logger
+}
+
+// Option represents tls config option type.
+type Option func(*config)
+
+// WithCertFiles sets certificate (chain) and private key pem files option.
+func WithCertFiles(certFile, keyFile string) Option {
+	return func(c *config) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithMinVersion sets minimum tls version option, one of "1.0", "1.1",
+// "1.2" or "1.3", defaults to "1.2".
+func WithMinVersion(version string) Option {
+	return func(c *config) {
+		c.minVersion = version
+	}
+}
+
+// WithClientCAFile sets ca bundle pem file option, client certificates are
+// verified against it.
+func WithClientCAFile(path string) Option {
+	return func(c *config) {
+		c.clientCAFile = path
+	}
+}
+
+// WithClientAuth sets client certificate mode option, ClientAuthRequire
+// (default) or ClientAuthOptional, only used with WithClientCAFile.
+func WithClientAuth(mode string) Option {
+	return func(c *config) {
+		c.clientAuth = mode
+	}
+}
+
+// WithReloadInterval sets minimum interval between checks of certificate
+// files option, defaults to DefaultReloadInterval.
+func WithReloadInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.reloadInterval = d
+	}
+}
+
+// WithLogger sets logger option, certificate reloads are logged, defaults
+// to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// New returns server tls config. Certificate files are loaded now and
+// reloaded during handshakes when they change, so rotated certificates are
+// served without a restart.
+func New(options ...Option) (*tls.Config, error) {
+	c := &config{
+		minVersion:     "1.2",
+		clientAuth:     ClientAuthRequire,
+		reloadInterval: DefaultReloadInterval,
+	}
+
+	for _, o := range options {
+		o(c)
+	}
+
+	if c.certFile == "" || c.keyFile == "" {
+		return nil, errors.New("tlsconfig.New certificate and key files are required")
+	}
+
+	minVersion, ok := tlsVersions[c.minVersion]
+	if !ok {
+		return nil, fmt.Errorf("tlsconfig.New unknown tls version '%s'", c.minVersion)
+	}
+
+	reloader, err := NewReloader(c.certFile, c.keyFile, c.reloadInterval, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("tlsconfig.New err: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if c.clientCAFile != "" {
+		pool, err := loadCertPool(c.clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tlsconfig.New err: %w", err)
+		}
+		cfg.ClientCAs = pool
+
+		switch c.clientAuth {
+		case ClientAuthRequire:
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		case ClientAuthOptional:
+			cfg.ClientAuth = tls.VerifyClientCertIfGiven
+		default:
+			return nil, fmt.Errorf("tlsconfig.New unknown client auth '%s'", c.clientAuth)
+		}
+	}
+
+	return cfg, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile err: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in '%s'", path)
+	}
+	return pool, nil
+}
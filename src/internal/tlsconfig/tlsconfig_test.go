@@ -0,0 +1,289 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/tlsconfig"
+)
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newCert returns certificate of cn signed by parent, self-signed if parent
+// is nil.
+func newCert(t *testing.T, cn string, isCA bool, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// writeCert writes cert and key files with modification time mod.
+func writeCert(t *testing.T, dir string, c *testCert, mod time.Time) (string, string) {
+	t.Helper()
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+
+	for path, data := range map[string][]byte{certFile: c.certPEM(), keyFile: c.keyPEM(t)} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+	}
+	return certFile, keyFile
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCert(t, dir, newCert(t, "server", false, nil), time.Now())
+
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, newCert(t, "ca", true, nil).certPEM(), 0o600); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	cfg, err := tlsconfig.New(tlsconfig.WithCertFiles(certFile, keyFile), tlsconfig.WithLogger(discardLogger))
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("wrong min version, want: %d, got: %d", tls.VersionTLS12, cfg.MinVersion)
+	}
+	if cfg.ClientAuth != tls.NoClientCert {
+		t.Errorf("wrong client auth, want: %v, got: %v", tls.NoClientCert, cfg.ClientAuth)
+	}
+
+	cfg, err = tlsconfig.New(
+		tlsconfig.WithCertFiles(certFile, keyFile),
+		tlsconfig.WithMinVersion("1.3"),
+		tlsconfig.WithClientCAFile(caFile),
+		tlsconfig.WithClientAuth(tlsconfig.ClientAuthOptional),
+		tlsconfig.WithLogger(discardLogger),
+	)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("wrong min version, want: %d, got: %d", tls.VersionTLS13, cfg.MinVersion)
+	}
+	if cfg.ClientAuth != tls.VerifyClientCertIfGiven {
+		t.Errorf("wrong client auth, want: %v, got: %v", tls.VerifyClientCertIfGiven, cfg.ClientAuth)
+	}
+
+	tcs := []struct {
+		name    string
+		options []tlsconfig.Option
+	}{
+		{"no files", nil},
+		{"missing files", []tlsconfig.Option{tlsconfig.WithCertFiles(filepath.Join(dir, "missing.pem"), keyFile)}},
+		{"unknown version", []tlsconfig.Option{tlsconfig.WithCertFiles(certFile, keyFile), tlsconfig.WithMinVersion("2.0")}},
+		{"invalid ca", []tlsconfig.Option{tlsconfig.WithCertFiles(certFile, keyFile), tlsconfig.WithClientCAFile(keyFile)}},
+		{
+			"unknown client auth",
+			[]tlsconfig.Option{
+				tlsconfig.WithCertFiles(certFile, keyFile),
+				tlsconfig.WithClientCAFile(caFile),
+				tlsconfig.WithClientAuth("sometimes"),
+			},
+		},
+	}
+	for _, tc := range tcs {
+		if _, err = tlsconfig.New(tc.options...); err == nil {
+			t.Errorf("%s: error expected", tc.name)
+		}
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	mod := time.Now().Add(-time.Minute)
+
+	first := newCert(t, "first", false, nil)
+	certFile, keyFile := writeCert(t, dir, first, mod)
+
+	r, err := tlsconfig.NewReloader(certFile, keyFile, 0, discardLogger)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if cert.Leaf.Subject.CommonName != "first" {
+		t.Errorf("wrong certificate, want: %s, got: %s", "first", cert.Leaf.Subject.CommonName)
+	}
+
+	second := newCert(t, "second", false, nil)
+	writeCert(t, dir, second, mod.Add(time.Second))
+
+	if cert, err = r.GetCertificate(nil); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if cert.Leaf.Subject.CommonName != "second" {
+		t.Errorf("wrong certificate, want: %s, got: %s", "second", cert.Leaf.Subject.CommonName)
+	}
+
+	// half written rotation keeps serving current certificate
+	if err = os.WriteFile(certFile, first.certPEM(), 0o600); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if cert, err = r.GetCertificate(nil); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if cert.Leaf.Subject.CommonName != "second" {
+		t.Errorf("wrong certificate, want: %s, got: %s", "second", cert.Leaf.Subject.CommonName)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newCert(t, "ca", true, nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, ca.certPEM(), 0o600); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	certFile, keyFile := writeCert(t, dir, newCert(t, "server", false, ca), time.Now())
+
+	cfg, err := tlsconfig.New(
+		tlsconfig.WithCertFiles(certFile, keyFile),
+		tlsconfig.WithClientCAFile(caFile),
+		tlsconfig.WithLogger(discardLogger),
+	)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	subjects := make(chan string, 2)
+	go func() {
+		for {
+			conn, aErr := ln.Accept()
+			if aErr != nil {
+				return
+			}
+			tlsConn, _ := conn.(*tls.Conn)
+			if tlsConn.Handshake() == nil {
+				subjects <- tlsConn.ConnectionState().VerifiedChains[0][0].Subject.CommonName
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	dial := func(certs ...tls.Certificate) error {
+		conn, dErr := tls.Dial("tcp", ln.Addr().String(), &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			MinVersion:   tls.VersionTLS12,
+		})
+		if dErr != nil {
+			return dErr
+		}
+		defer func() { _ = conn.Close() }()
+
+		// tls 1.3 client learns about rejected certificate on first read
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, dErr = conn.Read(make([]byte, 1))
+		if dErr == io.EOF { // nolint:errorlint
+			return nil
+		}
+		return dErr
+	}
+
+	if err = dial(newCert(t, "billing", false, ca).tlsCertificate()); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if subject := <-subjects; subject != "billing" {
+		t.Errorf("wrong client subject, want: %s, got: %s", "billing", subject)
+	}
+
+	if err = dial(); err == nil {
+		t.Error("error expected without client certificate")
+	}
+	if err = dial(newCert(t, "intruder", false, nil).tlsCertificate()); err == nil {
+		t.Error("error expected for client certificate of unknown ca")
+	}
+}
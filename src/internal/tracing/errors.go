@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var _ error = (*Error)(nil) // compile time proof
+
+// Error is an error of a traced request.
+type Error struct {
+	RequestID string
+	TraceID   string
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error() + " (request_id: " + e.RequestID + ", trace_id: " + e.TraceID + ")"
+}
+
+// Unwrap unwraps error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf formats error like fmt.Errorf, error carries request id and trace
+// id of ctx if ctx has a trace and err does not carry them already.
+func Errorf(ctx context.Context, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+
+	trace, ok := FromContext(ctx)
+	if !ok {
+		return err
+	}
+
+	var traced *Error
+	if errors.As(err, &traced) {
+		return err
+	}
+
+	return &Error{
+		RequestID: trace.RequestID,
+		TraceID:   trace.TraceID,
+		Err:       err,
+	}
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+)
+
+var _ slog.Handler = (*logHandler)(nil) // compile time proof
+
+// logHandler adds request id and trace ids of the context to records.
+type logHandler struct {
+	next slog.Handler
+}
+
+// NewLogHandler returns handler adding request_id, trace_id and span_id
+// attributes to records which are logged with a context carrying trace,
+// e.g. Logger.ErrorContext(r.Context(), ...).
+func NewLogHandler(next slog.Handler) slog.Handler {
+	if h, ok := next.(*logHandler); ok {
+		return h
+	}
+	return &logHandler{next: next}
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if trace, ok := FromContext(ctx); ok {
+		record = record.Clone()
+		record.AddAttrs(
+			slog.String("request_id", trace.RequestID),
+			slog.String("trace_id", trace.TraceID),
+			slog.String("span_id", trace.SpanID),
+		)
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,145 @@
+// Package tracing carries request id and W3C trace context of a request
+// through context, logs and errors.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// headers.
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+)
+
+// MaxRequestIDLength is the maximum length of accepted request id, longer
+// ids are replaced with a generated one.
+const MaxRequestIDLength = 128
+
+const (
+	traceparentVersion = "00"
+	flagSampled        = "01"
+	flagNotSampled     = "00"
+)
+
+// Trace identifies a request and its place in a distributed trace.
+type Trace struct {
+	RequestID    string
+	TraceID      string // 32 lowercase hex characters
+	SpanID       string // 16 lowercase hex characters, span of this server
+	ParentSpanID string // span of the caller, empty if trace starts here
+	Sampled      bool
+}
+
+type contextKey struct{}
+
+// NewContext returns ctx carrying trace.
+func NewContext(ctx context.Context, trace Trace) context.Context {
+	return context.WithValue(ctx, contextKey{}, trace)
+}
+
+// FromContext returns trace of ctx, ok is false if there is none.
+func FromContext(ctx context.Context) (Trace, bool) {
+	trace, ok := ctx.Value(contextKey{}).(Trace)
+	return trace, ok
+}
+
+// New returns trace continuing traceparent of caller if it is valid,
+// starting a new trace otherwise. Request id is kept if valid, generated
+// otherwise.
+func New(requestID, traceparent string) Trace {
+	trace := Trace{
+		RequestID: requestID,
+		SpanID:    randomHex(8),
+	}
+
+	if !validRequestID(requestID) {
+		trace.RequestID = randomHex(16)
+	}
+
+	if traceID, parentSpanID, sampled, ok := ParseTraceparent(traceparent); ok {
+		trace.TraceID = traceID
+		trace.ParentSpanID = parentSpanID
+		trace.Sampled = sampled
+	} else {
+		trace.TraceID = randomHex(16)
+		trace.Sampled = true
+	}
+
+	return trace
+}
+
+// Traceparent returns traceparent header value of the trace, parent is
+// the span of this server.
+func (t Trace) Traceparent() string {
+	flags := flagNotSampled
+	if t.Sampled {
+		flags = flagSampled
+	}
+	return traceparentVersion + "-" + t.TraceID + "-" + t.SpanID + "-" + flags
+}
+
+// ParseTraceparent parses traceparent header value, ok is false if it is
+// not valid. Versions newer than 00 are parsed as 00, extra fields are
+// ignored.
+func ParseTraceparent(s string) (traceID, parentSpanID string, sampled, ok bool) {
+	fields := strings.Split(strings.TrimSpace(s), "-")
+	if len(fields) < 4 {
+		return "", "", false, false
+	}
+
+	version, traceID, parentSpanID, flags := fields[0], fields[1], fields[2], fields[3]
+
+	if !isHex(version, 2) || version == "ff" || (version == traceparentVersion && len(fields) != 4) {
+		return "", "", false, false
+	}
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) {
+		return "", "", false, false
+	}
+	if !isHex(parentSpanID, 16) || parentSpanID == strings.Repeat("0", 16) {
+		return "", "", false, false
+	}
+	if !isHex(flags, 2) {
+		return "", "", false, false
+	}
+
+	flagsValue, _ := hex.DecodeString(flags)
+	return traceID, parentSpanID, flagsValue[0]&1 == 1, true
+}
+
+// isHex reports whether s is n lowercase hex characters.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// validRequestID reports whether id is non-empty printable ascii up to
+// MaxRequestIDLength, ids end up in logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > MaxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,112 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tcs := []struct {
+		header  string
+		ok      bool
+		sampled bool
+	}{
+		{"00-" + traceID + "-" + spanID + "-01", true, true},
+		{"00-" + traceID + "-" + spanID + "-00", true, false},
+		{"01-" + traceID + "-" + spanID + "-03-future", true, true},
+		{"00-" + traceID + "-" + spanID + "-01-extra", false, false},
+		{"ff-" + traceID + "-" + spanID + "-01", false, false},
+		{"00-" + strings.Repeat("0", 32) + "-" + spanID + "-01", false, false},
+		{"00-" + traceID + "-" + strings.Repeat("0", 16) + "-01", false, false},
+		{"00-" + strings.ToUpper(traceID) + "-" + spanID + "-01", false, false},
+		{"00-" + traceID + "-" + spanID, false, false},
+		{"", false, false},
+	}
+
+	for _, tc := range tcs {
+		gotTraceID, gotSpanID, sampled, ok := tracing.ParseTraceparent(tc.header)
+		if ok != tc.ok {
+			t.Errorf("wrong result for %q, want: %v, got: %v", tc.header, tc.ok, ok)
+			continue
+		}
+		if ok && (gotTraceID != traceID || gotSpanID != spanID || sampled != tc.sampled) {
+			t.Errorf("wrong fields for %q: %s %s %v", tc.header, gotTraceID, gotSpanID, sampled)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	trace := tracing.New("req-1", "00-"+traceID+"-"+spanID+"-01")
+
+	if trace.RequestID != "req-1" || trace.TraceID != traceID || trace.ParentSpanID != spanID {
+		t.Errorf("wrong trace: %+v", trace)
+	}
+	if trace.SpanID == spanID || len(trace.SpanID) != 16 {
+		t.Errorf("wrong span id: %s", trace.SpanID)
+	}
+	if want := "00-" + traceID + "-" + trace.SpanID + "-01"; trace.Traceparent() != want {
+		t.Errorf("wrong traceparent, want: %s, got: %s", want, trace.Traceparent())
+	}
+
+	trace = tracing.New("bad id\n", "invalid")
+	if len(trace.RequestID) != 32 || len(trace.TraceID) != 32 || trace.ParentSpanID != "" || !trace.Sampled {
+		t.Errorf("wrong new trace: %+v", trace)
+	}
+	if _, _, _, ok := tracing.ParseTraceparent(trace.Traceparent()); !ok {
+		t.Errorf("invalid traceparent: %s", trace.Traceparent())
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.Info("without trace")
+	ctx := tracing.NewContext(context.Background(), tracing.Trace{RequestID: "req-1", TraceID: traceID, SpanID: spanID})
+	logger.ErrorContext(ctx, "with trace")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if strings.Contains(lines[0], "request_id") {
+		t.Errorf("request id must not be logged: %s", lines[0])
+	}
+	for _, attr := range []string{"component=test", "request_id=req-1", "trace_id=" + traceID, "span_id=" + spanID} {
+		if !strings.Contains(lines[1], attr) {
+			t.Errorf("%s is missing: %s", attr, lines[1])
+		}
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	err := tracing.Errorf(context.Background(), "get err: %w", kverror.ErrKeyNotFound)
+	if err.Error() != "get err: key not found" {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	ctx := tracing.NewContext(context.Background(), tracing.Trace{RequestID: "req-1", TraceID: traceID})
+	err = tracing.Errorf(ctx, "get err: %w", kverror.ErrKeyNotFound)
+	err = tracing.Errorf(ctx, "handler err: %w", err)
+
+	if want := "handler err: get err: key not found (request_id: req-1, trace_id: " + traceID + ")"; err.Error() != want {
+		t.Errorf("wrong error, want: %s, got: %s", want, err)
+	}
+	if !errors.Is(err, kverror.ErrKeyNotFound) {
+		t.Error("error must wrap kverror.ErrKeyNotFound")
+	}
+
+	var traced *tracing.Error
+	if !errors.As(err, &traced) || traced.RequestID != "req-1" {
+		t.Errorf("wrong traced error: %#v", traced)
+	}
+}
@@ -0,0 +1,177 @@
+package basehttphandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+)
+
+var _ error = (*Error)(nil) // compile time proof
+
+// ProblemContentType is the content type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// codes of errors which are not kverror codes.
+const (
+	CodeInvalidRequest   = "invalid_request"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeTimeout          = "timeout"
+	CodeCanceled         = "canceled"
+	CodeInternal         = "internal_error"
+)
+
+// StatusClientClosedRequest is the status of requests canceled by client,
+// client never receives it but it ends up in access log and metrics.
+const StatusClientClosedRequest = 499
+
+// kverrorStatuses are status codes of kverror codes, missing codes are
+// server errors.
+var kverrorStatuses = map[kverror.Code]int{
+	kverror.ErrKeyNotFound:       http.StatusNotFound,
+	kverror.ErrNamespaceNotFound: http.StatusNotFound,
+	kverror.ErrKeyExists:         http.StatusConflict,
+	kverror.ErrNamespaceExists:   http.StatusConflict,
+	kverror.ErrVersionMismatch:   http.StatusConflict,
+	kverror.ErrInvalidTxn:        http.StatusBadRequest,
+	kverror.ErrInvalidNamespace:  http.StatusBadRequest,
+	kverror.ErrRevisionCompacted: http.StatusGone,
+}
+
+// Problem is RFC 7807 problem details with stable, machine-readable Code.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+	Error     string `json:"error,omitempty"` // same as detail, for clients of the former {"error": "..."} body
+}
+
+// Error is an error of request which is not a kverror, e.g. invalid payload,
+// or overrides status of its cause.
+type Error struct {
+	Status  int
+	Code    string // taken from Err if empty
+	Message string // taken from Err if empty
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap unwraps error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// BadRequest returns invalid request error with message.
+func BadRequest(message string) error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeInvalidRequest, Message: message}
+}
+
+// NotFound returns not found error with message.
+func NotFound(message string) error {
+	return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: message}
+}
+
+// MethodNotAllowed returns error of not allowed method.
+func MethodNotAllowed(method string) error {
+	return &Error{
+		Status:  http.StatusMethodNotAllowed,
+		Code:    CodeMethodNotAllowed,
+		Message: "method " + method + " not allowed",
+	}
+}
+
+// ProblemOf maps err to status, code and detail: kverror codes, context
+// deadline and cancellation and *Error values are known, anything else is
+// an internal error with a generic detail, cause is never exposed.
+func ProblemOf(err error) Problem {
+	var p Problem
+
+	kvErr, ok := kverror.From(err)
+	switch {
+	case ok:
+		p.Status, p.Code, p.Detail = http.StatusInternalServerError, string(kvErr.Code), kvErr.Message()
+		if status, known := kverrorStatuses[kvErr.Code]; known {
+			p.Status = status
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		p.Status, p.Code, p.Detail = http.StatusGatewayTimeout, CodeTimeout, err.Error()
+	case errors.Is(err, context.Canceled):
+		p.Status, p.Code, p.Detail = StatusClientClosedRequest, CodeCanceled, err.Error()
+	default:
+		p.Status, p.Code, p.Detail = http.StatusInternalServerError, CodeInternal, "internal error"
+	}
+
+	var reqErr *Error
+	if errors.As(err, &reqErr) {
+		if reqErr.Status != 0 {
+			p.Status = reqErr.Status
+		}
+		if reqErr.Code != "" {
+			p.Code = reqErr.Code
+		}
+		if reqErr.Message != "" {
+			p.Detail = reqErr.Message
+		}
+	}
+
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	if p.Status == StatusClientClosedRequest {
+		p.Title = "Client Closed Request"
+	}
+	p.Error = p.Detail
+
+	return p
+}
+
+// NewProblem returns problem of err for request r.
+func NewProblem(r *http.Request, err error) Problem {
+	p := ProblemOf(err)
+	p.Instance = r.URL.Path
+	if trace, ok := tracing.FromContext(r.Context()); ok {
+		p.RequestID = trace.RequestID
+	}
+	return p
+}
+
+// WriteProblem writes problem details response.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	j, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+
+	_, _ = w.Write(j)
+}
+
+// Error writes err as problem details response, internal errors and
+// loggable kverrors are logged with the request context, so the log line
+// carries request id of the response.
+func (h *Handler) Error(w http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(r, err)
+
+	if h.Logger != nil && (p.Code == CodeInternal || kverror.CodeOf(err).Loggable()) {
+		h.Logger.ErrorContext(r.Context(), "basehttphandler error response", "status", p.Status, "code", p.Code, "err", err)
+	}
+
+	WriteProblem(w, p)
+}
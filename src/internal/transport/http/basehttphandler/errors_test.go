@@ -0,0 +1,128 @@
+package basehttphandler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/tracing"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+func TestProblemOf(t *testing.T) {
+	tcs := []struct {
+		err    error
+		status int
+		code   string
+		detail string
+	}{
+		{kverror.New(kverror.ErrKeyNotFound, "get", "a", "'a' does not exist"), http.StatusNotFound, "key_not_found", "key not found, 'a' does not exist"},
+		{fmt.Errorf("service err: %w", kverror.ErrVersionMismatch), http.StatusConflict, "version_mismatch", "version mismatch"},
+		{kverror.ErrRevisionCompacted, http.StatusGone, "revision_compacted", "revision compacted"},
+		{kverror.ErrUnknown, http.StatusInternalServerError, "unknown", "unknown error"},
+		{fmt.Errorf("service err: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, basehttphandler.CodeTimeout, "service err: context deadline exceeded"},
+		{context.Canceled, basehttphandler.StatusClientClosedRequest, basehttphandler.CodeCanceled, "context canceled"},
+		{basehttphandler.BadRequest("key is empty"), http.StatusBadRequest, basehttphandler.CodeInvalidRequest, "key is empty"},
+		{basehttphandler.MethodNotAllowed(http.MethodPatch), http.StatusMethodNotAllowed, basehttphandler.CodeMethodNotAllowed, "method PATCH not allowed"},
+		{errors.New("boom"), http.StatusInternalServerError, basehttphandler.CodeInternal, "internal error"}, // nolint
+		{
+			&basehttphandler.Error{Status: http.StatusPreconditionFailed, Err: kverror.ErrVersionMismatch},
+			http.StatusPreconditionFailed, "version_mismatch", "version mismatch",
+		},
+	}
+
+	for _, tc := range tcs {
+		p := basehttphandler.ProblemOf(tc.err)
+		if p.Status != tc.status {
+			t.Errorf("wrong status of %v, want: %d, got: %d", tc.err, tc.status, p.Status)
+		}
+		if p.Code != tc.code {
+			t.Errorf("wrong code of %v, want: %s, got: %s", tc.err, tc.code, p.Code)
+		}
+		if p.Detail != tc.detail {
+			t.Errorf("wrong detail of %v, want: %s, got: %s", tc.err, tc.detail, p.Detail)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	h := &basehttphandler.Handler{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/get/?key=a", nil)
+	req = req.WithContext(tracing.NewContext(req.Context(), tracing.Trace{RequestID: "req-1"}))
+	w := httptest.NewRecorder()
+
+	h.Error(w, req, kverror.New(kverror.ErrKeyNotFound, "get", "a", "'a' does not exist"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	}
+
+	if contentType := w.Header().Get("Content-Type"); contentType != basehttphandler.ProblemContentType {
+		t.Errorf("wrong content type, want: %s, got: %s", basehttphandler.ProblemContentType, contentType)
+	}
+
+	var p basehttphandler.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	want := basehttphandler.Problem{
+		Type:      "about:blank",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "key not found, 'a' does not exist",
+		Instance:  "/api/v1/get/",
+		Code:      "key_not_found",
+		RequestID: "req-1",
+		Error:     "key not found, 'a' does not exist",
+	}
+	if p != want {
+		t.Errorf("wrong problem, want: %+v, got: %+v", want, p)
+	}
+}
+
+func TestErrorInternal(t *testing.T) {
+	var logs bytes.Buffer
+	h := &basehttphandler.Handler{
+		Logger: slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&logs, nil))),
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/get/?key=a", nil)
+	req = req.WithContext(tracing.NewContext(req.Context(), tracing.Trace{RequestID: "req-1"}))
+	w := httptest.NewRecorder()
+
+	h.Error(w, req, errors.New("open /var/lib/kvstore/aof: permission denied")) // nolint
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusInternalServerError, w.Code)
+	}
+
+	var p basehttphandler.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if p.Detail != "internal error" || p.Error != "internal error" || p.RequestID != "req-1" {
+		t.Errorf("wrong problem: %+v", p)
+	}
+	if strings.Contains(w.Body.String(), "permission denied") {
+		t.Errorf("internal error must not be exposed: %s", w.Body.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if entry["request_id"] != "req-1" {
+		t.Errorf("wrong request id, want: %s, got: %v", "req-1", entry["request_id"])
+	}
+	if err, _ := entry["err"].(string); !strings.Contains(err, "permission denied") {
+		t.Errorf("internal error must be logged, got: %v", entry["err"])
+	}
+}
@@ -18,6 +18,10 @@ type KVStoreHTTPHandler interface {
 	Update(http.ResponseWriter, *http.Request)
 	Delete(http.ResponseWriter, *http.Request)
 	List(http.ResponseWriter, *http.Request)
+	Txn(http.ResponseWriter, *http.Request)
+	Batch(http.ResponseWriter, *http.Request)
+	Namespaces(http.ResponseWriter, *http.Request)
+	Watch(http.ResponseWriter, *http.Request)
 }
 
 type kvstoreHandler struct {
@@ -16,13 +16,24 @@ type mockService struct {
 	getResponse    *kvstoreservice.ItemResponse
 	listErr        error
 	listResponse   *kvstoreservice.ListResponse
+	listRequest    *kvstoreservice.ListRequest
 	setErr         error
 	setResponse    *kvstoreservice.ItemResponse
 	updateErr      error
 	updateResponse *kvstoreservice.ItemResponse
+	txnErr         error
+	txnResponse    *kvstoreservice.TxnResponse
+	batchErr       error
+	batchResponse  *kvstoreservice.BatchResponse
+	nsErr          error
+	watchErr       error
+	watchEvents    chan kvstoreservice.WatchEvent
+	watchRequest   *kvstoreservice.WatchRequest
+	namespace      string
+	namespaces     []string
 }
 
-func (m *mockService) Delete(_ context.Context, _ string) error {
+func (m *mockService) Delete(_ context.Context, _ *kvstoreservice.DeleteRequest) error {
 	return m.deleteErr
 }
 
@@ -30,7 +41,8 @@ func (m *mockService) Get(_ context.Context, _ string) (*kvstoreservice.ItemResp
 	return m.getResponse, m.getErr
 }
 
-func (m *mockService) List(_ context.Context) (*kvstoreservice.ListResponse, error) {
+func (m *mockService) List(_ context.Context, lr *kvstoreservice.ListRequest) (*kvstoreservice.ListResponse, error) {
+	m.listRequest = lr
 	return m.listResponse, m.listErr
 }
 
@@ -41,3 +53,37 @@ func (m *mockService) Set(_ context.Context, _ *kvstoreservice.SetRequest) (*kvs
 func (m *mockService) Update(_ context.Context, _ *kvstoreservice.UpdateRequest) (*kvstoreservice.ItemResponse, error) {
 	return m.updateResponse, m.updateErr
 }
+
+func (m *mockService) Txn(_ context.Context, _ *kvstoreservice.TxnRequest) (*kvstoreservice.TxnResponse, error) {
+	return m.txnResponse, m.txnErr
+}
+
+func (m *mockService) Batch(_ context.Context, _ *kvstoreservice.BatchRequest) (*kvstoreservice.BatchResponse, error) {
+	return m.batchResponse, m.batchErr
+}
+
+func (m *mockService) Namespace(name string) kvstoreservice.KVStoreService {
+	m.namespace = name
+	return m
+}
+
+func (m *mockService) CreateNamespace(_ context.Context, _ string) error {
+	return m.nsErr
+}
+
+func (m *mockService) DropNamespace(_ context.Context, _ string) error {
+	return m.nsErr
+}
+
+func (m *mockService) ListNamespaces(_ context.Context) ([]string, error) {
+	return m.namespaces, m.nsErr
+}
+
+func (m *mockService) Watch(_ context.Context, wr *kvstoreservice.WatchRequest) (<-chan kvstoreservice.WatchEvent, error) {
+	m.watchRequest = wr
+	return m.watchEvents, m.watchErr
+}
+
+func (m *mockService) Stats(_ context.Context) (*kvstoreservice.StatsResponse, error) {
+	return &kvstoreservice.StatsResponse{}, nil
+}
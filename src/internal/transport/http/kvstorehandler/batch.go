@@ -0,0 +1,145 @@
+package kvstorehandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+// MaxBatchItems is the maximum number of items in a batch request.
+const MaxBatchItems = 1000
+
+func (h *kvstoreHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
+		return
+	}
+
+	if len(body) == 0 {
+		h.Error(w, r, basehttphandler.BadRequest("empty body/payload"))
+		return
+	}
+
+	var handlerRequest BatchRequest
+	if err = json.Unmarshal(body, &handlerRequest); err != nil {
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
+		return
+	}
+
+	switch handlerRequest.Op {
+	case kvstoreservice.BatchOpGet, kvstoreservice.BatchOpSet, kvstoreservice.BatchOpDelete:
+	default:
+		h.Error(w, r, basehttphandler.BadRequest("unknown op '"+handlerRequest.Op+"'"))
+		return
+	}
+
+	if len(handlerRequest.Items) == 0 {
+		h.Error(w, r, basehttphandler.BadRequest("items are empty"))
+		return
+	}
+
+	if len(handlerRequest.Items) > MaxBatchItems {
+		h.Error(w, r, basehttphandler.BadRequest("too many items, max: "+strconv.Itoa(MaxBatchItems)))
+		return
+	}
+
+	serviceRequest := kvstoreservice.BatchRequest{
+		Op:    handlerRequest.Op,
+		Items: make([]kvstoreservice.BatchItem, len(handlerRequest.Items)),
+	}
+	for i, item := range handlerRequest.Items {
+		var message string
+
+		switch {
+		case item.Key == "":
+			message = "key is empty"
+		case handlerRequest.Op == kvstoreservice.BatchOpSet && item.Value == nil:
+			message = "value is empty"
+		default:
+			message = ttlError(item.TTL)
+		}
+
+		if message != "" {
+			h.Error(w, r, basehttphandler.BadRequest(message+", item: "+strconv.Itoa(i)))
+			return
+		}
+
+		serviceRequest.Items[i] = kvstoreservice.BatchItem{
+			Key:   item.Key,
+			Value: item.Value,
+			TTL:   time.Duration(item.TTL) * time.Second,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
+
+	serviceResponse, err := h.service.Batch(ctx, &serviceRequest)
+	if err != nil {
+		h.Error(w, r, err)
+		return
+	}
+
+	successStatus := http.StatusOK
+	switch handlerRequest.Op {
+	case kvstoreservice.BatchOpSet:
+		successStatus = http.StatusCreated
+	case kvstoreservice.BatchOpDelete:
+		successStatus = http.StatusNoContent
+	}
+
+	handlerResponse := BatchResponse{
+		Results: make([]BatchItemResponse, len(serviceResponse.Results)),
+	}
+	for i, result := range serviceResponse.Results {
+		itemResponse := BatchItemResponse{
+			Key:    result.Key,
+			Status: successStatus,
+		}
+
+		if result.Err != nil {
+			problem := h.batchItemError(r, result.Err)
+			itemResponse.Status, itemResponse.Code, itemResponse.Error = problem.Status, problem.Code, problem.Detail
+		}
+
+		if result.Item != nil {
+			itemResponse.Item = &ItemResponse{
+				Key:       result.Item.Key,
+				Value:     result.Item.Value,
+				ExpiresAt: expiresAt(result.Item.ExpiresAt),
+				Version:   result.Item.Version,
+			}
+		}
+
+		handlerResponse.Results[i] = itemResponse
+	}
+
+	h.JSON(
+		w,
+		http.StatusOK,
+		handlerResponse,
+	)
+}
+
+// batchItemError maps per-key error to problem, key is already part of the
+// result.
+func (h *kvstoreHandler) batchItemError(r *http.Request, err error) basehttphandler.Problem {
+	problem := basehttphandler.ProblemOf(err)
+	if problem.Code == basehttphandler.CodeInternal || kverror.CodeOf(err).Loggable() {
+		h.Logger.ErrorContext(r.Context(), "kvstorehandler Batch service.Batch", "err", err)
+	}
+	return problem
+}
@@ -0,0 +1,124 @@
+package kvstorehandler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+)
+
+func TestBatchInvalidMethod(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Batch(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestBatchEmptyBody(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Batch(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestBatchInvalidPayload(t *testing.T) {
+	tooMany := `{"op":"get","items":[` + strings.Repeat(`{"key":"a"},`, kvstorehandler.MaxBatchItems) + `{"key":"a"}]}`
+
+	tests := []struct {
+		payload       string
+		shouldContain string
+	}{
+		{`{"op":"rename","items":[{"key":"a"}]}`, "unknown op"},
+		{`{"op":"get","items":[]}`, "items are empty"},
+		{tooMany, fmt.Sprintf("too many items, max: %d", kvstorehandler.MaxBatchItems)},
+		{`{"op":"get","items":[{"key":"a"},{"key":""}]}`, "key is empty, item: 1"},
+		{`{"op":"set","items":[{"key":"a"}]}`, "value is empty, item: 0"},
+		{`{"op":"set","items":[{"key":"a","value":"1","ttl":-1}]}`, "ttl can not be negative"},
+		{`{"op":"set","items":[{"key":"a","value":"1","ttl":9223372037}]}`, "ttl can not be greater than"},
+	}
+
+	for _, tc := range tests {
+		handler := kvstorehandler.New()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.payload))
+		w := httptest.NewRecorder()
+
+		handler.Batch(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+		}
+
+		if !strings.Contains(w.Body.String(), tc.shouldContain) {
+			t.Errorf("wrong body message, want: %s, got: %s", tc.shouldContain, w.Body.String())
+		}
+	}
+}
+
+func TestBatchTimeout(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithContextTimeout(time.Second*-1),
+		kvstorehandler.WithService(&mockService{
+			batchErr: context.DeadlineExceeded,
+		}),
+	)
+
+	payload := strings.NewReader(`{"op":"get","items":[{"key":"a"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Batch(w, req)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusGatewayTimeout, w.Code)
+	}
+}
+
+func TestBatchSuccess(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithService(&mockService{
+			batchResponse: &kvstoreservice.BatchResponse{
+				Results: []kvstoreservice.BatchItemResponse{
+					{Key: "a", Item: &kvstoreservice.ItemResponse{Key: "a", Value: "1", Version: 3}},
+					{Key: "b", Err: fmt.Errorf("%w", kverror.ErrKeyExists)},
+					{Key: "c", Err: kverror.ErrUnknown},
+				},
+			},
+		}),
+	)
+
+	payload := strings.NewReader(`{"op":"set","items":[{"key":"a","value":"1"},{"key":"b","value":"2"},{"key":"c","value":"3"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Batch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	shouldEqual := `{"results":[` +
+		`{"key":"a","status":201,"item":{"key":"a","value":"1","version":3}},` +
+		`{"key":"b","status":409,"code":"key_exists","error":"key exist"},` +
+		`{"key":"c","status":500,"code":"unknown","error":"unknown error"}]}`
+	if w.Body.String() != shouldEqual {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
+	}
+}
@@ -2,81 +2,58 @@ package kvstorehandler
 
 import (
 	"context"
-	"errors"
 	"net/http"
+	"strconv"
 
-	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 )
 
 func (h *kvstoreHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		h.JSON(
-			w,
-			http.StatusMethodNotAllowed,
-			map[string]string{"error": "method " + r.Method + " not allowed"},
-		)
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
 		return
 	}
 
 	if len(r.URL.Query()) == 0 {
-		h.JSON(
-			w,
-			http.StatusNotFound,
-			map[string]string{"error": "key query param required"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("key query param required"))
 		return
 	}
 
 	keys, ok := r.URL.Query()["key"]
 	if !ok {
-		h.JSON(
-			w,
-			http.StatusNotFound,
-			map[string]string{"error": "key not present"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("key not present"))
 		return
 	}
 
-	key := keys[0]
-
-	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
-	defer cancel()
+	serviceRequest := kvstoreservice.DeleteRequest{
+		Key: keys[0],
+	}
 
-	if err := h.service.Delete(ctx, key); err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			h.JSON(
-				w,
-				http.StatusGatewayTimeout,
-				map[string]string{"error": err.Error()},
-			)
+	if version := r.URL.Query().Get("version"); version != "" {
+		v, err := strconv.ParseUint(version, 10, 64)
+		if err != nil {
+			h.Error(w, r, basehttphandler.BadRequest("invalid version"))
 			return
 		}
+		serviceRequest.Version = v
+	}
 
-		var kvErr *kverror.Error
-
-		if errors.As(err, &kvErr) {
-			clientMessage := kvErr.Message
-			if kvErr.Data != nil {
-				data, ok := kvErr.Data.(string)
-				if ok {
-					clientMessage = clientMessage + ", " + data
-				}
-			}
+	ifMatchHeader := r.Header.Get("If-Match")
+	if ifMatchHeader != "" {
+		version, err := parseIfMatch(ifMatchHeader)
+		if err != nil {
+			h.Error(w, r, ifMatchError(err))
+			return
+		}
+		serviceRequest.Version = version
+	}
 
-			if kvErr.Loggable {
-				h.Logger.Error("kvstorehandler Delete service.Delete", "err", clientMessage)
-			}
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
 
-			if kvErr == kverror.ErrKeyNotFound {
-				h.JSON(w, http.StatusNotFound, map[string]string{"error": clientMessage})
-				return
-			}
-		}
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
+	if err := h.service.Delete(ctx, &serviceRequest); err != nil {
+		h.Error(w, r, preconditionError(ifMatchHeader, err))
 		return
 	}
 
@@ -36,8 +36,8 @@ func TestDeleteQueryParamRequired(t *testing.T) {
 
 	handler.Delete(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
 	}
 
 	shouldContain := "key query param required"
@@ -53,8 +53,8 @@ func TestDeleteQueryParamKeyNotFound(t *testing.T) {
 
 	handler.Delete(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
 	}
 
 	shouldContain := "key not present"
@@ -110,11 +110,9 @@ func TestDeleteErrUnknown(t *testing.T) {
 }
 
 func TestDeleteErrKeyNotFound(t *testing.T) {
-	_ = kverror.ErrKeyNotFound.AddData("key=test") // ignore error.
-
 	handler := kvstorehandler.New(
 		kvstorehandler.WithService(&mockService{
-			deleteErr: kverror.ErrKeyNotFound,
+			deleteErr: kverror.New(kverror.ErrKeyNotFound, "delete", "test", "key=test"),
 		}),
 		kvstorehandler.WithLogger(logger),
 	)
@@ -136,8 +134,46 @@ func TestDeleteErrKeyNotFound(t *testing.T) {
 	if !strings.Contains(w.Body.String(), shouldContain) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
 	}
+}
+
+func TestDeleteInvalidVersion(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodDelete, "/?key=test&version=abc", nil)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "invalid version"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
+func TestDeleteErrVersionMismatch(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			deleteErr: kverror.ErrVersionMismatch,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	req := httptest.NewRequest(http.MethodDelete, "/?key=test&version=3", nil)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusConflict, w.Code)
+	}
 
-	_ = kverror.ErrKeyNotFound.DestoryData() // ignore error.
+	shouldContain := "version mismatch"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
 }
 
 func TestDeleteSuccess(t *testing.T) {
@@ -159,3 +195,39 @@ func TestDeleteSuccess(t *testing.T) {
 		t.Errorf("wrong body size, want: 0, got: %d", w.Body.Len())
 	}
 }
+
+func TestDeleteIfMatchPreconditionFailed(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			deleteErr: kverror.ErrKeyNotFound,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	req := httptest.NewRequest(http.MethodDelete, "/?key=test", nil)
+	req.Header.Set("If-Match", "*")
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusPreconditionFailed, w.Code)
+	}
+}
+
+func TestDeleteIfMatchWeakETag(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	req := httptest.NewRequest(http.MethodDelete, "/?key=test", nil)
+	req.Header.Set("If-Match", `W/"3"`)
+	w := httptest.NewRecorder()
+
+	handler.Delete(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusPreconditionFailed, w.Code)
+	}
+}
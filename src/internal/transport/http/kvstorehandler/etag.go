@@ -0,0 +1,93 @@
+package kvstorehandler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+var (
+	errInvalidETag = errors.New("invalid entity tag")
+	errWeakETag    = errors.New("weak entity tag never matches If-Match")
+)
+
+// formatETag generates strong entity tag from item version.
+func formatETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// parseETag parses single entity tag to item version, weak tags are accepted.
+func parseETag(tag string) (uint64, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, errInvalidETag
+	}
+
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return 0, errInvalidETag
+	}
+	return version, nil
+}
+
+// parseIfMatch parses If-Match header value. Zero version means "*", any
+// existing item matches. Only single entity tag is supported since it is
+// checked atomically by the storage. If-Match uses strong comparison (RFC
+// 7232 section 3.1), weak tags never match.
+func parseIfMatch(header string) (uint64, error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return 0, nil
+	}
+
+	if strings.Contains(header, ",") {
+		return 0, errors.New("only single entity tag is supported in If-Match")
+	}
+
+	if strings.HasPrefix(header, "W/") {
+		return 0, errWeakETag
+	}
+
+	return parseETag(header)
+}
+
+// ifMatchError returns error of invalid If-Match header, 412 for weak tags
+// and 400 otherwise.
+func ifMatchError(err error) error {
+	if errors.Is(err, errWeakETag) {
+		return &basehttphandler.Error{
+			Status:  http.StatusPreconditionFailed,
+			Code:    string(kverror.ErrVersionMismatch),
+			Message: err.Error(),
+		}
+	}
+	return basehttphandler.BadRequest(err.Error())
+}
+
+// ifNoneMatch reports whether If-None-Match header value matches given
+// version, uses weak comparison.
+func ifNoneMatch(header string, version uint64) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if v, err := parseETag(tag); err == nil && v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// preconditionError turns version conflicts of requests with If-Match header
+// into 412 Precondition Failed.
+func preconditionError(ifMatchHeader string, err error) error {
+	if ifMatchHeader != "" && (errors.Is(err, kverror.ErrKeyNotFound) || errors.Is(err, kverror.ErrVersionMismatch)) {
+		return &basehttphandler.Error{Status: http.StatusPreconditionFailed, Err: err}
+	}
+	return err
+}
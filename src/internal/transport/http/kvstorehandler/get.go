@@ -2,38 +2,25 @@ package kvstorehandler
 
 import (
 	"context"
-	"errors"
 	"net/http"
 
-	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 )
 
 func (h *kvstoreHandler) Get(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		h.JSON(
-			w,
-			http.StatusMethodNotAllowed,
-			map[string]string{"error": "method " + r.Method + " not allowed"},
-		)
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
 		return
 	}
 
 	if len(r.URL.Query()) == 0 {
-		h.JSON(
-			w,
-			http.StatusNotFound,
-			map[string]string{"error": "key query param required"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("key query param required"))
 		return
 	}
 
 	keys, ok := r.URL.Query()["key"]
 	if !ok {
-		h.JSON(
-			w,
-			http.StatusNotFound,
-			map[string]string{"error": "key not present"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("key not present"))
 		return
 	}
 
@@ -44,46 +31,26 @@ func (h *kvstoreHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 	serviceResponse, err := h.service.Get(ctx, key)
 	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			h.JSON(
-				w,
-				http.StatusGatewayTimeout,
-				map[string]string{"error": err.Error()},
-			)
-			return
-		}
-
-		var kvErr *kverror.Error
-
-		if errors.As(err, &kvErr) {
-			clientMessage := kvErr.Message
-			if kvErr.Data != nil {
-				data, ok := kvErr.Data.(string)
-				if ok {
-					clientMessage = clientMessage + ", " + data
-				}
-			}
+		h.Error(w, r, err)
+		return
+	}
 
-			if kvErr.Loggable {
-				h.Logger.Error("kvstorehandler Get service.Get", "err", clientMessage)
-			}
+	if serviceResponse.Version > 0 {
+		w.Header().Set("ETag", formatETag(serviceResponse.Version))
+	}
 
-			if kvErr == kverror.ErrKeyNotFound {
-				h.JSON(w, http.StatusNotFound, map[string]string{"error": clientMessage})
-				return
-			}
+	if ifNoneMatchHeader := r.Header.Get("If-None-Match"); ifNoneMatchHeader != "" {
+		if ifNoneMatch(ifNoneMatchHeader, serviceResponse.Version) {
+			w.WriteHeader(http.StatusNotModified)
+			return
 		}
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
-		return
 	}
 
 	handlerResponse := ItemResponse{
-		Key:   serviceResponse.Key,
-		Value: serviceResponse.Value,
+		Key:       serviceResponse.Key,
+		Value:     serviceResponse.Value,
+		ExpiresAt: expiresAt(serviceResponse.ExpiresAt),
+		Version:   serviceResponse.Version,
 	}
 
 	h.JSON(
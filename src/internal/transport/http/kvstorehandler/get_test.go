@@ -10,6 +10,7 @@ import (
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
 )
 
@@ -37,8 +38,8 @@ func TestGetQueryParamRequired(t *testing.T) {
 
 	handler.Get(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
 	}
 
 	shouldContain := "key query param required"
@@ -54,8 +55,8 @@ func TestGetQueryParamKeyNotFound(t *testing.T) {
 
 	handler.Get(w, req)
 
-	if w.Code != http.StatusNotFound {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
 	}
 
 	shouldContain := "key not present"
@@ -111,11 +112,9 @@ func TestGetErrUnknown(t *testing.T) {
 }
 
 func TestGetErrKeyNotFound(t *testing.T) {
-	_ = kverror.ErrKeyNotFound.AddData("key=test") // ignore error.
-
 	handler := kvstorehandler.New(
 		kvstorehandler.WithService(&mockService{
-			getErr: kverror.ErrKeyNotFound,
+			getErr: kverror.New(kverror.ErrKeyNotFound, "get", "test", "key=test"),
 		}),
 		kvstorehandler.WithLogger(logger),
 	)
@@ -139,7 +138,14 @@ func TestGetErrKeyNotFound(t *testing.T) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
 	}
 
-	_ = kverror.ErrKeyNotFound.DestoryData() // ignore error.
+	if contentType := w.Header().Get("Content-Type"); contentType != basehttphandler.ProblemContentType {
+		t.Errorf("wrong content type, want: %s, got: %s", basehttphandler.ProblemContentType, contentType)
+	}
+
+	shouldContain = `"code":"key_not_found"`
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
 }
 
 func TestGetSuccess(t *testing.T) {
@@ -168,3 +174,67 @@ func TestGetSuccess(t *testing.T) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
 	}
 }
+
+func TestGetETag(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithService(&mockService{
+			getResponse: &kvstoreservice.ItemResponse{
+				Key:     "test",
+				Value:   "test",
+				Version: 7,
+			},
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/?key=test", nil)
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	shouldEqual := `"7"`
+	if w.Header().Get("ETag") != shouldEqual {
+		t.Errorf("wrong etag, want: %s, got: %s", shouldEqual, w.Header().Get("ETag"))
+	}
+}
+
+func TestGetIfNoneMatch(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithService(&mockService{
+			getResponse: &kvstoreservice.ItemResponse{
+				Key:     "test",
+				Value:   "test",
+				Version: 7,
+			},
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/?key=test", nil)
+	req.Header.Set("If-None-Match", `"6", W/"7"`)
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotModified, w.Code)
+	}
+
+	if w.Body.Len() != 0 {
+		t.Errorf("wrong body size, want: 0, got: %d", w.Body.Len())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?key=test", nil)
+	req.Header.Set("If-None-Match", `"6"`)
+	w = httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+}
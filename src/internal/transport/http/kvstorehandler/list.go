@@ -2,79 +2,96 @@ package kvstorehandler
 
 import (
 	"context"
-	"errors"
+	"encoding/base64"
 	"net/http"
+	"strconv"
 
-	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 )
 
+// MaxListLimit is the maximum value of limit query param of List.
+const MaxListLimit = 1000
+
+// NextCursorHeader carries continuation cursor of List when there are more
+// items, pass it as cursor query param to get the next page.
+const NextCursorHeader = "X-Next-Cursor"
+
 func (h *kvstoreHandler) List(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		h.JSON(
-			w,
-			http.StatusMethodNotAllowed,
-			map[string]string{"error": "method " + r.Method + " not allowed"},
-		)
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
-	defer cancel()
+	query := r.URL.Query()
+	serviceRequest := kvstoreservice.ListRequest{
+		Prefix: query.Get("prefix"),
+		Start:  query.Get("start"),
+		End:    query.Get("end"),
+	}
 
-	serviceResponse, err := h.service.List(ctx)
-	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			h.JSON(
-				w,
-				http.StatusGatewayTimeout,
-				map[string]string{"error": err.Error()},
-			)
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > MaxListLimit {
+			h.Error(w, r, basehttphandler.BadRequest("invalid limit, must be between 1 and "+strconv.Itoa(MaxListLimit)))
 			return
 		}
+		serviceRequest.Limit = l
+	}
 
-		var kvErr *kverror.Error
-		if errors.As(err, &kvErr) {
-			clientMessage := kvErr.Message
-			if kvErr.Data != nil {
-				data, ok := kvErr.Data.(string)
-				if ok {
-					clientMessage = clientMessage + ", " + data
-				}
-			}
-
-			if kvErr.Loggable {
-				h.Logger.Error("kvstorehandler List service.List", "err", clientMessage)
-			}
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			h.Error(w, r, basehttphandler.BadRequest("invalid cursor"))
+			return
 		}
+		serviceRequest.After = after
+	}
 
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
+
+	serviceResponse, err := h.service.List(ctx, &serviceRequest)
+	if err != nil {
+		h.Error(w, r, err)
 		return
 	}
 
 	var handlerResponse ListResponse
-	for _, item := range *serviceResponse {
+	for _, item := range serviceResponse.Items {
 		handlerResponse = append(handlerResponse, ItemResponse{
-			Key:   item.Key,
-			Value: item.Value,
+			Key:       item.Key,
+			Value:     item.Value,
+			ExpiresAt: expiresAt(item.ExpiresAt),
+			Version:   item.Version,
 		})
 	}
 
 	if len(handlerResponse) == 0 {
-		h.JSON(
-			w,
-			http.StatusNotFound,
-			map[string]string{"error": "nothing found"},
-		)
+		h.Error(w, r, basehttphandler.NotFound("nothing found"))
 		return
 	}
 
+	if serviceResponse.Next != "" {
+		w.Header().Set(NextCursorHeader, encodeCursor(serviceResponse.Next))
+	}
+
 	h.JSON(
 		w,
 		http.StatusOK,
 		handlerResponse,
 	)
 }
+
+// encodeCursor makes opaque cursor from the last key of a page.
+func encodeCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
@@ -56,7 +56,7 @@ func TestListTimeout(t *testing.T) {
 func TestListErrUnknown(t *testing.T) {
 	handler := kvstorehandler.New(
 		kvstorehandler.WithService(&mockService{
-			listErr: kverror.ErrUnknown.AddData("fake error"),
+			listErr: kverror.New(kverror.ErrUnknown, "list", "test", "fake error"),
 		}),
 		kvstorehandler.WithLogger(logger),
 	)
@@ -101,9 +101,11 @@ func TestListSuccess(t *testing.T) {
 		kvstorehandler.WithLogger(logger),
 		kvstorehandler.WithService(&mockService{
 			listResponse: &kvstoreservice.ListResponse{
-				{
-					Key:   "test",
-					Value: "test",
+				Items: []kvstoreservice.ItemResponse{
+					{
+						Key:   "test",
+						Value: "test",
+					},
 				},
 			},
 		}),
@@ -123,3 +125,63 @@ func TestListSuccess(t *testing.T) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
 	}
 }
+
+func TestListInvalidQuery(t *testing.T) {
+	for _, target := range []string{"/?limit=0", "/?limit=abc", "/?limit=1001", "/?cursor=%25%25"} {
+		handler := kvstorehandler.New(
+			kvstorehandler.WithService(&mockService{}),
+		)
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+
+		handler.List(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: wrong status code, want: %d, got: %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	service := &mockService{
+		listResponse: &kvstoreservice.ListResponse{
+			Items: []kvstoreservice.ItemResponse{
+				{Key: "user:1", Value: "1"},
+				{Key: "user:2", Value: "2"},
+			},
+			Next: "user:2",
+		},
+	}
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(service),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/?prefix=user:&start=user:0&end=user:9&limit=2", nil)
+	w := httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	want := kvstoreservice.ListRequest{Prefix: "user:", Start: "user:0", End: "user:9", Limit: 2}
+	if *service.listRequest != want {
+		t.Errorf("wrong list request, want: %+v, got: %+v", want, *service.listRequest)
+	}
+
+	cursor := w.Header().Get(kvstorehandler.NextCursorHeader)
+	if cursor == "" {
+		t.Fatal("next cursor header is missing")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?prefix=user:&limit=2&cursor="+cursor, nil)
+	w = httptest.NewRecorder()
+
+	handler.List(w, req)
+
+	if service.listRequest.After != "user:2" {
+		t.Errorf("wrong after, want: user:2, got: %s", service.listRequest.After)
+	}
+}
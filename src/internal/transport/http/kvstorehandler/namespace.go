@@ -0,0 +1,148 @@
+package kvstorehandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+// namespacePathPrefix is the path segment Namespaces is mounted under.
+const namespacePathPrefix = "/ns/"
+
+// Namespaces serves namespace operations and key operations of a namespace:
+//
+//	GET    .../ns/                  list namespaces (allowed ones when authenticated)
+//	POST   .../ns/                  create namespace
+//	DELETE .../ns/{namespace}/      drop namespace
+//	*      .../ns/{namespace}/{op}/ set, get, update, delete, list, txn, batch, watch
+func (h *kvstoreHandler) Namespaces(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if _, after, ok := strings.Cut(path, namespacePathPrefix); ok {
+		path = after
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case segments[0] == "":
+		h.namespaceCollection(w, r)
+	case len(segments) == 1:
+		h.dropNamespace(w, r, segments[0])
+	case len(segments) == 2:
+		nsHandler := &kvstoreHandler{
+			Handler: h.Handler,
+			service: h.service.Namespace(segments[0]),
+		}
+
+		switch segments[1] {
+		case "set":
+			nsHandler.Set(w, r)
+		case "get":
+			nsHandler.Get(w, r)
+		case "update":
+			nsHandler.Update(w, r)
+		case "delete":
+			nsHandler.Delete(w, r)
+		case "list":
+			nsHandler.List(w, r)
+		case "txn":
+			nsHandler.Txn(w, r)
+		case "batch":
+			nsHandler.Batch(w, r)
+		case "watch":
+			nsHandler.Watch(w, r)
+		default:
+			h.Error(w, r, basehttphandler.NotFound("unknown operation '"+segments[1]+"'"))
+		}
+	default:
+		h.Error(w, r, basehttphandler.NotFound("not found"))
+	}
+}
+
+func (h *kvstoreHandler) namespaceCollection(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
+
+	switch r.Method {
+	case http.MethodGet:
+		names, err := h.service.ListNamespaces(ctx)
+		if err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		if principal, ok := auth.FromContext(r.Context()); ok {
+			allowed := []string{}
+			for _, name := range names {
+				if principal.AllowsNamespace(name) {
+					allowed = append(allowed, name)
+				}
+			}
+			names = allowed
+		}
+
+		h.JSON(
+			w,
+			http.StatusOK,
+			NamespaceListResponse{Namespaces: names},
+		)
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.Error(w, r, basehttphandler.BadRequest(err.Error()))
+			return
+		}
+
+		if len(body) == 0 {
+			h.Error(w, r, basehttphandler.BadRequest("empty body/payload"))
+			return
+		}
+
+		var handlerRequest CreateNamespaceRequest
+		if err = json.Unmarshal(body, &handlerRequest); err != nil {
+			h.Error(w, r, basehttphandler.BadRequest(err.Error()))
+			return
+		}
+
+		if handlerRequest.Name == "" {
+			h.Error(w, r, basehttphandler.BadRequest("name is empty"))
+			return
+		}
+
+		if err = h.service.CreateNamespace(ctx, handlerRequest.Name); err != nil {
+			h.Error(w, r, err)
+			return
+		}
+
+		h.JSON(
+			w,
+			http.StatusCreated,
+			NamespaceResponse{Name: handlerRequest.Name},
+		)
+	default:
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
+	}
+}
+
+func (h *kvstoreHandler) dropNamespace(w http.ResponseWriter, r *http.Request, name string) {
+	if r.Method != http.MethodDelete {
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
+
+	if err := h.service.DropNamespace(ctx, name); err != nil {
+		h.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
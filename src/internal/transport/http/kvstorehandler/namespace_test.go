@@ -0,0 +1,185 @@
+package kvstorehandler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/auth"
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+)
+
+func TestNamespacesList(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			namespaces: []string{"default", "team-a"},
+		}),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ns/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Namespaces(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	shouldEqual := `{"namespaces":["default","team-a"]}`
+	if w.Body.String() != shouldEqual {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
+	}
+}
+
+func TestNamespacesListAllowed(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			namespaces: []string{"default", "team-a", "team-b"},
+		}),
+	)
+	principal := &auth.Principal{Subject: "ci", Namespaces: []string{"team-b"}}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ns/", nil)
+	req = req.WithContext(auth.NewContext(req.Context(), principal))
+	w := httptest.NewRecorder()
+
+	handler.Namespaces(w, req)
+
+	shouldEqual := `{"namespaces":["team-b"]}`
+	if w.Body.String() != shouldEqual {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
+	}
+}
+
+func TestNamespacesInvalidMethod(t *testing.T) {
+	handler := kvstorehandler.New(kvstorehandler.WithService(&mockService{}))
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/ns/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Namespaces(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestNamespacesCreate(t *testing.T) {
+	tests := []struct {
+		payload    string
+		nsErr      error
+		statusCode int
+	}{
+		{"", nil, http.StatusBadRequest},
+		{`{}`, nil, http.StatusBadRequest},
+		{`{"name":"team/a"}`, kverror.ErrInvalidNamespace, http.StatusBadRequest},
+		{`{"name":"team-a"}`, kverror.ErrNamespaceExists, http.StatusConflict},
+		{`{"name":"team-a"}`, context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{`{"name":"team-a"}`, nil, http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		handler := kvstorehandler.New(
+			kvstorehandler.WithContextTimeout(time.Second),
+			kvstorehandler.WithService(&mockService{nsErr: tc.nsErr}),
+			kvstorehandler.WithLogger(logger),
+		)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/ns/", strings.NewReader(tc.payload))
+		w := httptest.NewRecorder()
+
+		handler.Namespaces(w, req)
+
+		if w.Code != tc.statusCode {
+			t.Errorf("wrong status code, want: %d, got: %d", tc.statusCode, w.Code)
+		}
+	}
+}
+
+func TestNamespacesDrop(t *testing.T) {
+	tests := []struct {
+		method     string
+		nsErr      error
+		statusCode int
+	}{
+		{http.MethodGet, nil, http.StatusMethodNotAllowed},
+		{http.MethodDelete, kverror.ErrNamespaceNotFound, http.StatusNotFound},
+		{http.MethodDelete, kverror.ErrInvalidNamespace, http.StatusBadRequest},
+		{http.MethodDelete, nil, http.StatusNoContent},
+	}
+
+	for _, tc := range tests {
+		handler := kvstorehandler.New(
+			kvstorehandler.WithService(&mockService{nsErr: tc.nsErr}),
+			kvstorehandler.WithLogger(logger),
+		)
+		req := httptest.NewRequest(tc.method, "/api/v1/ns/team-a/", nil)
+		w := httptest.NewRecorder()
+
+		handler.Namespaces(w, req)
+
+		if w.Code != tc.statusCode {
+			t.Errorf("wrong status code, want: %d, got: %d", tc.statusCode, w.Code)
+		}
+	}
+}
+
+func TestNamespacesKeyOperation(t *testing.T) {
+	service := &mockService{
+		getResponse: &kvstoreservice.ItemResponse{
+			Key:   "key",
+			Value: "value",
+		},
+	}
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(service),
+		kvstorehandler.WithLogger(logger),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ns/team-a/get/?key=key", nil)
+	w := httptest.NewRecorder()
+
+	handler.Namespaces(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	if service.namespace != "team-a" {
+		t.Errorf("wrong namespace, want: team-a, got: %s", service.namespace)
+	}
+}
+
+func TestNamespacesKeyOperationNamespaceNotFound(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			getErr: kverror.ErrNamespaceNotFound,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ns/team-a/get/?key=key", nil)
+	w := httptest.NewRecorder()
+
+	handler.Namespaces(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	}
+
+	shouldContain := "namespace not found"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
+func TestNamespacesUnknownOperation(t *testing.T) {
+	handler := kvstorehandler.New(kvstorehandler.WithService(&mockService{}))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ns/team-a/rename/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Namespaces(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusNotFound, w.Code)
+	}
+}
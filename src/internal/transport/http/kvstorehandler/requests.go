@@ -1,13 +1,76 @@
 package kvstorehandler
 
+import "strconv"
+
+// MaxTTL is the largest accepted ttl in seconds (ten years), larger values
+// would overflow time.Duration.
+const MaxTTL = 10 * 365 * 24 * 60 * 60
+
+// ttlError returns why ttl is not valid, empty if it is.
+func ttlError(ttl int64) string {
+	switch {
+	case ttl < 0:
+		return "ttl can not be negative"
+	case ttl > MaxTTL:
+		return "ttl can not be greater than " + strconv.Itoa(MaxTTL)
+	}
+	return ""
+}
+
 // SetRequest is an input payload for creating new k/v item.
 type SetRequest struct {
 	Key   string `json:"key"`
 	Value any    `json:"value"`
+	TTL   int64  `json:"ttl,omitempty"` // seconds, zero means never expires
 }
 
 // UpdateRequest is an input payload for updating existing k/v item.
 type UpdateRequest struct {
+	Key     string `json:"key"`
+	Value   any    `json:"value"`
+	TTL     int64  `json:"ttl,omitempty"`     // seconds, zero means never expires
+	Version uint64 `json:"version,omitempty"` // expected version, zero means unconditional
+}
+
+// TxnCompare is a condition of TxnRequest.
+type TxnCompare struct {
+	Key     string `json:"key"`
+	Target  string `json:"target"` // version or value
+	Result  string `json:"result"` // =, !=, > or <
+	Version uint64 `json:"version,omitempty"`
+	Value   any    `json:"value,omitempty"`
+}
+
+// TxnOp is an operation of TxnRequest.
+type TxnOp struct {
+	Op    string `json:"op"` // get, put or delete
 	Key   string `json:"key"`
-	Value any    `json:"value"`
+	Value any    `json:"value,omitempty"`
+	TTL   int64  `json:"ttl,omitempty"` // seconds, zero means never expires
+}
+
+// TxnRequest is an input payload for applying multiple operations atomically.
+type TxnRequest struct {
+	Compare []TxnCompare `json:"compare"`
+	Success []TxnOp      `json:"success"`
+	Failure []TxnOp      `json:"failure"`
+}
+
+// BatchItem is an element of BatchRequest, value and ttl are used by set
+// only.
+type BatchItem struct {
+	Key   string `json:"key"`
+	Value any    `json:"value,omitempty"`
+	TTL   int64  `json:"ttl,omitempty"` // seconds, zero means never expires
+}
+
+// BatchRequest is an input payload for processing many keys in one request.
+type BatchRequest struct {
+	Op    string      `json:"op"` // get, set or delete
+	Items []BatchItem `json:"items"`
+}
+
+// CreateNamespaceRequest is an input payload for creating namespace.
+type CreateNamespaceRequest struct {
+	Name string `json:"name"`
 }
@@ -1,10 +1,74 @@
 package kvstorehandler
 
+import (
+	"time"
+)
+
 // ItemResponse represents k/v item.
 type ItemResponse struct {
-	Key   string `json:"key"`
-	Value any    `json:"value"`
+	Key       string     `json:"key"`
+	Value     any        `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Version   uint64     `json:"version,omitempty"`
 }
 
 // ListResponse represents collection of ItemResponse.
 type ListResponse []ItemResponse
+
+// TxnOpResponse represents result of transaction operation.
+type TxnOpResponse struct {
+	Op      string        `json:"op"`
+	Key     string        `json:"key"`
+	Item    *ItemResponse `json:"item,omitempty"`
+	Deleted bool          `json:"deleted,omitempty"`
+}
+
+// TxnResponse represents result of transaction.
+type TxnResponse struct {
+	Succeeded bool            `json:"succeeded"`
+	Revision  uint64          `json:"revision"`
+	Results   []TxnOpResponse `json:"results"`
+}
+
+// expiresAt converts service expiration time to response field, nil means
+// never expires.
+func expiresAt(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// BatchItemResponse represents per-key result of batch with its own status
+// code, failed items also have error code and message.
+type BatchItemResponse struct {
+	Key    string        `json:"key"`
+	Status int           `json:"status"`
+	Item   *ItemResponse `json:"item,omitempty"`
+	Code   string        `json:"code,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// BatchResponse represents result of batch, results are in request order.
+type BatchResponse struct {
+	Results []BatchItemResponse `json:"results"`
+}
+
+// NamespaceResponse represents namespace.
+type NamespaceResponse struct {
+	Name string `json:"name"`
+}
+
+// NamespaceListResponse represents collection of namespace names.
+type NamespaceListResponse struct {
+	Namespaces []string `json:"namespaces"`
+}
+
+// WatchEventResponse represents data of a watch server-sent event.
+type WatchEventResponse struct {
+	Type     string        `json:"type"`
+	Key      string        `json:"key,omitempty"`
+	Revision uint64        `json:"revision"`
+	Item     *ItemResponse `json:"item,omitempty"`
+	PrevItem *ItemResponse `json:"prev_item,omitempty"`
+}
@@ -6,65 +6,48 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 )
 
 func (h *kvstoreHandler) Set(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		h.JSON(
-			w,
-			http.StatusMethodNotAllowed,
-			map[string]string{"error": "method " + r.Method + " not allowed"},
-		)
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": err.Error()},
-		)
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
 		return
 	}
 
 	if len(body) == 0 {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": "empty body/payload"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("empty body/payload"))
 		return
 	}
 
 	var handlerRequest SetRequest
 	if err = json.Unmarshal(body, &handlerRequest); err != nil {
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
 		return
 	}
 
 	if handlerRequest.Key == "" {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": "key is empty"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("key is empty"))
 		return
 	}
 
 	if handlerRequest.Value == nil {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": "value is empty"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("value is empty"))
+		return
+	}
+
+	if message := ttlError(handlerRequest.TTL); message != "" {
+		h.Error(w, r, basehttphandler.BadRequest(message))
 		return
 	}
 
@@ -72,90 +55,43 @@ func (h *kvstoreHandler) Set(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	existingItem, err := h.service.Get(ctx, handlerRequest.Key)
-	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			h.JSON(
-				w,
-				http.StatusGatewayTimeout,
-				map[string]string{"error": err.Error()},
-			)
-			return
-		}
-
-		var kvErr *kverror.Error
-		if errors.As(err, &kvErr) {
-			clientMessage := kvErr.Message
-			if kvErr.Data != nil {
-				data, ok := kvErr.Data.(string)
-				if ok {
-					clientMessage = clientMessage + ", " + data
-				}
-			}
-
-			if kvErr.Loggable {
-				h.Logger.Error("kvstorehandler Set service.Get", "err", clientMessage)
-			}
-
-			if kvErr != kverror.ErrKeyNotFound {
-				h.JSON(
-					w,
-					http.StatusBadRequest,
-					map[string]string{"error": clientMessage},
-				)
-				return
-			}
-		}
+	if err != nil && !errors.Is(err, kverror.ErrKeyNotFound) {
+		h.Error(w, r, err)
+		return
 	}
 
 	// this should be nil. means, key does not exist
 	if existingItem != nil {
-		h.JSON(
-			w,
-			http.StatusConflict,
-			map[string]string{"error": "can not set, '" + handlerRequest.Key + "' already exists"},
-		)
+		h.Error(w, r, kverror.New(
+			kverror.ErrKeyExists,
+			"set",
+			handlerRequest.Key,
+			"'"+handlerRequest.Key+"' already exist",
+		))
 		return
 	}
 
 	serviceRequest := kvstoreservice.SetRequest{
 		Key:   handlerRequest.Key,
 		Value: handlerRequest.Value,
+		TTL:   time.Duration(handlerRequest.TTL) * time.Second,
 	}
 
 	serviceResponse, err := h.service.Set(ctx, &serviceRequest)
 	if err != nil {
-		var kvErr *kverror.Error
-
-		if errors.As(err, &kvErr) {
-			clientMessage := kvErr.Message
-			if kvErr.Data != nil {
-				data, ok := kvErr.Data.(string)
-				if ok {
-					clientMessage = clientMessage + ", " + data
-				}
-			}
-
-			if kvErr.Loggable {
-				h.Logger.Error("kvstorehandler Set service.Set", "err", clientMessage)
-			}
-
-			if kvErr == kverror.ErrKeyExists {
-				h.JSON(w, http.StatusConflict, map[string]string{"error": clientMessage})
-				return
-			}
-		}
-
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
+		h.Error(w, r, err)
 		return
 	}
 
+	if serviceResponse.Version > 0 {
+		w.Header().Set("ETag", formatETag(serviceResponse.Version))
+	}
+
 	handlerResponse := ItemResponse{
-		Key:   serviceResponse.Key,
-		Value: serviceResponse.Value,
+		Key:       serviceResponse.Key,
+		Value:     serviceResponse.Value,
+		ExpiresAt: expiresAt(serviceResponse.ExpiresAt),
+		Version:   serviceResponse.Version,
 	}
 
 	h.JSON(
@@ -59,8 +59,8 @@ func TestSetBodyUnmarshal(t *testing.T) {
 
 	handler.Set(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusInternalServerError, w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
 	}
 }
 
@@ -119,6 +119,44 @@ func TestSetValueIsEmpty(t *testing.T) {
 	}
 }
 
+func TestSetNegativeTTL(t *testing.T) {
+	handler := kvstorehandler.New()
+
+	payload := strings.NewReader(`{"key":"test","value":"test","ttl":-1}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Set(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "ttl can not be negative"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
+func TestSetTooLargeTTL(t *testing.T) {
+	handler := kvstorehandler.New()
+
+	payload := strings.NewReader(`{"key":"test","value":"test","ttl":9223372037}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Set(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "ttl can not be greater than"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
 func TestSetTimeout(t *testing.T) {
 	handler := kvstorehandler.New(
 		kvstorehandler.WithContextTimeout(time.Second*-1),
@@ -170,7 +208,7 @@ func TestSetErrUnknown(t *testing.T) {
 func TestSetServiceUnknownError(t *testing.T) {
 	handler := kvstorehandler.New(
 		kvstorehandler.WithService(&mockService{
-			getErr: kverror.ErrUnknown.AddData("fake error"),
+			getErr: kverror.New(kverror.ErrUnknown, "get", "test", "fake error"),
 		}),
 		kvstorehandler.WithLogger(logger),
 	)
@@ -181,8 +219,8 @@ func TestSetServiceUnknownError(t *testing.T) {
 
 	handler.Set(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusInternalServerError, w.Code)
 	}
 }
 
@@ -210,11 +248,9 @@ func TestSetServiceNilExistingItem(t *testing.T) {
 }
 
 func TestSetErrKeyExists(t *testing.T) {
-	_ = kverror.ErrKeyExists.AddData("key=test") // ignore error.
-
 	handler := kvstorehandler.New(
 		kvstorehandler.WithService(&mockService{
-			setErr: kverror.ErrKeyExists,
+			setErr: kverror.New(kverror.ErrKeyExists, "set", "test", "key=test"),
 		}),
 		kvstorehandler.WithLogger(logger),
 	)
@@ -238,8 +274,6 @@ func TestSetErrKeyExists(t *testing.T) {
 	if !strings.Contains(w.Body.String(), shouldContain) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
 	}
-
-	_ = kverror.ErrKeyExists.DestoryData() // ignore error.
 }
 
 func TestSetSuccess(t *testing.T) {
@@ -269,3 +303,32 @@ func TestSetSuccess(t *testing.T) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
 	}
 }
+
+func TestSetSuccessWithTTL(t *testing.T) {
+	expiresAt := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	handler := kvstorehandler.New(
+		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithService(&mockService{
+			setResponse: &kvstoreservice.ItemResponse{
+				Key:       "test",
+				Value:     "test",
+				ExpiresAt: expiresAt,
+			},
+		}),
+	)
+
+	payload := strings.NewReader(`{"key":"test","value":"test","ttl":60}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Set(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusCreated, w.Code)
+	}
+
+	shouldEqual := `{"key":"test","value":"test","expires_at":"2023-09-01T12:00:00Z"}`
+	if w.Body.String() != shouldEqual {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
+	}
+}
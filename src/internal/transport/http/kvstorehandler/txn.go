@@ -0,0 +1,112 @@
+package kvstorehandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+func (h *kvstoreHandler) Txn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
+		return
+	}
+
+	if len(body) == 0 {
+		h.Error(w, r, basehttphandler.BadRequest("empty body/payload"))
+		return
+	}
+
+	var handlerRequest TxnRequest
+	if err = json.Unmarshal(body, &handlerRequest); err != nil {
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
+		return
+	}
+
+	if len(handlerRequest.Success) == 0 && len(handlerRequest.Failure) == 0 {
+		h.Error(w, r, basehttphandler.BadRequest("success and failure operations are empty"))
+		return
+	}
+
+	serviceRequest := kvstoreservice.TxnRequest{
+		Compares: make([]kvstoreservice.TxnCompare, len(handlerRequest.Compare)),
+	}
+	for i, c := range handlerRequest.Compare {
+		serviceRequest.Compares[i] = kvstoreservice.TxnCompare{
+			Key:     c.Key,
+			Target:  c.Target,
+			Result:  c.Result,
+			Version: c.Version,
+			Value:   c.Value,
+		}
+	}
+
+	for _, op := range append(handlerRequest.Success, handlerRequest.Failure...) {
+		if message := ttlError(op.TTL); message != "" {
+			h.Error(w, r, basehttphandler.BadRequest(message))
+			return
+		}
+	}
+	serviceRequest.Success = txnOps(handlerRequest.Success)
+	serviceRequest.Failure = txnOps(handlerRequest.Failure)
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
+
+	serviceResponse, err := h.service.Txn(ctx, &serviceRequest)
+	if err != nil {
+		h.Error(w, r, err)
+		return
+	}
+
+	handlerResponse := TxnResponse{
+		Succeeded: serviceResponse.Succeeded,
+		Revision:  serviceResponse.Revision,
+		Results:   make([]TxnOpResponse, len(serviceResponse.Results)),
+	}
+	for i, result := range serviceResponse.Results {
+		handlerResponse.Results[i] = TxnOpResponse{
+			Op:      result.Type,
+			Key:     result.Key,
+			Deleted: result.Deleted,
+		}
+		if result.Item != nil {
+			handlerResponse.Results[i].Item = &ItemResponse{
+				Key:       result.Item.Key,
+				Value:     result.Item.Value,
+				ExpiresAt: expiresAt(result.Item.ExpiresAt),
+				Version:   result.Item.Version,
+			}
+		}
+	}
+
+	h.JSON(
+		w,
+		http.StatusOK,
+		handlerResponse,
+	)
+}
+
+func txnOps(ops []TxnOp) []kvstoreservice.TxnOp {
+	serviceOps := make([]kvstoreservice.TxnOp, len(ops))
+	for i, op := range ops {
+		serviceOps[i] = kvstoreservice.TxnOp{
+			Type:  op.Op,
+			Key:   op.Key,
+			Value: op.Value,
+			TTL:   time.Duration(op.TTL) * time.Second,
+		}
+	}
+	return serviceOps
+}
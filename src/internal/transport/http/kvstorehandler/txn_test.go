@@ -0,0 +1,191 @@
+package kvstorehandler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+)
+
+func TestTxnInvalidMethod(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestTxnBodyReadError(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodPost, "/", &errorReader{})
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestTxnEmptyBody(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestTxnNoOperations(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"compare":[]}`))
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "success and failure operations are empty"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
+func TestTxnNegativeTTL(t *testing.T) {
+	handler := kvstorehandler.New()
+	payload := strings.NewReader(`{"success":[{"op":"put","key":"a","value":"1","ttl":-1}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestTxnTooLargeTTL(t *testing.T) {
+	handler := kvstorehandler.New()
+	payload := strings.NewReader(`{"success":[{"op":"put","key":"a","value":"1","ttl":9223372037}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestTxnTimeout(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithContextTimeout(time.Second*-1),
+		kvstorehandler.WithService(&mockService{
+			txnErr: context.DeadlineExceeded,
+		}),
+	)
+
+	payload := strings.NewReader(`{"success":[{"op":"get","key":"a"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusGatewayTimeout, w.Code)
+	}
+}
+
+func TestTxnErrInvalidTxn(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			txnErr: kverror.ErrInvalidTxn,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	payload := strings.NewReader(`{"success":[{"op":"rename","key":"a"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "invalid transaction"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
+func TestTxnErrUnknown(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			txnErr: kverror.ErrUnknown,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	payload := strings.NewReader(`{"success":[{"op":"get","key":"a"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestTxnSuccess(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithService(&mockService{
+			txnResponse: &kvstoreservice.TxnResponse{
+				Succeeded: true,
+				Revision:  7,
+				Results: []kvstoreservice.TxnOpResponse{
+					{Type: "put", Key: "a", Item: &kvstoreservice.ItemResponse{Key: "a", Value: "1", Version: 7}},
+					{Type: "delete", Key: "b", Deleted: true},
+				},
+			},
+		}),
+	)
+
+	payload := strings.NewReader(`{
+		"compare":[{"key":"a","target":"version","result":"=","version":0}],
+		"success":[{"op":"put","key":"a","value":"1"},{"op":"delete","key":"b"}]
+	}`)
+	req := httptest.NewRequest(http.MethodPost, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Txn(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	shouldEqual := `{"succeeded":true,"revision":7,"results":[` +
+		`{"op":"put","key":"a","item":{"key":"a","value":"1","version":7}},` +
+		`{"op":"delete","key":"b","deleted":true}]}`
+	if w.Body.String() != shouldEqual {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
+	}
+}
@@ -3,122 +3,87 @@ package kvstorehandler
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"io"
 	"net/http"
+	"time"
 
-	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 )
 
 func (h *kvstoreHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
-		h.JSON(
-			w,
-			http.StatusMethodNotAllowed,
-			map[string]string{"error": "method " + r.Method + " not allowed"},
-		)
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": err.Error()},
-		)
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
 		return
 	}
 
 	if len(body) == 0 {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": "empty body/payload"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("empty body/payload"))
 		return
 	}
 
 	var handlerRequest UpdateRequest
 	if err = json.Unmarshal(body, &handlerRequest); err != nil {
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
+		h.Error(w, r, basehttphandler.BadRequest(err.Error()))
 		return
 	}
 
 	if handlerRequest.Key == "" {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": "key is empty"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("key is empty"))
 		return
 	}
 
 	if handlerRequest.Value == nil {
-		h.JSON(
-			w,
-			http.StatusBadRequest,
-			map[string]string{"error": "value is empty"},
-		)
+		h.Error(w, r, basehttphandler.BadRequest("value is empty"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
-	defer cancel()
+	if message := ttlError(handlerRequest.TTL); message != "" {
+		h.Error(w, r, basehttphandler.BadRequest(message))
+		return
+	}
 
 	serviceRequest := kvstoreservice.UpdateRequest{
-		Key:   handlerRequest.Key,
-		Value: handlerRequest.Value,
+		Key:     handlerRequest.Key,
+		Value:   handlerRequest.Value,
+		TTL:     time.Duration(handlerRequest.TTL) * time.Second,
+		Version: handlerRequest.Version,
 	}
 
-	serviceResponse, err := h.service.Update(ctx, &serviceRequest)
-	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			h.JSON(
-				w,
-				http.StatusGatewayTimeout,
-				map[string]string{"error": err.Error()},
-			)
+	ifMatchHeader := r.Header.Get("If-Match")
+	if ifMatchHeader != "" {
+		version, errr := parseIfMatch(ifMatchHeader)
+		if errr != nil {
+			h.Error(w, r, ifMatchError(errr))
 			return
 		}
+		serviceRequest.Version = version
+	}
 
-		var kvErr *kverror.Error
-
-		if errors.As(err, &kvErr) {
-			clientMessage := kvErr.Message
-			if kvErr.Data != nil {
-				data, ok := kvErr.Data.(string)
-				if ok {
-					clientMessage = clientMessage + ", " + data
-				}
-			}
-
-			if kvErr.Loggable {
-				h.Logger.Error("kvstorehandler Update service.Update", "err", clientMessage)
-			}
-
-			if kvErr == kverror.ErrKeyNotFound {
-				h.JSON(w, http.StatusNotFound, map[string]string{"error": clientMessage})
-				return
-			}
-		}
+	ctx, cancel := context.WithTimeout(r.Context(), h.CancelTimeout)
+	defer cancel()
 
-		h.JSON(
-			w,
-			http.StatusInternalServerError,
-			map[string]string{"error": err.Error()},
-		)
+	serviceResponse, err := h.service.Update(ctx, &serviceRequest)
+	if err != nil {
+		h.Error(w, r, preconditionError(ifMatchHeader, err))
 		return
 	}
 
+	if serviceResponse.Version > 0 {
+		w.Header().Set("ETag", formatETag(serviceResponse.Version))
+	}
+
 	handlerResponse := ItemResponse{
-		Key:   serviceResponse.Key,
-		Value: serviceResponse.Value,
+		Key:       serviceResponse.Key,
+		Value:     serviceResponse.Value,
+		ExpiresAt: expiresAt(serviceResponse.ExpiresAt),
+		Version:   serviceResponse.Version,
 	}
 
 	h.JSON(
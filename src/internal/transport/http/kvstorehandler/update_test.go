@@ -3,6 +3,7 @@ package kvstorehandler_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/vbyazilim/kvstore/src/internal/kverror"
 	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
 	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
 )
 
@@ -69,8 +71,8 @@ func TestUpdateBodyUnmarshal(t *testing.T) {
 
 	handler.Update(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("wrong status code, want: %d, got: %d", http.StatusInternalServerError, w.Code)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
 	}
 }
 
@@ -112,6 +114,44 @@ func TestUpdateValueIsEmpty(t *testing.T) {
 	}
 }
 
+func TestUpdateNegativeTTL(t *testing.T) {
+	handler := kvstorehandler.New()
+
+	payload := strings.NewReader(`{"key":"test","value":"test","ttl":-1}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "ttl can not be negative"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
+func TestUpdateTooLargeTTL(t *testing.T) {
+	handler := kvstorehandler.New()
+
+	payload := strings.NewReader(`{"key":"test","value":"test","ttl":9223372037}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	shouldContain := "ttl can not be greater than"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
+}
+
 func TestUpdateTimeout(t *testing.T) {
 	handler := kvstorehandler.New(
 		kvstorehandler.WithContextTimeout(time.Second*-1),
@@ -161,11 +201,9 @@ func TestUpdateErrUnknown(t *testing.T) {
 }
 
 func TestUpdateErrKeyExists(t *testing.T) {
-	_ = kverror.ErrKeyNotFound.AddData("key=test") // ignore return no need
-
 	handler := kvstorehandler.New(
 		kvstorehandler.WithService(&mockService{
-			updateErr: kverror.ErrKeyNotFound,
+			updateErr: kverror.New(kverror.ErrKeyNotFound, "update", "test", "key=test"),
 		}),
 		kvstorehandler.WithLogger(logger),
 	)
@@ -189,8 +227,30 @@ func TestUpdateErrKeyExists(t *testing.T) {
 	if !strings.Contains(w.Body.String(), shouldContain) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
 	}
+}
+
+func TestUpdateErrVersionMismatch(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			updateErr: kverror.ErrVersionMismatch,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	payload := strings.NewReader(`{"key":"test","value":"test","version":3}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusConflict, w.Code)
+	}
 
-	_ = kverror.ErrKeyNotFound.DestoryData() // ignore error
+	shouldContain := "version mismatch"
+	if !strings.Contains(w.Body.String(), shouldContain) {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldContain, w.Body.String())
+	}
 }
 
 func TestUpdateSuccess(t *testing.T) {
@@ -220,3 +280,95 @@ func TestUpdateSuccess(t *testing.T) {
 		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
 	}
 }
+
+func TestUpdateIfMatchInvalid(t *testing.T) {
+	handler := kvstorehandler.New()
+
+	payload := strings.NewReader(`{"key":"test","value":"test"}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	req.Header.Set("If-Match", `"1", "2"`)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestUpdateIfMatchPreconditionFailed(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			updateErr: kverror.ErrVersionMismatch,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	payload := strings.NewReader(`{"key":"test","value":"test"}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	req.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusPreconditionFailed, w.Code)
+	}
+}
+
+func TestUpdateIfMatchWeakETag(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			updateResponse: &kvstoreservice.ItemResponse{Key: "test", Value: "test", Version: 4},
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+
+	payload := strings.NewReader(`{"key":"test","value":"test"}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	req.Header.Set("If-Match", `W/"3"`)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusPreconditionFailed, w.Code)
+	}
+
+	var p basehttphandler.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if p.Code != "version_mismatch" {
+		t.Errorf("wrong code, want: %s, got: %s", "version_mismatch", p.Code)
+	}
+}
+
+func TestUpdateIfMatchSuccess(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithLogger(logger),
+		kvstorehandler.WithService(&mockService{
+			updateResponse: &kvstoreservice.ItemResponse{
+				Key:     "test",
+				Value:   "test",
+				Version: 4,
+			},
+		}),
+	)
+
+	payload := strings.NewReader(`{"key":"test","value":"test"}`)
+	req := httptest.NewRequest(http.MethodPut, "/", payload)
+	req.Header.Set("If-Match", `"3"`)
+	w := httptest.NewRecorder()
+
+	handler.Update(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	shouldEqual := `"4"`
+	if w.Header().Get("ETag") != shouldEqual {
+		t.Errorf("wrong etag, want: %s, got: %s", shouldEqual, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,146 @@
+package kvstorehandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/basehttphandler"
+)
+
+// WatchHeartbeatInterval is the interval of keep-alive comments sent to idle
+// watch streams.
+const WatchHeartbeatInterval = 15 * time.Second
+
+// Watch streams changes of key or prefix as server-sent events. Event id is
+// the revision, reconnecting clients resume via Last-Event-ID header or
+// revision query param.
+func (h *kvstoreHandler) Watch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.Error(w, r, basehttphandler.MethodNotAllowed(r.Method))
+		return
+	}
+
+	query := r.URL.Query()
+
+	if query.Has("key") == query.Has("prefix") {
+		h.Error(w, r, basehttphandler.BadRequest("either key or prefix query param required"))
+		return
+	}
+
+	serviceRequest := kvstoreservice.WatchRequest{
+		Key:    query.Get("key"),
+		Prefix: query.Get("prefix"),
+	}
+
+	if query.Has("key") && serviceRequest.Key == "" {
+		h.Error(w, r, basehttphandler.BadRequest("key is empty"))
+		return
+	}
+
+	if revision := query.Get("revision"); revision != "" {
+		v, err := strconv.ParseUint(revision, 10, 64)
+		if err != nil {
+			h.Error(w, r, basehttphandler.BadRequest("invalid revision"))
+			return
+		}
+		serviceRequest.Revision = v
+	}
+
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		v, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			h.Error(w, r, basehttphandler.BadRequest("invalid Last-Event-ID"))
+			return
+		}
+		serviceRequest.Revision = v + 1
+	}
+
+	events, err := h.service.Watch(r.Context(), &serviceRequest)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		h.Error(w, r, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{}) // stream outlives server write timeout
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err = rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(WatchHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, errMarshal := json.Marshal(watchEventResponse(event))
+			if errMarshal != nil {
+				h.Logger.ErrorContext(r.Context(), "kvstorehandler Watch json.Marshal", "err", errMarshal)
+				return
+			}
+
+			if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, event.Type, data); err != nil {
+				return
+			}
+		}
+
+		if err = rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func watchEventResponse(event kvstoreservice.WatchEvent) WatchEventResponse {
+	response := WatchEventResponse{
+		Type:     event.Type,
+		Key:      event.Key,
+		Revision: event.Revision,
+	}
+
+	if event.Item != nil {
+		response.Item = &ItemResponse{
+			Key:       event.Item.Key,
+			Value:     event.Item.Value,
+			ExpiresAt: expiresAt(event.Item.ExpiresAt),
+			Version:   event.Item.Version,
+		}
+	}
+
+	if event.PrevItem != nil {
+		response.PrevItem = &ItemResponse{
+			Key:       event.PrevItem.Key,
+			Value:     event.PrevItem.Value,
+			ExpiresAt: expiresAt(event.PrevItem.ExpiresAt),
+			Version:   event.PrevItem.Version,
+		}
+	}
+
+	return response
+}
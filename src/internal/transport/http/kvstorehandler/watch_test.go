@@ -0,0 +1,119 @@
+package kvstorehandler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+)
+
+func TestWatchInvalidMethod(t *testing.T) {
+	handler := kvstorehandler.New()
+	req := httptest.NewRequest(http.MethodPost, "/?key=key", nil)
+	w := httptest.NewRecorder()
+
+	handler.Watch(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestWatchInvalidQuery(t *testing.T) {
+	tests := []struct {
+		target        string
+		lastEventID   string
+		shouldContain string
+	}{
+		{"/", "", "either key or prefix query param required"},
+		{"/?key=a&prefix=b", "", "either key or prefix query param required"},
+		{"/?key=", "", "key is empty"},
+		{"/?key=a&revision=abc", "", "invalid revision"},
+		{"/?key=a", "abc", "invalid Last-Event-ID"},
+	}
+
+	for _, tc := range tests {
+		handler := kvstorehandler.New()
+		req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+		if tc.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", tc.lastEventID)
+		}
+		w := httptest.NewRecorder()
+
+		handler.Watch(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("wrong status code, want: %d, got: %d", http.StatusBadRequest, w.Code)
+		}
+
+		if !strings.Contains(w.Body.String(), tc.shouldContain) {
+			t.Errorf("wrong body message, want: %s, got: %s", tc.shouldContain, w.Body.String())
+		}
+	}
+}
+
+func TestWatchErrRevisionCompacted(t *testing.T) {
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(&mockService{
+			watchErr: kverror.ErrRevisionCompacted,
+		}),
+		kvstorehandler.WithLogger(logger),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/?prefix=&revision=1", nil)
+	w := httptest.NewRecorder()
+
+	handler.Watch(w, req)
+
+	if w.Code != http.StatusGone {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusGone, w.Code)
+	}
+}
+
+func TestWatchStream(t *testing.T) {
+	events := make(chan kvstoreservice.WatchEvent, 2)
+	events <- kvstoreservice.WatchEvent{
+		Type:     "put",
+		Key:      "config",
+		Revision: 5,
+		Item:     &kvstoreservice.ItemResponse{Key: "config", Value: "v2", Version: 5},
+		PrevItem: &kvstoreservice.ItemResponse{Key: "config", Value: "v1", Version: 3},
+	}
+	events <- kvstoreservice.WatchEvent{Type: "delete", Key: "config", Revision: 6}
+	close(events)
+
+	service := &mockService{watchEvents: events}
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(service),
+		kvstorehandler.WithLogger(logger),
+	)
+	req := httptest.NewRequest(http.MethodGet, "/?key=config&revision=1", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	w := httptest.NewRecorder()
+
+	handler.Watch(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("wrong status code, want: %d, got: %d", http.StatusOK, w.Code)
+	}
+
+	if service.watchRequest.Revision != 5 {
+		t.Errorf("Last-Event-ID should win, want: revision 5, got: %d", service.watchRequest.Revision)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("wrong content type, want: text/event-stream, got: %s", ct)
+	}
+
+	shouldEqual := "id: 5\nevent: put\n" +
+		`data: {"type":"put","key":"config","revision":5,"item":{"key":"config","value":"v2","version":5},"prev_item":{"key":"config","value":"v1","version":3}}` +
+		"\n\nid: 6\nevent: delete\n" +
+		`data: {"type":"delete","key":"config","revision":6}` +
+		"\n\n"
+	if w.Body.String() != shouldEqual {
+		t.Errorf("wrong body message, want: %s, got: %s", shouldEqual, w.Body.String())
+	}
+}
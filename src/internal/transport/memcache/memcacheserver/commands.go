@@ -0,0 +1,589 @@
+package memcacheserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/releaseinfo"
+)
+
+const noreply = "noreply"
+
+// commandFunc runs a command, returned error means the connection can not
+// be used anymore.
+type commandFunc func(context.Context, *conn, []string) error
+
+var commands = map[string]commandFunc{
+	"get":       cmdGet,
+	"gets":      cmdGet,
+	"set":       cmdStore,
+	"add":       cmdStore,
+	"replace":   cmdStore,
+	"cas":       cmdStore,
+	"delete":    cmdDelete,
+	"incr":      cmdIncrDecr,
+	"decr":      cmdIncrDecr,
+	"touch":     cmdTouch,
+	"flush_all": cmdFlushAll,
+	"stats":     cmdStats,
+	"version":   cmdVersion,
+	"verbosity": cmdVerbosity,
+	"quit":      cmdQuit,
+}
+
+type stats struct {
+	cmdGet           atomic.Uint64
+	cmdSet           atomic.Uint64
+	cmdTouch         atomic.Uint64
+	cmdFlush         atomic.Uint64
+	getHits          atomic.Uint64
+	getMisses        atomic.Uint64
+	totalConnections atomic.Uint64
+}
+
+// serviceError replies with service error.
+func (c *conn) serviceError(command string, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.reply("SERVER_ERROR " + err.Error())
+		return
+	}
+
+	if kvErr, ok := kverror.From(err); ok {
+		if kvErr.Code.Loggable() {
+			c.server.logger.Error("memcacheserver "+command, "err", err)
+		}
+		c.reply("SERVER_ERROR " + kvErr.Message())
+		return
+	}
+
+	c.server.logger.Error("memcacheserver "+command, "err", err)
+	c.reply("SERVER_ERROR " + err.Error())
+}
+
+// cmdGet handles get <key>* and gets <key>*, gets also returns cas unique
+// which is the item version.
+func cmdGet(ctx context.Context, c *conn, fields []string) error {
+	if len(fields) < 2 {
+		c.reply("ERROR")
+		return nil
+	}
+
+	items := make([]kvstoreservice.BatchItem, len(fields)-1)
+	for i, key := range fields[1:] {
+		if len(key) > MaxKeyLength {
+			c.reply("CLIENT_ERROR bad command line format")
+			return nil
+		}
+		items[i] = kvstoreservice.BatchItem{Key: key}
+	}
+
+	response, err := c.server.service.Batch(ctx, &kvstoreservice.BatchRequest{
+		Op:    kvstoreservice.BatchOpGet,
+		Items: items,
+	})
+	if err != nil {
+		c.serviceError("get service.Batch", err)
+		return nil
+	}
+
+	var b strings.Builder
+	for _, result := range response.Results {
+		c.server.stats.cmdGet.Add(1)
+
+		if result.Err != nil {
+			if errors.Is(result.Err, kverror.ErrKeyNotFound) {
+				c.server.stats.getMisses.Add(1)
+				continue
+			}
+			c.serviceError("get service.Batch", result.Err)
+			return nil
+		}
+		c.server.stats.getHits.Add(1)
+
+		data, flags := decodeValue(result.Item.Value)
+
+		b.WriteString("VALUE " + result.Key + " " + strconv.FormatUint(uint64(flags), 10) + " " + strconv.Itoa(len(data)))
+		if fields[0] == "gets" {
+			b.WriteString(" " + strconv.FormatUint(result.Item.Version, 10))
+		}
+		b.WriteString("\r\n" + data + "\r\n")
+	}
+	b.WriteString("END")
+
+	c.reply(b.String())
+	return nil
+}
+
+// cmdStore handles <set|add|replace> <key> <flags> <exptime> <bytes>
+// [noreply] and cas <key> <flags> <exptime> <bytes> <cas unique> [noreply]
+// followed by data block. set is a put transaction, add maps to Set,
+// replace maps to Update and cas maps to Update with version.
+func cmdStore(ctx context.Context, c *conn, fields []string) error {
+	name := fields[0]
+
+	n := 5
+	if name == "cas" {
+		n = 6
+	}
+	if len(fields) != n && (len(fields) != n+1 || fields[n] != noreply) {
+		c.reply("ERROR")
+		return nil
+	}
+	quiet := len(fields) == n+1
+
+	size, err := strconv.Atoi(fields[4])
+	if err != nil || size < 0 {
+		c.reply("CLIENT_ERROR bad command line format")
+		return errBadDataChunk // data block can not be skipped
+	}
+
+	if size > MaxItemSize {
+		if _, err = io.CopyN(io.Discard, c.reader, int64(size)+2); err != nil {
+			return err
+		}
+		c.reply("SERVER_ERROR object too large for cache")
+		return nil
+	}
+
+	data, err := readData(c.reader, size)
+	if err != nil {
+		if errors.Is(err, errBadDataChunk) {
+			c.reply("CLIENT_ERROR " + err.Error())
+		}
+		return err
+	}
+
+	key := fields[1]
+	flags, flagsErr := strconv.ParseUint(fields[2], 10, 32)
+	exptime, exptimeErr := strconv.ParseInt(fields[3], 10, 64)
+
+	var casUnique uint64
+	var casErr error
+	if name == "cas" {
+		casUnique, casErr = strconv.ParseUint(fields[5], 10, 64)
+	}
+
+	if !validKey(key) || flagsErr != nil || exptimeErr != nil || casErr != nil {
+		c.reply("CLIENT_ERROR bad command line format")
+		return nil
+	}
+
+	c.server.stats.cmdSet.Add(1)
+
+	value := encodeValue(data, uint32(flags))
+	itemTTL, expired := ttl(exptime)
+	result := "STORED"
+
+	switch {
+	case name == "cas" && casUnique == 0: // zero version means unconditional update
+		result = "EXISTS"
+		if _, err = c.server.service.Get(ctx, key); errors.Is(err, kverror.ErrKeyNotFound) {
+			result, err = "NOT_FOUND", nil
+		}
+	case expired:
+		result, err = storeExpired(ctx, c, name, key, casUnique)
+	case name == "set":
+		_, err = c.server.service.Txn(ctx, &kvstoreservice.TxnRequest{
+			Success: []kvstoreservice.TxnOp{
+				{Type: "put", Key: key, Value: value, TTL: itemTTL},
+			},
+		})
+	case name == "add":
+		_, err = c.server.service.Set(ctx, &kvstoreservice.SetRequest{
+			Key:   key,
+			Value: value,
+			TTL:   itemTTL,
+		})
+		if errors.Is(err, kverror.ErrKeyExists) {
+			result, err = "NOT_STORED", nil
+		}
+	case name == "replace":
+		_, err = c.server.service.Update(ctx, &kvstoreservice.UpdateRequest{
+			Key:   key,
+			Value: value,
+			TTL:   itemTTL,
+		})
+		if errors.Is(err, kverror.ErrKeyNotFound) {
+			result, err = "NOT_STORED", nil
+		}
+	case name == "cas":
+		_, err = c.server.service.Update(ctx, &kvstoreservice.UpdateRequest{
+			Key:     key,
+			Value:   value,
+			TTL:     itemTTL,
+			Version: casUnique,
+		})
+		switch {
+		case errors.Is(err, kverror.ErrVersionMismatch):
+			result, err = "EXISTS", nil
+		case errors.Is(err, kverror.ErrKeyNotFound):
+			result, err = "NOT_FOUND", nil
+		}
+	}
+
+	if quiet {
+		return nil
+	}
+	if err != nil {
+		c.serviceError(name, err)
+		return nil
+	}
+
+	c.reply(result)
+	return nil
+}
+
+// storeExpired handles store command of an item which expires immediately,
+// the key is deleted instead of written (with version for cas), add is not
+// stored.
+func storeExpired(ctx context.Context, c *conn, name, key string, casUnique uint64) (string, error) {
+	if name == "add" {
+		return "NOT_STORED", nil
+	}
+
+	err := c.server.service.Delete(ctx, &kvstoreservice.DeleteRequest{Key: key, Version: casUnique})
+	switch {
+	case err == nil:
+		return "STORED", nil
+	case errors.Is(err, kverror.ErrVersionMismatch):
+		return "EXISTS", nil
+	case errors.Is(err, kverror.ErrKeyNotFound):
+		switch name {
+		case "set":
+			return "STORED", nil
+		case "replace":
+			return "NOT_STORED", nil
+		}
+		return "NOT_FOUND", nil
+	}
+	return "", err
+}
+
+// cmdDelete handles delete <key> [noreply], legacy delete <key> 0 is also
+// accepted.
+func cmdDelete(ctx context.Context, c *conn, fields []string) error {
+	if len(fields) < 2 || len(fields) > 4 {
+		c.reply("ERROR")
+		return nil
+	}
+
+	quiet := fields[len(fields)-1] == noreply
+	if (len(fields) == 3 && !quiet && fields[2] != "0") || (len(fields) == 4 && (!quiet || fields[2] != "0")) {
+		c.reply("CLIENT_ERROR bad command line format. Usage: delete <key> [noreply]")
+		return nil
+	}
+
+	result := "DELETED"
+
+	err := c.server.service.Delete(ctx, &kvstoreservice.DeleteRequest{Key: fields[1]})
+	if errors.Is(err, kverror.ErrKeyNotFound) {
+		result, err = "NOT_FOUND", nil
+	}
+
+	if quiet {
+		return nil
+	}
+	if err != nil {
+		c.serviceError("delete service.Delete", err)
+		return nil
+	}
+
+	c.reply(result)
+	return nil
+}
+
+// cmdIncrDecr handles incr|decr <key> <value> [noreply]. Value is rewritten
+// with a version check so concurrent writes are not lost, incr wraps around
+// at 64 bits, decr stops at zero.
+func cmdIncrDecr(ctx context.Context, c *conn, fields []string) error {
+	if len(fields) != 3 && (len(fields) != 4 || fields[3] != noreply) {
+		c.reply("ERROR")
+		return nil
+	}
+	quiet := len(fields) == 4
+	key := fields[1]
+
+	delta, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		if !quiet {
+			c.reply("CLIENT_ERROR invalid numeric delta argument")
+		}
+		return nil
+	}
+
+	for {
+		item, err := c.server.service.Get(ctx, key)
+		if err != nil {
+			if quiet {
+				return nil
+			}
+			if errors.Is(err, kverror.ErrKeyNotFound) {
+				c.reply("NOT_FOUND")
+				return nil
+			}
+			c.serviceError(fields[0]+" service.Get", err)
+			return nil
+		}
+
+		itemTTL, expired := remainingTTL(item.ExpiresAt)
+		if expired { // expired since read
+			if !quiet {
+				c.reply("NOT_FOUND")
+			}
+			return nil
+		}
+
+		data, flags := decodeValue(item.Value)
+
+		current, err := strconv.ParseUint(data, 10, 64)
+		if err != nil {
+			if !quiet {
+				c.reply("CLIENT_ERROR cannot increment or decrement non-numeric value")
+			}
+			return nil
+		}
+
+		switch {
+		case fields[0] == "incr":
+			current += delta
+		case delta > current:
+			current = 0
+		default:
+			current -= delta
+		}
+		result := strconv.FormatUint(current, 10)
+
+		_, err = c.server.service.Update(ctx, &kvstoreservice.UpdateRequest{
+			Key:     key,
+			Value:   encodeValue(result, flags),
+			TTL:     itemTTL,
+			Version: item.Version,
+		})
+		if errors.Is(err, kverror.ErrVersionMismatch) {
+			continue // changed in between, try again
+		}
+
+		if quiet {
+			return nil
+		}
+
+		switch {
+		case err == nil:
+			c.reply(result)
+		case errors.Is(err, kverror.ErrKeyNotFound):
+			c.reply("NOT_FOUND")
+		default:
+			c.serviceError(fields[0]+" service.Update", err)
+		}
+		return nil
+	}
+}
+
+// cmdTouch handles touch <key> <exptime> [noreply], expired exptime deletes
+// the key.
+func cmdTouch(ctx context.Context, c *conn, fields []string) error {
+	if len(fields) != 3 && (len(fields) != 4 || fields[3] != noreply) {
+		c.reply("ERROR")
+		return nil
+	}
+	quiet := len(fields) == 4
+	key := fields[1]
+
+	exptime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		if !quiet {
+			c.reply("CLIENT_ERROR invalid exptime argument")
+		}
+		return nil
+	}
+
+	c.server.stats.cmdTouch.Add(1)
+
+	itemTTL, expired := ttl(exptime)
+
+	for {
+		item, err := c.server.service.Get(ctx, key)
+		switch {
+		case err == nil && expired:
+			err = c.server.service.Delete(ctx, &kvstoreservice.DeleteRequest{Key: key, Version: item.Version})
+		case err == nil:
+			_, err = c.server.service.Update(ctx, &kvstoreservice.UpdateRequest{
+				Key:     key,
+				Value:   item.Value,
+				TTL:     itemTTL,
+				Version: item.Version,
+			})
+		}
+		if errors.Is(err, kverror.ErrVersionMismatch) {
+			continue // changed in between, try again
+		}
+
+		if quiet {
+			return nil
+		}
+
+		switch {
+		case err == nil:
+			c.reply("TOUCHED")
+		case errors.Is(err, kverror.ErrKeyNotFound):
+			c.reply("NOT_FOUND")
+		default:
+			c.serviceError("touch", err)
+		}
+		return nil
+	}
+}
+
+// cmdFlushAll handles flush_all [delay] [noreply], all keys are deleted
+// now or after delay seconds.
+func cmdFlushAll(ctx context.Context, c *conn, fields []string) error {
+	quiet := fields[len(fields)-1] == noreply
+	if quiet {
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) > 2 {
+		c.reply("ERROR")
+		return nil
+	}
+
+	var delay int64
+	if len(fields) == 2 {
+		var err error
+		if delay, err = strconv.ParseInt(fields[1], 10, 64); err != nil || delay < 0 {
+			if !quiet {
+				c.reply("CLIENT_ERROR bad command line format")
+			}
+			return nil
+		}
+	}
+
+	c.server.stats.cmdFlush.Add(1)
+
+	if d, expired := ttl(delay); delay > 0 && !expired {
+		c.server.scheduleFlush(d)
+		if !quiet {
+			c.reply("OK")
+		}
+		return nil
+	}
+
+	err := c.server.flush(ctx)
+	if quiet {
+		return nil
+	}
+	if err != nil {
+		c.serviceError("flush_all", err)
+		return nil
+	}
+
+	c.reply("OK")
+	return nil
+}
+
+// flush deletes all keys.
+func (s *Server) flush(ctx context.Context) error {
+	list, err := s.service.List(ctx, &kvstoreservice.ListRequest{})
+	if err != nil {
+		return err
+	}
+
+	items := make([]kvstoreservice.BatchItem, len(list.Items))
+	for i, item := range list.Items {
+		items[i] = kvstoreservice.BatchItem{Key: item.Key}
+	}
+
+	_, err = s.service.Batch(ctx, &kvstoreservice.BatchRequest{
+		Op:    kvstoreservice.BatchOpDelete,
+		Items: items,
+	})
+	return err
+}
+
+// scheduleFlush flushes all keys after d, replaces previously scheduled
+// flush.
+func (s *Server) scheduleFlush(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+	}
+
+	s.flushTimer = time.AfterFunc(d, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.cancelTimeout)
+		defer cancel()
+
+		if err := s.flush(ctx); err != nil {
+			s.logger.Error("memcacheserver delayed flush_all", "err", err)
+		}
+	})
+}
+
+// cmdStats handles stats, only general-purpose statistics are supported.
+func cmdStats(ctx context.Context, c *conn, fields []string) error {
+	if len(fields) > 1 {
+		c.reply("ERROR")
+		return nil
+	}
+
+	stats, err := c.server.service.Stats(ctx)
+	if err != nil {
+		c.serviceError("stats service.Stats", err)
+		return nil
+	}
+
+	s := &c.server.stats
+	lines := []struct {
+		name  string
+		value string
+	}{
+		{"pid", strconv.Itoa(os.Getpid())},
+		{"uptime", strconv.FormatInt(int64(time.Since(c.server.started).Seconds()), 10)},
+		{"time", strconv.FormatInt(time.Now().Unix(), 10)},
+		{"version", releaseinfo.Version},
+		{"curr_connections", strconv.Itoa(c.server.connectedClients())},
+		{"total_connections", strconv.FormatUint(s.totalConnections.Load(), 10)},
+		{"cmd_get", strconv.FormatUint(s.cmdGet.Load(), 10)},
+		{"cmd_set", strconv.FormatUint(s.cmdSet.Load(), 10)},
+		{"cmd_flush", strconv.FormatUint(s.cmdFlush.Load(), 10)},
+		{"cmd_touch", strconv.FormatUint(s.cmdTouch.Load(), 10)},
+		{"get_hits", strconv.FormatUint(s.getHits.Load(), 10)},
+		{"get_misses", strconv.FormatUint(s.getMisses.Load(), 10)},
+		{"curr_items", strconv.Itoa(stats.Keys)},
+		{"bytes", strconv.FormatInt(stats.Bytes, 10)},
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString("STAT " + line.name + " " + line.value + "\r\n")
+	}
+	b.WriteString("END")
+
+	c.reply(b.String())
+	return nil
+}
+
+func cmdVersion(_ context.Context, c *conn, _ []string) error {
+	c.reply("VERSION " + releaseinfo.Version)
+	return nil
+}
+
+func cmdVerbosity(_ context.Context, c *conn, fields []string) error {
+	if fields[len(fields)-1] != noreply {
+		c.reply("OK")
+	}
+	return nil
+}
+
+func cmdQuit(_ context.Context, c *conn, _ []string) error {
+	c.quit = true
+	return nil
+}
@@ -0,0 +1,177 @@
+package memcacheserver
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+)
+
+// protocol limits.
+const (
+	MaxLineLength = 64 << 10 // also size of connection read buffer
+	MaxKeyLength  = 250
+	MaxItemSize   = 1 << 20
+
+	// exptime values up to 30 days are relative seconds, unix timestamps
+	// otherwise.
+	maxRelativeExptime = 60 * 60 * 24 * 30
+)
+
+var (
+	errLineTooLong  = errors.New("line too long")
+	errBadDataChunk = errors.New("bad data chunk")
+)
+
+// readLine reads a line terminated by LF or CRLF, terminator is stripped.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errLineTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return string(line), nil
+}
+
+// readData reads data block of storage commands which is terminated by
+// CRLF.
+func readData(r *bufio.Reader, size int) (string, error) {
+	buf := make([]byte, size+2)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	if buf[size] != '\r' || buf[size+1] != '\n' {
+		return "", errBadDataChunk
+	}
+	return string(buf[:size]), nil
+}
+
+// validKey reports whether key is a valid memcached key, up to 250 bytes
+// without control characters and whitespace.
+func validKey(key string) bool {
+	if key == "" || len(key) > MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// ttl converts exptime to ttl, zero means never expires. expired is true
+// for negative exptime and timestamps in the past, such items expire
+// immediately so they are deleted instead of written.
+func ttl(exptime int64) (d time.Duration, expired bool) {
+	switch {
+	case exptime == 0:
+		return 0, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= maxRelativeExptime:
+		return time.Duration(exptime) * time.Second, false
+	}
+
+	return remainingTTL(time.Unix(exptime, 0))
+}
+
+// remainingTTL returns ttl which keeps given expiration time, expired is
+// true if it has already passed.
+func remainingTTL(expiresAt time.Time) (d time.Duration, expired bool) {
+	if expiresAt.IsZero() {
+		return 0, false
+	}
+
+	d = time.Until(expiresAt)
+	if d <= 0 {
+		return 0, true
+	}
+	return d, false
+}
+
+// stored value keys, used only when client flags are not zero.
+const (
+	valueKeyData  = "data"
+	valueKeyBytes = "data_base64" // data which is not valid utf-8
+	valueKeyFlags = "flags"
+)
+
+// encodeValue converts memcached data and client flags to stored value.
+// Data is stored as is when flags are zero, otherwise as
+// {"data": ..., "flags": ...} object so flags survive. Data which is not
+// valid utf-8 is stored as []byte, or base64 encoded in "data_base64" with
+// flags, to survive json persistence.
+func encodeValue(data string, flags uint32) any {
+	binary := !utf8.ValidString(data)
+
+	switch {
+	case flags == 0 && binary:
+		return []byte(data)
+	case flags == 0:
+		return data
+	case binary:
+		return map[string]any{
+			valueKeyBytes: base64.StdEncoding.EncodeToString([]byte(data)),
+			valueKeyFlags: float64(flags),
+		}
+	}
+	return map[string]any{
+		valueKeyData:  data,
+		valueKeyFlags: float64(flags),
+	}
+}
+
+// decodeValue converts stored value to memcached data and client flags,
+// values set over http which are not strings are returned json encoded.
+func decodeValue(v any) (string, uint32) {
+	switch value := v.(type) {
+	case string:
+		return value, 0
+	case []byte:
+		return string(value), 0
+	case map[string]any:
+		if data, flags, ok := decodeFlagged(value); ok {
+			return data, flags
+		}
+	}
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v), 0
+	}
+	return string(j), 0
+}
+
+// decodeFlagged decodes value stored with client flags.
+func decodeFlagged(value map[string]any) (string, uint32, bool) {
+	flags, ok := value[valueKeyFlags].(float64)
+	if len(value) != 2 || !ok || flags < 0 || flags > 1<<32-1 || flags != float64(uint32(flags)) {
+		return "", 0, false
+	}
+
+	if data, ok := value[valueKeyData].(string); ok {
+		return data, uint32(flags), true
+	}
+
+	encoded, ok := value[valueKeyBytes].(string)
+	if !ok {
+		return "", 0, false
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", 0, false
+	}
+	return string(data), uint32(flags), true
+}
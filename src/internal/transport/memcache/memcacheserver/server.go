@@ -0,0 +1,201 @@
+package memcacheserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/tcpserver"
+)
+
+// DefaultAddr is the default listen address.
+const DefaultAddr = ":11211"
+
+// ErrServerClosed is returned by Serve and ListenAndServe after Shutdown.
+var ErrServerClosed = tcpserver.ErrServerClosed
+
+// Server speaks memcached text protocol on top of
+// kvstoreservice.KVStoreService, so memcached clients can talk to kvstore.
+type Server struct {
+	addr          string
+	service       kvstoreservice.KVStoreService
+	logger        *slog.Logger
+	cancelTimeout time.Duration
+
+	mu         sync.Mutex  // guarding flushTimer
+	flushTimer *time.Timer // delayed flush_all
+
+	tcp     *tcpserver.Server
+	started time.Time
+	stats   stats
+}
+
+// Option represents server option type.
+type Option func(*Server)
+
+// WithAddr sets listen address option.
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithService sets service option.
+func WithService(srvc kvstoreservice.KVStoreService) Option {
+	return func(s *Server) {
+		s.service = srvc
+	}
+}
+
+// WithLogger sets logger option.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = l
+	}
+}
+
+// WithContextTimeout sets service call context cancel timeout.
+func WithContextTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.cancelTimeout = d
+	}
+}
+
+// New instantiates new server instance.
+func New(options ...Option) *Server {
+	srvr := &Server{
+		addr:          DefaultAddr,
+		logger:        slog.Default(),
+		cancelTimeout: 5 * time.Second,
+		started:       time.Now(),
+	}
+
+	for _, o := range options {
+		o(srvr)
+	}
+
+	srvr.tcp = tcpserver.New(srvr.serveConn, tcpserver.WithLogger(srvr.logger))
+
+	return srvr
+}
+
+// Addr returns listen address.
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// ListenAndServe listens on server address and serves connections.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("memcacheserver.ListenAndServe net.Listen err: %w", err)
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts connections on given listener, each connection is served
+// in its own goroutine. Listener is closed on return.
+func (s *Server) Serve(ln net.Listener) error {
+	return s.tcp.Serve(ln)
+}
+
+// Shutdown stops accepting connections, lets running commands finish then
+// closes connections. Remaining connections are closed forcibly when ctx is
+// done. Scheduled flush_all is canceled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.flushTimer != nil {
+		s.flushTimer.Stop()
+	}
+	s.mu.Unlock()
+
+	return s.tcp.Shutdown(ctx)
+}
+
+func (s *Server) closing() bool {
+	return s.tcp.Closing()
+}
+
+func (s *Server) connectedClients() int {
+	return s.tcp.Conns()
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	s.stats.totalConnections.Add(1)
+
+	c := &conn{
+		server: s,
+		nc:     nc,
+		reader: bufio.NewReaderSize(nc, MaxLineLength),
+		writer: bufio.NewWriter(nc),
+	}
+	c.serve()
+}
+
+type conn struct {
+	server *Server
+	nc     net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+	quit   bool
+}
+
+func (c *conn) serve() {
+	for !c.quit {
+		line, err := readLine(c.reader)
+		if err != nil {
+			if errors.Is(err, errLineTooLong) {
+				c.reply("CLIENT_ERROR " + err.Error())
+				_ = c.writer.Flush()
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && !c.server.closing() {
+				c.server.logger.Debug("memcacheserver read command", "remote", c.nc.RemoteAddr().String(), "err", err)
+			}
+			return
+		}
+
+		if fields := strings.Fields(line); len(fields) > 0 {
+			if err = c.dispatch(fields); err != nil {
+				_ = c.writer.Flush()
+				return
+			}
+		}
+
+		// pipelined commands are answered with a single write.
+		if c.reader.Buffered() == 0 || c.quit || c.server.closing() {
+			if err = c.writer.Flush(); err != nil {
+				return
+			}
+		}
+
+		if c.server.closing() {
+			return
+		}
+	}
+}
+
+// dispatch runs the command, returned error means the connection can not
+// be used anymore.
+func (c *conn) dispatch(fields []string) error {
+	cmd, ok := commands[fields[0]]
+	if !ok {
+		c.reply("ERROR")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.server.cancelTimeout)
+	defer cancel()
+
+	return cmd(ctx, c, fields)
+}
+
+func (c *conn) reply(line string) {
+	_, _ = c.writer.WriteString(line + "\r\n")
+}
@@ -0,0 +1,330 @@
+package memcacheserver_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/transport/memcache/memcacheserver"
+)
+
+type client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newServer(t *testing.T, db kvstorage.MemoryDB) (*memcacheserver.Server, string) {
+	t.Helper()
+
+	return serve(t, kvstorage.New(kvstorage.WithMemoryDB(db)))
+}
+
+func serve(t *testing.T, storage kvstorage.Storer) (*memcacheserver.Server, string) {
+	t.Helper()
+
+	service := kvstoreservice.New(kvstoreservice.WithStorage(storage))
+	server := memcacheserver.New(memcacheserver.WithService(service))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+		if err := <-served; !errors.Is(err, memcacheserver.ErrServerClosed) {
+			t.Errorf("wrong serve error, want: %v, got: %v", memcacheserver.ErrServerClosed, err)
+		}
+	})
+
+	return server, ln.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return &client{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// do sends request and reads reply lines until one of terminal lines.
+func (c *client) do(t *testing.T, request string) string {
+	t.Helper()
+
+	if _, err := c.conn.Write([]byte(request)); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	var reply string
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error occurred: %v, reply so far: %q", err, reply)
+		}
+		reply += line
+
+		if strings.HasPrefix(line, "VALUE ") {
+			data, _ := c.reader.ReadString('\n')
+			reply += data
+			continue
+		}
+		if strings.HasPrefix(line, "STAT ") {
+			continue
+		}
+		return reply
+	}
+}
+
+func TestCommands(t *testing.T) {
+	_, addr := newServer(t, kvstorage.MemoryDB{
+		"object": {Value: map[string]any{"a": 1.0}},
+	})
+	c := dial(t, addr)
+
+	tcs := []struct {
+		request string
+		want    string
+	}{
+		{"get key\r\n", "END\r\n"},
+		{"set key 0 0 5\r\nvalue\r\n", "STORED\r\n"},
+		{"get key\r\n", "VALUE key 0 5\r\nvalue\r\nEND\r\n"},
+		{"add key 0 0 5\r\nother\r\n", "NOT_STORED\r\n"},
+		{"add new 0 0 3\r\nnew\r\n", "STORED\r\n"},
+		{"replace missing 0 0 5\r\nvalue\r\n", "NOT_STORED\r\n"},
+		{"replace key 42 0 7\r\nupdated\r\n", "STORED\r\n"},
+		{"get key new missing\r\n", "VALUE key 42 7\r\nupdated\r\nVALUE new 0 3\r\nnew\r\nEND\r\n"},
+		{"gets new\r\n", "VALUE new 0 3 3\r\nnew\r\nEND\r\n"},
+		{"cas new 0 0 3 1\r\nold\r\n", "EXISTS\r\n"},
+		{"cas new 0 0 3 3\r\ncas\r\n", "STORED\r\n"},
+		{"cas missing 0 0 3 2\r\ncas\r\n", "NOT_FOUND\r\n"},
+		{"get object\r\n", "VALUE object 0 7\r\n{\"a\":1}\r\nEND\r\n"},
+		{"set counter 0 0 2\r\n10\r\n", "STORED\r\n"},
+		{"incr counter 5\r\n", "15\r\n"},
+		{"decr counter 20\r\n", "0\r\n"},
+		{"incr key 1\r\n", "CLIENT_ERROR cannot increment or decrement non-numeric value\r\n"},
+		{"incr missing 1\r\n", "NOT_FOUND\r\n"},
+		{"incr counter abc\r\n", "CLIENT_ERROR invalid numeric delta argument\r\n"},
+		{"touch counter 100\r\n", "TOUCHED\r\n"},
+		{"touch missing 100\r\n", "NOT_FOUND\r\n"},
+		{"delete new\r\n", "DELETED\r\n"},
+		{"delete new\r\n", "NOT_FOUND\r\n"},
+		{"set quiet 0 0 1 noreply\r\nq\r\nget quiet\r\n", "VALUE quiet 0 1\r\nq\r\nEND\r\n"},
+		{"set expired 0 -1 1\r\nx\r\n", "STORED\r\n"},
+		{"get expired\r\n", "END\r\n"},
+		{"set key 0 0 abc\r\n", "CLIENT_ERROR bad command line format\r\n"},
+	}
+
+	for _, tc := range tcs {
+		if got := c.do(t, tc.request); got != tc.want {
+			t.Errorf("wrong reply for %q, want: %q, got: %q", tc.request, tc.want, got)
+		}
+	}
+}
+
+func TestBinaryValue(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kvstore.aof")
+	data := "\xff\xfe\x00a"
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncAlways)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	_, addr := serve(t, kvstorage.New(kvstorage.WithAOF(aof), kvstorage.WithSnapshotDir(dir)))
+	c := dial(t, addr)
+
+	requests := []string{
+		"set plain 0 0 4\r\n" + data + "\r\n",
+		"set flagged 42 0 4\r\n" + data + "\r\n",
+		"add added 0 0 4\r\n" + data + "\r\n",
+		"set replaced 0 0 1\r\nx\r\n",
+		"replace replaced 7 0 4\r\n" + data + "\r\n",
+	}
+	for _, request := range requests {
+		if got := c.do(t, request); got != "STORED\r\n" {
+			t.Errorf("wrong reply for %q, want: %q, got: %q", request, "STORED\r\n", got)
+		}
+	}
+
+	want := "VALUE plain 0 4\r\n" + data + "\r\n" +
+		"VALUE flagged 42 4\r\n" + data + "\r\n" +
+		"VALUE added 0 4\r\n" + data + "\r\n" +
+		"VALUE replaced 7 4\r\n" + data + "\r\nEND\r\n"
+	get := "get plain flagged added replaced\r\n"
+
+	if got := c.do(t, get); got != want {
+		t.Errorf("wrong reply, want: %q, got: %q", want, got)
+	}
+	if err = aof.Close(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	// values are read back from aof by a new storage
+	if aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	t.Cleanup(func() { _ = aof.Close() })
+
+	storage := kvstorage.New(kvstorage.WithAOF(aof), kvstorage.WithSnapshotDir(dir))
+	_, addr = serve(t, storage)
+
+	if got := dial(t, addr).do(t, get); got != want {
+		t.Errorf("wrong reply after aof replay, want: %q, got: %q", want, got)
+	}
+
+	// and from snapshot
+	if err = storage.Snapshot(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	snapshot, err := kvstorage.LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	_, addr = serve(t, kvstorage.New(kvstorage.WithSnapshot(snapshot)))
+
+	if got := dial(t, addr).do(t, get); got != want {
+		t.Errorf("wrong reply after snapshot load, want: %q, got: %q", want, got)
+	}
+}
+
+func TestExpiredExptime(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	tcs := []struct {
+		request string
+		want    string
+	}{
+		{"add key 0 -1 1\r\nx\r\n", "NOT_STORED\r\n"},
+		{"replace key 0 -1 1\r\nx\r\n", "NOT_STORED\r\n"},
+		{"touch key -1\r\n", "NOT_FOUND\r\n"},
+		{"cas key 0 -1 1 1\r\nx\r\n", "NOT_FOUND\r\n"},
+		{"set key 0 -1 1\r\nx\r\n", "STORED\r\n"},
+		{"get key\r\n", "END\r\n"},
+
+		{"set key 0 0 1\r\nx\r\n", "STORED\r\n"},
+		{"add key 0 -1 1\r\ny\r\n", "NOT_STORED\r\n"},
+		{"get key\r\n", "VALUE key 0 1\r\nx\r\nEND\r\n"},
+		{"cas key 0 -1 1 999\r\ny\r\n", "EXISTS\r\n"},
+		{"replace key 0 -1 1\r\ny\r\n", "STORED\r\n"},
+		{"get key\r\n", "END\r\n"},
+
+		{"set key 0 0 1\r\nx\r\n", "STORED\r\n"},
+		{"set key 0 1000000000 1\r\ny\r\n", "STORED\r\n"}, // timestamp in the past
+		{"get key\r\n", "END\r\n"},
+
+		{"set key 0 0 1\r\nx\r\n", "STORED\r\n"},
+		{"touch key -1\r\n", "TOUCHED\r\n"},
+		{"get key\r\n", "END\r\n"},
+	}
+
+	for _, tc := range tcs {
+		if got := c.do(t, tc.request); got != tc.want {
+			t.Errorf("wrong reply of %q, want: %q, got: %q", tc.request, tc.want, got)
+		}
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if got := c.do(t, "append key 0 0 1\r\n"); got != "ERROR\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "ERROR\r\n", got)
+	}
+	if got := c.do(t, "version\r\n"); !strings.HasPrefix(got, "VERSION ") {
+		t.Errorf("wrong reply: %q", got)
+	}
+}
+
+func TestBadDataChunk(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if got := c.do(t, "set key 0 0 2\r\nvalue\r\n"); got != "CLIENT_ERROR bad data chunk\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "CLIENT_ERROR bad data chunk\r\n", got)
+	}
+
+	if _, err := c.reader.ReadByte(); err == nil {
+		t.Error("connection must be closed")
+	}
+}
+
+func TestTooLargeItem(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	data := strings.Repeat("x", memcacheserver.MaxItemSize+1)
+	request := "set key 0 0 " + strconv.Itoa(len(data)) + "\r\n" + data + "\r\n"
+
+	if got := c.do(t, request); got != "SERVER_ERROR object too large for cache\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "SERVER_ERROR object too large for cache\r\n", got)
+	}
+	if got := c.do(t, "get key\r\n"); got != "END\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "END\r\n", got)
+	}
+}
+
+func TestFlushAllAndStats(t *testing.T) {
+	_, addr := newServer(t, kvstorage.MemoryDB{
+		"a": {Value: "1"},
+		"b": {Value: "2"},
+	})
+	c := dial(t, addr)
+
+	_ = c.do(t, "get a missing\r\n")
+
+	stats := c.do(t, "stats\r\n")
+	for _, want := range []string{"STAT curr_items 2\r\n", "STAT bytes 4\r\n", "STAT get_hits 1\r\n", "STAT get_misses 1\r\n", "END\r\n"} {
+		if !strings.Contains(stats, want) {
+			t.Errorf("stats must contain %q, got: %q", want, stats)
+		}
+	}
+
+	if got := c.do(t, "flush_all\r\n"); got != "OK\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "OK\r\n", got)
+	}
+	if got := c.do(t, "get a b\r\n"); got != "END\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "END\r\n", got)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	server, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if got := c.do(t, "version\r\n"); !strings.HasPrefix(got, "VERSION ") {
+		t.Errorf("wrong reply: %q", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+
+	if _, err := c.reader.ReadByte(); err == nil {
+		t.Error("connection must be closed")
+	}
+}
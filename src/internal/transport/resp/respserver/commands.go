@@ -0,0 +1,572 @@
+package respserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/releaseinfo"
+)
+
+// RedisVersion is reported by HELLO and INFO, clients use it for feature
+// detection.
+const RedisVersion = "7.0.0"
+
+// DefaultScanCount is the page size of SCAN when COUNT is not given.
+const DefaultScanCount = 10
+
+type command struct {
+	arity int // number of args including command name, negative means at least
+	fn    func(context.Context, *conn, [][]byte)
+}
+
+var commands = map[string]command{
+	"ping":    {arity: -1, fn: cmdPing},
+	"echo":    {arity: 2, fn: cmdEcho},
+	"hello":   {arity: -1, fn: cmdHello},
+	"select":  {arity: 2, fn: cmdSelect},
+	"quit":    {arity: 1, fn: cmdQuit},
+	"command": {arity: -1, fn: cmdCommand},
+	"client":  {arity: -2, fn: cmdClient},
+	"info":    {arity: -1, fn: cmdInfo},
+	"get":     {arity: 2, fn: cmdGet},
+	"set":     {arity: -3, fn: cmdSet},
+	"del":     {arity: -2, fn: cmdDel},
+	"exists":  {arity: -2, fn: cmdExists},
+	"keys":    {arity: 2, fn: cmdKeys},
+	"scan":    {arity: -2, fn: cmdScan},
+	"expire":  {arity: 3, fn: cmdExpire},
+	"ttl":     {arity: 2, fn: cmdTTL},
+}
+
+// formatValue converts stored value to Redis string, values set over http
+// may be any json value and are returned json encoded.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(j)
+}
+
+// parseValue converts Redis string to stored value, strings which are not
+// valid utf-8 are stored as []byte to survive json persistence.
+func parseValue(b []byte) any {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return bytes.Clone(b)
+}
+
+// serviceError replies with service error.
+func (c *conn) serviceError(command string, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.writer.error("ERR " + err.Error())
+		return
+	}
+
+	if kvErr, ok := kverror.From(err); ok {
+		if kvErr.Code.Loggable() {
+			c.server.logger.Error("respserver "+command, "err", err)
+		}
+		c.writer.error("ERR " + kvErr.Message())
+		return
+	}
+
+	c.server.logger.Error("respserver "+command, "err", err)
+	c.writer.error("ERR " + err.Error())
+}
+
+func cmdPing(_ context.Context, c *conn, args [][]byte) {
+	switch len(args) {
+	case 1:
+		c.writer.simple("PONG")
+	case 2:
+		c.writer.bulk(string(args[1]))
+	default:
+		c.writer.error("ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+func cmdEcho(_ context.Context, c *conn, args [][]byte) {
+	c.writer.bulk(string(args[1]))
+}
+
+func cmdHello(_ context.Context, c *conn, args [][]byte) {
+	proto := c.writer.proto
+
+	if len(args) > 1 {
+		v, err := strconv.Atoi(string(args[1]))
+		if err != nil || (v != protoRESP2 && v != protoRESP3) {
+			c.writer.error("NOPROTO unsupported protocol version")
+			return
+		}
+		proto = v
+	}
+
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(string(args[i])) {
+		case "SETNAME":
+			if i+1 == len(args) {
+				c.writer.error("ERR syntax error")
+				return
+			}
+			i++
+			c.name = string(args[i])
+		case "AUTH":
+			c.writer.error("ERR AUTH is not supported")
+			return
+		default:
+			c.writer.error("ERR syntax error")
+			return
+		}
+	}
+
+	c.writer.proto = proto
+
+	c.writer.mapHeader(7)
+	c.writer.bulk("server")
+	c.writer.bulk("redis")
+	c.writer.bulk("version")
+	c.writer.bulk(RedisVersion)
+	c.writer.bulk("proto")
+	c.writer.integer(int64(proto))
+	c.writer.bulk("id")
+	c.writer.integer(c.id)
+	c.writer.bulk("mode")
+	c.writer.bulk("standalone")
+	c.writer.bulk("role")
+	c.writer.bulk("master")
+	c.writer.bulk("modules")
+	c.writer.array(0)
+}
+
+func cmdSelect(_ context.Context, c *conn, args [][]byte) {
+	if string(args[1]) != "0" {
+		c.writer.error("ERR DB index is out of range")
+		return
+	}
+	c.writer.simple("OK")
+}
+
+func cmdQuit(_ context.Context, c *conn, _ [][]byte) {
+	c.writer.simple("OK")
+	c.quit = true
+}
+
+// cmdCommand replies empty command table, redis-cli asks for it on start.
+func cmdCommand(_ context.Context, c *conn, args [][]byte) {
+	if len(args) > 1 && strings.EqualFold(string(args[1]), "DOCS") {
+		c.writer.mapHeader(0)
+		return
+	}
+	c.writer.array(0)
+}
+
+func cmdClient(_ context.Context, c *conn, args [][]byte) {
+	switch subcommand := strings.ToUpper(string(args[1])); subcommand {
+	case "SETNAME":
+		if len(args) != 3 {
+			c.writer.error("ERR wrong number of arguments for 'client|setname' command")
+			return
+		}
+		c.name = string(args[2])
+		c.writer.simple("OK")
+	case "GETNAME":
+		if c.name == "" {
+			c.writer.null()
+			return
+		}
+		c.writer.bulk(c.name)
+	case "ID":
+		c.writer.integer(c.id)
+	case "SETINFO":
+		c.writer.simple("OK")
+	default:
+		c.writer.error("ERR unknown subcommand '" + string(args[1]) + "'")
+	}
+}
+
+func cmdInfo(ctx context.Context, c *conn, args [][]byte) {
+	sections := map[string]bool{}
+	for _, arg := range args[1:] {
+		sections[strings.ToLower(string(arg))] = true
+	}
+	include := func(section string) bool {
+		return len(sections) == 0 || sections["all"] || sections["everything"] ||
+			sections["default"] || sections[section]
+	}
+
+	var b strings.Builder
+
+	if include("server") {
+		b.WriteString("# Server\r\n")
+		b.WriteString("redis_version:" + RedisVersion + "\r\n")
+		b.WriteString("redis_mode:standalone\r\n")
+		b.WriteString("kvstore_version:" + releaseinfo.Version + "\r\n")
+		b.WriteString("uptime_in_seconds:" + strconv.Itoa(int(time.Since(c.server.started).Seconds())) + "\r\n")
+		b.WriteString("\r\n")
+	}
+
+	if include("clients") {
+		b.WriteString("# Clients\r\n")
+		b.WriteString("connected_clients:" + strconv.Itoa(c.server.connectedClients()) + "\r\n")
+		b.WriteString("\r\n")
+	}
+
+	if include("keyspace") {
+		stats, err := c.server.service.Stats(ctx)
+		if err != nil {
+			c.serviceError("INFO service.Stats", err)
+			return
+		}
+
+		b.WriteString("# Keyspace\r\n")
+		if stats.Keys > 0 {
+			b.WriteString(fmt.Sprintf("db0:keys=%d,expires=%d,avg_ttl=0\r\n", stats.Keys, stats.Expires))
+		}
+	}
+
+	c.writer.bulk(b.String())
+}
+
+func cmdGet(ctx context.Context, c *conn, args [][]byte) {
+	item, err := c.server.service.Get(ctx, string(args[1]))
+	if err != nil {
+		if errors.Is(err, kverror.ErrKeyNotFound) {
+			c.writer.null()
+			return
+		}
+		c.serviceError("GET service.Get", err)
+		return
+	}
+	c.writer.bulk(formatValue(item.Value))
+}
+
+// cmdSet handles SET key value [NX|XX] [EX seconds|PX milliseconds], NX
+// maps to Set, XX maps to Update and plain SET is a put transaction.
+func cmdSet(ctx context.Context, c *conn, args [][]byte) {
+	key, value := string(args[1]), parseValue(args[2])
+
+	var nx, xx bool
+	var ttl time.Duration
+
+	for i := 3; i < len(args); i++ {
+		switch option := strings.ToUpper(string(args[i])); option {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if ttl != 0 || i+1 == len(args) {
+				c.writer.error("ERR syntax error")
+				return
+			}
+			i++
+
+			n, err := strconv.ParseInt(string(args[i]), 10, 64)
+			if err != nil {
+				c.writer.error("ERR value is not an integer or out of range")
+				return
+			}
+
+			unit := time.Second
+			if option == "PX" {
+				unit = time.Millisecond
+			}
+			if n <= 0 || n > math.MaxInt64/int64(unit) {
+				c.writer.error("ERR invalid expire time in 'set' command")
+				return
+			}
+			ttl = time.Duration(n) * unit
+		default:
+			c.writer.error("ERR syntax error")
+			return
+		}
+	}
+
+	if nx && xx {
+		c.writer.error("ERR syntax error")
+		return
+	}
+
+	switch {
+	case nx:
+		if _, err := c.server.service.Set(ctx, &kvstoreservice.SetRequest{
+			Key:   key,
+			Value: value,
+			TTL:   ttl,
+		}); err != nil {
+			if errors.Is(err, kverror.ErrKeyExists) {
+				c.writer.null()
+				return
+			}
+			c.serviceError("SET service.Set", err)
+			return
+		}
+	case xx:
+		if _, err := c.server.service.Update(ctx, &kvstoreservice.UpdateRequest{
+			Key:   key,
+			Value: value,
+			TTL:   ttl,
+		}); err != nil {
+			if errors.Is(err, kverror.ErrKeyNotFound) {
+				c.writer.null()
+				return
+			}
+			c.serviceError("SET service.Update", err)
+			return
+		}
+	default:
+		if _, err := c.server.service.Txn(ctx, &kvstoreservice.TxnRequest{
+			Success: []kvstoreservice.TxnOp{
+				{Type: "put", Key: key, Value: value, TTL: ttl},
+			},
+		}); err != nil {
+			c.serviceError("SET service.Txn", err)
+			return
+		}
+	}
+
+	c.writer.simple("OK")
+}
+
+// countBatch runs batch for given keys and replies number of keys which
+// succeeded, missing keys are not counted.
+func countBatch(ctx context.Context, c *conn, op string, args [][]byte) {
+	items := make([]kvstoreservice.BatchItem, len(args))
+	for i, arg := range args {
+		items[i] = kvstoreservice.BatchItem{Key: string(arg)}
+	}
+
+	response, err := c.server.service.Batch(ctx, &kvstoreservice.BatchRequest{Op: op, Items: items})
+	if err != nil {
+		c.serviceError(strings.ToUpper(op)+" service.Batch", err)
+		return
+	}
+
+	var count int64
+	for _, result := range response.Results {
+		if result.Err == nil {
+			count++
+			continue
+		}
+		if !errors.Is(result.Err, kverror.ErrKeyNotFound) {
+			c.serviceError(strings.ToUpper(op)+" service.Batch", result.Err)
+			return
+		}
+	}
+	c.writer.integer(count)
+}
+
+func cmdDel(ctx context.Context, c *conn, args [][]byte) {
+	countBatch(ctx, c, kvstoreservice.BatchOpDelete, args[1:])
+}
+
+func cmdExists(ctx context.Context, c *conn, args [][]byte) {
+	countBatch(ctx, c, kvstoreservice.BatchOpGet, args[1:])
+}
+
+func cmdKeys(ctx context.Context, c *conn, args [][]byte) {
+	pattern := string(args[1])
+
+	list, err := c.server.service.List(ctx, &kvstoreservice.ListRequest{
+		Prefix: literalPrefix(pattern),
+	})
+	if err != nil {
+		c.serviceError("KEYS service.List", err)
+		return
+	}
+
+	keys := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		if globMatch(pattern, item.Key) {
+			keys = append(keys, item.Key)
+		}
+	}
+
+	c.writer.array(len(keys))
+	for _, key := range keys {
+		c.writer.bulk(key)
+	}
+}
+
+// cmdScan handles SCAN cursor [MATCH pattern] [COUNT count] [TYPE type],
+// keys are walked in order, COUNT limits keys examined not keys returned.
+func cmdScan(ctx context.Context, c *conn, args [][]byte) {
+	cursor, err := strconv.ParseUint(string(args[1]), 10, 64)
+	if err != nil {
+		c.writer.error("ERR invalid cursor")
+		return
+	}
+
+	pattern := "*"
+	count := DefaultScanCount
+	typ := ""
+
+	for i := 2; i < len(args); i++ {
+		if i+1 == len(args) {
+			c.writer.error("ERR syntax error")
+			return
+		}
+
+		switch strings.ToUpper(string(args[i])) {
+		case "MATCH":
+			pattern = string(args[i+1])
+		case "COUNT":
+			count, err = strconv.Atoi(string(args[i+1]))
+			if err != nil {
+				c.writer.error("ERR value is not an integer or out of range")
+				return
+			}
+			if count < 1 {
+				c.writer.error("ERR syntax error")
+				return
+			}
+		case "TYPE":
+			typ = strings.ToLower(string(args[i+1]))
+		default:
+			c.writer.error("ERR syntax error")
+			return
+		}
+		i++
+	}
+
+	var after string
+	if cursor != 0 {
+		var ok bool
+		if after, ok = c.server.cursors.get(cursor); !ok {
+			c.writer.error("ERR invalid cursor")
+			return
+		}
+	}
+
+	list, err := c.server.service.List(ctx, &kvstoreservice.ListRequest{
+		Prefix: literalPrefix(pattern),
+		After:  after,
+		Limit:  count,
+	})
+	if err != nil {
+		c.serviceError("SCAN service.List", err)
+		return
+	}
+
+	keys := make([]string, 0, len(list.Items))
+	if typ == "" || typ == "string" {
+		for _, item := range list.Items {
+			if globMatch(pattern, item.Key) {
+				keys = append(keys, item.Key)
+			}
+		}
+	}
+
+	var next uint64
+	if list.Next != "" {
+		next = c.server.cursors.add(list.Next)
+	}
+
+	c.writer.array(2)
+	c.writer.bulk(strconv.FormatUint(next, 10))
+	c.writer.array(len(keys))
+	for _, key := range keys {
+		c.writer.bulk(key)
+	}
+}
+
+// cmdExpire handles EXPIRE key seconds, value is rewritten with a version
+// check so concurrent writes are not lost. Non-positive seconds deletes the
+// key.
+func cmdExpire(ctx context.Context, c *conn, args [][]byte) {
+	key := string(args[1])
+
+	seconds, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		c.writer.error("ERR value is not an integer or out of range")
+		return
+	}
+	if seconds > math.MaxInt64/int64(time.Second) {
+		c.writer.error("ERR invalid expire time in 'expire' command")
+		return
+	}
+
+	for {
+		item, err := c.server.service.Get(ctx, key)
+		if err != nil {
+			if errors.Is(err, kverror.ErrKeyNotFound) {
+				c.writer.integer(0)
+				return
+			}
+			c.serviceError("EXPIRE service.Get", err)
+			return
+		}
+
+		if seconds <= 0 {
+			err = c.server.service.Delete(ctx, &kvstoreservice.DeleteRequest{
+				Key:     key,
+				Version: item.Version,
+			})
+		} else {
+			_, err = c.server.service.Update(ctx, &kvstoreservice.UpdateRequest{
+				Key:     key,
+				Value:   item.Value,
+				TTL:     time.Duration(seconds) * time.Second,
+				Version: item.Version,
+			})
+		}
+
+		switch {
+		case err == nil:
+			c.writer.integer(1)
+			return
+		case errors.Is(err, kverror.ErrVersionMismatch):
+			continue // changed in between, try again
+		case errors.Is(err, kverror.ErrKeyNotFound):
+			c.writer.integer(0)
+			return
+		default:
+			c.serviceError("EXPIRE service.Update", err)
+			return
+		}
+	}
+}
+
+// cmdTTL replies remaining seconds, -2 if key does not exist and -1 if key
+// never expires.
+func cmdTTL(ctx context.Context, c *conn, args [][]byte) {
+	item, err := c.server.service.Get(ctx, string(args[1]))
+	if err != nil {
+		if errors.Is(err, kverror.ErrKeyNotFound) {
+			c.writer.integer(-2)
+			return
+		}
+		c.serviceError("TTL service.Get", err)
+		return
+	}
+
+	if item.ExpiresAt.IsZero() {
+		c.writer.integer(-1)
+		return
+	}
+
+	remaining := time.Until(item.ExpiresAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	c.writer.integer(int64((remaining + time.Second/2) / time.Second))
+}
@@ -0,0 +1,49 @@
+package respserver
+
+import (
+	"sync"
+)
+
+// MaxScanCursors is the number of SCAN cursors kept, oldest ones are
+// forgotten first.
+const MaxScanCursors = 10000
+
+// cursorTable maps numeric SCAN cursors to the last key of the returned
+// page. Redis clients treat cursors as unsigned integers, keys can not be
+// used directly.
+type cursorTable struct {
+	mu    sync.Mutex
+	next  uint64
+	keys  map[uint64]string
+	order []uint64
+}
+
+func newCursorTable() *cursorTable {
+	return &cursorTable{
+		keys: make(map[uint64]string),
+	}
+}
+
+// add stores key and returns its cursor, never zero.
+func (t *cursorTable) add(key string) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.next++
+	t.keys[t.next] = key
+	t.order = append(t.order, t.next)
+
+	if len(t.order) > MaxScanCursors {
+		delete(t.keys, t.order[0])
+		t.order = t.order[1:]
+	}
+	return t.next
+}
+
+func (t *cursorTable) get(cursor uint64) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key, ok := t.keys[cursor]
+	return key, ok
+}
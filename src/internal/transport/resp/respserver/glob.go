@@ -0,0 +1,99 @@
+package respserver
+
+import (
+	"strings"
+)
+
+// globMatch reports whether s matches Redis glob-style pattern. Supports
+// *, ?, [abc], [^abc], [a-z] and \ escaping, matching is byte-wise.
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+
+			i := 1
+			negate := i < len(pattern) && pattern[i] == '^'
+			if negate {
+				i++
+			}
+
+			matched := false
+			for ; i < len(pattern) && pattern[i] != ']'; i++ {
+				switch {
+				case pattern[i] == '\\' && i+1 < len(pattern):
+					i++
+					if pattern[i] == s[0] {
+						matched = true
+					}
+				case i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']':
+					lo, hi := pattern[i], pattern[i+2]
+					if lo > hi {
+						lo, hi = hi, lo
+					}
+					if s[0] >= lo && s[0] <= hi {
+						matched = true
+					}
+					i += 2
+				default:
+					if pattern[i] == s[0] {
+						matched = true
+					}
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			if i >= len(pattern) { // unterminated class ends the pattern
+				i = len(pattern) - 1
+			}
+
+			s = s[1:]
+			pattern = pattern[i:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			s = s[1:]
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			s = s[1:]
+		}
+		pattern = pattern[1:]
+	}
+	return len(s) == 0
+}
+
+// literalPrefix returns the part of pattern before the first special
+// character, every matching key starts with it.
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
@@ -0,0 +1,173 @@
+package respserver
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// protocol limits.
+const (
+	MaxInlineLength = 64 << 10 // also size of connection read buffer
+	MaxBulkLength   = 64 << 20
+	MaxArrayLength  = 1 << 20
+	MaxRequestSize  = 64 << 20 // total of bulk strings of a command
+)
+
+// protocol versions.
+const (
+	protoRESP2 = 2
+	protoRESP3 = 3
+)
+
+var errProtocol = errors.New("protocol error")
+
+// readCommand reads a command sent either as array of bulk strings (what
+// clients send) or as inline space separated line (what telnet sends).
+// Empty command is returned for empty lines and empty arrays.
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if b != '*' {
+		_ = r.UnreadByte()
+
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := bytes.Fields(line)
+		args := make([][]byte, len(fields))
+		for i, f := range fields {
+			args[i] = append([]byte(nil), f...)
+		}
+		return args, nil
+	}
+
+	n, err := readLength(r, MaxArrayLength, "invalid multibulk length")
+	if err != nil {
+		return nil, err
+	}
+
+	// buffers grow as data arrives, declared lengths are not allocated
+	// upfront.
+	var args [][]byte
+	var size int
+	for i := 0; i < n; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != '$' {
+			return nil, fmt.Errorf("%w: expected '$', got '%c'", errProtocol, b)
+		}
+
+		l, err := readLength(r, MaxBulkLength, "invalid bulk length")
+		if err != nil {
+			return nil, err
+		}
+
+		if size += l; size > MaxRequestSize {
+			return nil, fmt.Errorf("%w: too big request", errProtocol)
+		}
+
+		var buf bytes.Buffer
+		if _, err = io.CopyN(&buf, r, int64(l)+2); err != nil {
+			return nil, err
+		}
+
+		arg := buf.Bytes()
+		if arg[l] != '\r' || arg[l+1] != '\n' {
+			return nil, fmt.Errorf("%w: bulk string is not terminated by CRLF", errProtocol)
+		}
+
+		args = append(args, arg[:l])
+	}
+	return args, nil
+}
+
+// readLine reads a line terminated by LF or CRLF, terminator is stripped.
+// Returned slice is valid until the next read.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("%w: too big inline request", errProtocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line, nil
+}
+
+func readLength(r *bufio.Reader, max int, message string) (int, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(string(line))
+	if err != nil || n > max {
+		return 0, fmt.Errorf("%w: %s", errProtocol, message)
+	}
+	if n < 0 {
+		return 0, nil
+	}
+	return n, nil
+}
+
+// writer writes replies in protocol version negotiated by HELLO, RESP2
+// unless client asks otherwise.
+type writer struct {
+	*bufio.Writer
+
+	proto int
+}
+
+func (w *writer) simple(s string) {
+	_, _ = w.WriteString("+" + s + "\r\n")
+}
+
+func (w *writer) error(message string) {
+	_, _ = w.WriteString("-" + message + "\r\n")
+}
+
+func (w *writer) integer(n int64) {
+	_, _ = w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func (w *writer) bulk(s string) {
+	_, _ = w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func (w *writer) null() {
+	if w.proto == protoRESP3 {
+		_, _ = w.WriteString("_\r\n")
+		return
+	}
+	_, _ = w.WriteString("$-1\r\n")
+}
+
+func (w *writer) array(n int) {
+	_, _ = w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// mapHeader writes map header, RESP2 has no maps so it is flattened to an
+// array of key value pairs.
+func (w *writer) mapHeader(n int) {
+	if w.proto == protoRESP3 {
+		_, _ = w.WriteString("%" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.array(2 * n)
+}
@@ -0,0 +1,247 @@
+package respserver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/transport/tcpserver"
+)
+
+// defaults.
+const (
+	DefaultAddr        = ":6379"
+	DefaultIdleTimeout = 5 * time.Minute  // waiting for the next command
+	DefaultReadTimeout = 30 * time.Second // reading rest of a started command
+)
+
+// ErrServerClosed is returned by Serve and ListenAndServe after Shutdown.
+var ErrServerClosed = tcpserver.ErrServerClosed
+
+// Server speaks Redis serialization protocol (RESP2 and RESP3) on top of
+// kvstoreservice.KVStoreService, so redis-cli and Redis client libraries
+// can talk to kvstore.
+type Server struct {
+	addr          string
+	service       kvstoreservice.KVStoreService
+	logger        *slog.Logger
+	cancelTimeout time.Duration
+	idleTimeout   time.Duration
+	readTimeout   time.Duration
+
+	tcp        *tcpserver.Server
+	started    time.Time
+	nextConnID atomic.Int64
+	cursors    *cursorTable
+}
+
+// Option represents server option type.
+type Option func(*Server)
+
+// WithAddr sets listen address option.
+func WithAddr(addr string) Option {
+	return func(s *Server) {
+		s.addr = addr
+	}
+}
+
+// WithService sets service option.
+func WithService(srvc kvstoreservice.KVStoreService) Option {
+	return func(s *Server) {
+		s.service = srvc
+	}
+}
+
+// WithLogger sets logger option.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = l
+	}
+}
+
+// WithContextTimeout sets service call context cancel timeout.
+func WithContextTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.cancelTimeout = d
+	}
+}
+
+// WithIdleTimeout sets how long a connection may wait for the next command
+// before it is closed, defaults to DefaultIdleTimeout. Zero disables it.
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.idleTimeout = d
+	}
+}
+
+// WithReadTimeout sets how long reading a command may take once it is
+// started, defaults to DefaultReadTimeout. Zero disables it.
+func WithReadTimeout(d time.Duration) Option {
+	return func(s *Server) {
+		s.readTimeout = d
+	}
+}
+
+// New instantiates new server instance.
+func New(options ...Option) *Server {
+	srvr := &Server{
+		addr:          DefaultAddr,
+		logger:        slog.Default(),
+		cancelTimeout: 5 * time.Second,
+		idleTimeout:   DefaultIdleTimeout,
+		readTimeout:   DefaultReadTimeout,
+		started:       time.Now(),
+		cursors:       newCursorTable(),
+	}
+
+	for _, o := range options {
+		o(srvr)
+	}
+
+	srvr.tcp = tcpserver.New(srvr.serveConn, tcpserver.WithLogger(srvr.logger))
+
+	return srvr
+}
+
+// Addr returns listen address.
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// ListenAndServe listens on server address and serves connections.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("respserver.ListenAndServe net.Listen err: %w", err)
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts connections on given listener, each connection is served
+// in its own goroutine. Listener is closed on return.
+func (s *Server) Serve(ln net.Listener) error {
+	return s.tcp.Serve(ln)
+}
+
+// Shutdown stops accepting connections, lets running commands finish then
+// closes connections. Remaining connections are closed forcibly when ctx is
+// done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.tcp.Shutdown(ctx)
+}
+
+func (s *Server) closing() bool {
+	return s.tcp.Closing()
+}
+
+func (s *Server) connectedClients() int {
+	return s.tcp.Conns()
+}
+
+func (s *Server) serveConn(nc net.Conn) {
+	c := &conn{
+		id:     s.nextConnID.Add(1),
+		server: s,
+		nc:     nc,
+		reader: bufio.NewReaderSize(nc, MaxInlineLength),
+		writer: &writer{Writer: bufio.NewWriter(nc), proto: protoRESP2},
+	}
+	c.serve()
+}
+
+type conn struct {
+	id     int64
+	server *Server
+	nc     net.Conn
+	reader *bufio.Reader
+	writer *writer
+	name   string
+	quit   bool
+}
+
+func (c *conn) serve() {
+	for !c.quit {
+		args, err := c.readCommand()
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				c.writer.error("ERR " + err.Error())
+				_ = c.writer.Flush()
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) && !c.server.closing() {
+				c.server.logger.Debug("respserver read command", "remote", c.nc.RemoteAddr().String(), "err", err)
+			}
+			return
+		}
+
+		if len(args) > 0 {
+			c.dispatch(args)
+		}
+
+		// pipelined commands are answered with a single write.
+		if c.reader.Buffered() == 0 || c.quit || c.server.closing() {
+			if err = c.writer.Flush(); err != nil {
+				return
+			}
+		}
+
+		if c.server.closing() {
+			return
+		}
+	}
+}
+
+// readCommand waits for the next command up to idle timeout, then reads it
+// up to read timeout.
+func (c *conn) readCommand() ([][]byte, error) {
+	if c.reader.Buffered() == 0 {
+		_ = c.nc.SetReadDeadline(deadline(c.server.idleTimeout))
+		// deadline set by Shutdown must not be overridden.
+		if c.server.closing() {
+			return nil, net.ErrClosed
+		}
+		if _, err := c.reader.Peek(1); err != nil {
+			return nil, err
+		}
+	}
+
+	_ = c.nc.SetReadDeadline(deadline(c.server.readTimeout))
+	if c.server.closing() {
+		return nil, net.ErrClosed
+	}
+	return readCommand(c.reader)
+}
+
+// deadline returns deadline of timeout d, zero time if d is zero.
+func deadline(d time.Duration) time.Time {
+	if d <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(d)
+}
+
+func (c *conn) dispatch(args [][]byte) {
+	name := strings.ToLower(string(args[0]))
+
+	cmd, ok := commands[name]
+	if !ok {
+		c.writer.error("ERR unknown command '" + string(args[0]) + "'")
+		return
+	}
+
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		c.writer.error("ERR wrong number of arguments for '" + name + "' command")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.server.cancelTimeout)
+	defer cancel()
+
+	cmd.fn(ctx, c, args)
+}
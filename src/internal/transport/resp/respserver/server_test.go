@@ -0,0 +1,416 @@
+package respserver_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/transport/resp/respserver"
+)
+
+type client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newServer(t *testing.T, db kvstorage.MemoryDB) (*respserver.Server, string) {
+	t.Helper()
+
+	return serve(t, kvstorage.New(kvstorage.WithMemoryDB(db)))
+}
+
+func serve(t *testing.T, storage kvstorage.Storer, options ...respserver.Option) (*respserver.Server, string) {
+	t.Helper()
+
+	service := kvstoreservice.New(kvstoreservice.WithStorage(storage))
+	options = append([]respserver.Option{respserver.WithService(service)}, options...)
+	server := respserver.New(options...)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+		if err := <-served; !errors.Is(err, respserver.ErrServerClosed) {
+			t.Errorf("wrong serve error, want: %v, got: %v", respserver.ErrServerClosed, err)
+		}
+	})
+
+	return server, ln.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return &client{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (c *client) send(t *testing.T, args ...string) {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+
+	if _, err := c.conn.Write([]byte(b.String())); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+}
+
+// readReply reads a whole reply and returns it in wire format.
+func (c *client) readReply(t *testing.T) string {
+	t.Helper()
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	switch line[0] {
+	case '$':
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if n < 0 {
+			return line
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(c.reader, buf); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+		return line + string(buf)
+	case '*', '%':
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if line[0] == '%' {
+			n *= 2
+		}
+		for i := 0; i < n; i++ {
+			line += c.readReply(t)
+		}
+	}
+	return line
+}
+
+func (c *client) do(t *testing.T, args ...string) string {
+	t.Helper()
+
+	c.send(t, args...)
+	return c.readReply(t)
+}
+
+func TestCommands(t *testing.T) {
+	_, addr := newServer(t, kvstorage.MemoryDB{
+		"number": {Value: 1.0},
+	})
+	c := dial(t, addr)
+
+	tcs := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"PING"}, "+PONG\r\n"},
+		{[]string{"ping", "hello"}, "$5\r\nhello\r\n"},
+		{[]string{"ECHO", "hi"}, "$2\r\nhi\r\n"},
+		{[]string{"GET", "key"}, "$-1\r\n"},
+		{[]string{"SET", "key", "value"}, "+OK\r\n"},
+		{[]string{"GET", "key"}, "$5\r\nvalue\r\n"},
+		{[]string{"SET", "key", "other", "NX"}, "$-1\r\n"},
+		{[]string{"SET", "missing", "other", "XX"}, "$-1\r\n"},
+		{[]string{"SET", "key", "updated", "XX"}, "+OK\r\n"},
+		{[]string{"SET", "new", "value", "NX", "EX", "100"}, "+OK\r\n"},
+		{[]string{"SET", "key", "value", "NX", "XX"}, "-ERR syntax error\r\n"},
+		{[]string{"SET", "key", "value", "EX", "0"}, "-ERR invalid expire time in 'set' command\r\n"},
+		{[]string{"SET", "key", "value", "EX", "abc"}, "-ERR value is not an integer or out of range\r\n"},
+		{[]string{"GET", "key"}, "$7\r\nupdated\r\n"},
+		{[]string{"GET", "number"}, "$1\r\n1\r\n"},
+		{[]string{"TTL", "new"}, ":100\r\n"},
+		{[]string{"TTL", "key"}, ":-1\r\n"},
+		{[]string{"TTL", "missing"}, ":-2\r\n"},
+		{[]string{"EXPIRE", "key", "50"}, ":1\r\n"},
+		{[]string{"TTL", "key"}, ":50\r\n"},
+		{[]string{"GET", "key"}, "$7\r\nupdated\r\n"},
+		{[]string{"EXPIRE", "missing", "50"}, ":0\r\n"},
+		{[]string{"EXISTS", "key", "new", "missing", "key"}, ":3\r\n"},
+		{[]string{"KEYS", "*"}, "*3\r\n$3\r\nkey\r\n$3\r\nnew\r\n$6\r\nnumber\r\n"},
+		{[]string{"KEYS", "n[a-f]?"}, "*1\r\n$3\r\nnew\r\n"},
+		{[]string{"KEYS", "n*r"}, "*1\r\n$6\r\nnumber\r\n"},
+		{[]string{"DEL", "key", "missing"}, ":1\r\n"},
+		{[]string{"EXPIRE", "new", "0"}, ":1\r\n"},
+		{[]string{"EXISTS", "key", "new"}, ":0\r\n"},
+		{[]string{"GET"}, "-ERR wrong number of arguments for 'get' command\r\n"},
+		{[]string{"FLUSHALL"}, "-ERR unknown command 'FLUSHALL'\r\n"},
+		{[]string{"SELECT", "0"}, "+OK\r\n"},
+		{[]string{"SELECT", "1"}, "-ERR DB index is out of range\r\n"},
+	}
+
+	for _, tc := range tcs {
+		if got := c.do(t, tc.args...); got != tc.want {
+			t.Errorf("wrong reply for %v, want: %q, got: %q", tc.args, tc.want, got)
+		}
+	}
+}
+
+func TestBinaryValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kvstore.aof")
+	value := "\xff\xfe\x00a"
+
+	aof, err := kvstorage.OpenAOF(path, kvstorage.FsyncAlways)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	_, addr := serve(t, kvstorage.New(kvstorage.WithAOF(aof)))
+	c := dial(t, addr)
+
+	if got := c.do(t, "SET", "key", value); got != "+OK\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "+OK\r\n", got)
+	}
+	if err = aof.Close(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	// value is read back from aof by a new storage
+	if aof, err = kvstorage.OpenAOF(path, kvstorage.FsyncNever); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	t.Cleanup(func() { _ = aof.Close() })
+
+	_, addr = serve(t, kvstorage.New(kvstorage.WithAOF(aof)))
+	c = dial(t, addr)
+
+	want := "$4\r\n" + value + "\r\n"
+	if got := c.do(t, "GET", "key"); got != want {
+		t.Errorf("wrong reply, want: %q, got: %q", want, got)
+	}
+}
+
+func TestScan(t *testing.T) {
+	db := kvstorage.MemoryDB{}
+	for i := 0; i < 25; i++ {
+		db["key"+strconv.Itoa(100+i)] = &kvstorage.Item{Value: "value"}
+	}
+	db["other"] = &kvstorage.Item{Value: "value"}
+
+	_, addr := newServer(t, db)
+	c := dial(t, addr)
+
+	seen := map[string]bool{}
+	cursor := "0"
+	pages := 0
+
+	for {
+		c.send(t, "SCAN", cursor, "MATCH", "key*", "COUNT", "10")
+
+		header, _ := c.reader.ReadString('\n')
+		if header != "*2\r\n" {
+			t.Fatalf("wrong scan reply header, want: %q, got: %q", "*2\r\n", header)
+		}
+
+		_, _ = c.reader.ReadString('\n')
+		next, _ := c.reader.ReadString('\n')
+		cursor = strings.TrimSpace(next)
+
+		keys := c.readReply(t)
+		for _, line := range strings.Split(keys, "\r\n") {
+			if strings.HasPrefix(line, "key") {
+				seen[line] = true
+			}
+		}
+
+		pages++
+		if cursor == "0" {
+			break
+		}
+	}
+
+	if len(seen) != 25 {
+		t.Errorf("wrong number of scanned keys, want: %d, got: %d", 25, len(seen))
+	}
+	if pages != 3 {
+		t.Errorf("wrong number of pages, want: %d, got: %d", 3, pages)
+	}
+
+	if got := c.do(t, "SCAN", "12345"); got != "-ERR invalid cursor\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "-ERR invalid cursor\r\n", got)
+	}
+}
+
+func TestHello(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if got := c.do(t, "HELLO", "4"); got != "-NOPROTO unsupported protocol version\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "-NOPROTO unsupported protocol version\r\n", got)
+	}
+
+	reply := c.do(t, "HELLO", "3", "SETNAME", "test")
+	if !strings.HasPrefix(reply, "%7\r\n") || !strings.Contains(reply, "$5\r\nproto\r\n:3\r\n") {
+		t.Errorf("wrong hello reply: %q", reply)
+	}
+
+	if got := c.do(t, "GET", "missing"); got != "_\r\n" {
+		t.Errorf("wrong resp3 null reply, want: %q, got: %q", "_\r\n", got)
+	}
+
+	if got := c.do(t, "CLIENT", "GETNAME"); got != "$4\r\ntest\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "$4\r\ntest\r\n", got)
+	}
+}
+
+func TestInlineAndPipelinedCommands(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if _, err := c.conn.Write([]byte("SET key value\r\nGET key\r\n\r\nPING\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	for _, want := range []string{"+OK\r\n", "$5\r\nvalue\r\n", "+PONG\r\n"} {
+		if got := c.readReply(t); got != want {
+			t.Errorf("wrong reply, want: %q, got: %q", want, got)
+		}
+	}
+}
+
+func TestProtocolError(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if _, err := c.conn.Write([]byte("*1\r\n+PING\r\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if got := c.readReply(t); !strings.HasPrefix(got, "-ERR protocol error") {
+		t.Errorf("wrong reply: %q", got)
+	}
+
+	if _, err := c.reader.ReadByte(); err == nil {
+		t.Error("connection must be closed")
+	}
+}
+
+func TestDeclaredLengthsNotAllocated(t *testing.T) {
+	_, addr := newServer(t, nil)
+	info := dial(t, addr)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	c := dial(t, addr)
+	header := "*" + strconv.Itoa(respserver.MaxArrayLength) + "\r\n$" + strconv.Itoa(respserver.MaxBulkLength) + "\r\nx"
+	if _, err := c.conn.Write([]byte(header)); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	_ = c.conn.Close()
+
+	// connection is served until the server reads eof.
+	for !strings.Contains(info.do(t, "INFO", "clients"), "connected_clients:1\r\n") {
+		time.Sleep(time.Millisecond)
+	}
+
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("declared lengths must not be allocated, allocated: %d bytes", allocated)
+	}
+}
+
+func TestRequestTooBig(t *testing.T) {
+	_, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	request := "*2\r\n$" + strconv.Itoa(respserver.MaxBulkLength) + "\r\n" +
+		strings.Repeat("x", respserver.MaxBulkLength) + "\r\n$1\r\nx\r\n"
+	if _, err := c.conn.Write([]byte(request)); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if got := c.readReply(t); got != "-ERR protocol error: too big request\r\n" {
+		t.Errorf("wrong reply: %q", got)
+	}
+}
+
+func TestConnectionTimeouts(t *testing.T) {
+	_, addr := serve(
+		t,
+		kvstorage.New(),
+		respserver.WithIdleTimeout(100*time.Millisecond),
+		respserver.WithReadTimeout(100*time.Millisecond),
+	)
+
+	idle := dial(t, addr)
+	if got := idle.do(t, "PING"); got != "+PONG\r\n" {
+		t.Errorf("wrong reply: %q", got)
+	}
+	if _, err := idle.reader.ReadByte(); err == nil || errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("idle connection must be closed, err: %v", err)
+	}
+
+	stalled := dial(t, addr)
+	if _, err := stalled.conn.Write([]byte("*2\r\n$4\r\nECHO\r\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err := stalled.reader.ReadByte(); err == nil || errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("stalled connection must be closed, err: %v", err)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	_, addr := newServer(t, kvstorage.MemoryDB{
+		"key":     {Value: "value"},
+		"expires": {Value: "value", ExpiresAt: time.Now().Add(time.Hour)},
+	})
+	c := dial(t, addr)
+
+	reply := c.do(t, "INFO", "keyspace")
+	if !strings.Contains(reply, "db0:keys=2,expires=1,avg_ttl=0") {
+		t.Errorf("wrong info reply: %q", reply)
+	}
+	if strings.Contains(reply, "# Server") {
+		t.Errorf("info must contain only requested section: %q", reply)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	server, addr := newServer(t, nil)
+	c := dial(t, addr)
+
+	if got := c.do(t, "PING"); got != "+PONG\r\n" {
+		t.Errorf("wrong reply, want: %q, got: %q", "+PONG\r\n", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		t.Errorf("error occurred: %v", err)
+	}
+
+	if _, err := c.reader.ReadByte(); err == nil {
+		t.Error("connection must be closed")
+	}
+}
@@ -0,0 +1,195 @@
+// Package tcpserver accepts and tracks connections of line based protocol
+// servers (Redis and memcached), so they share listener handling and
+// graceful shutdown.
+package tcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const shutdownPollInterval = 50 * time.Millisecond
+
+// ErrServerClosed is returned by Serve after Shutdown.
+var ErrServerClosed = errors.New("tcpserver: server closed")
+
+// ConnHandler serves connection until it returns, connection is closed
+// afterwards. Handlers should check Closing after each command and return
+// when it reports true.
+type ConnHandler func(nc net.Conn)
+
+// Server tracks listeners and connections of ConnHandler.
+type Server struct {
+	handler ConnHandler
+	logger  *slog.Logger
+
+	mu           sync.Mutex // guarding listeners, conns and shuttingDown
+	listeners    map[net.Listener]struct{}
+	conns        map[net.Conn]struct{}
+	shuttingDown bool
+}
+
+// Option represents server option type.
+type Option func(*Server)
+
+// WithLogger sets logger option, panics of handlers are logged to it.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = l
+	}
+}
+
+// New instantiates new server instance.
+func New(handler ConnHandler, options ...Option) *Server {
+	srvr := &Server{
+		handler:   handler,
+		logger:    slog.Default(),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+
+	for _, o := range options {
+		o(srvr)
+	}
+
+	return srvr
+}
+
+// Serve accepts connections on given listener, each connection is served
+// in its own goroutine. Listener is closed on return.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.shuttingDown {
+		s.mu.Unlock()
+		_ = ln.Close()
+		return ErrServerClosed
+	}
+	s.listeners[ln] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.listeners, ln)
+		s.mu.Unlock()
+		_ = ln.Close()
+	}()
+
+	for {
+		nc, err := ln.Accept()
+		if err != nil {
+			if s.Closing() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return fmt.Errorf("tcpserver.Serve ln.Accept err: %w", err)
+		}
+
+		if !s.track(nc) {
+			_ = nc.Close()
+			return ErrServerClosed
+		}
+
+		go s.serveConn(nc)
+	}
+}
+
+// serveConn runs handler of connection, a panicking handler is logged and
+// only its connection is closed.
+func (s *Server) serveConn(nc net.Conn) {
+	defer func() {
+		_ = nc.Close()
+		s.untrack(nc)
+	}()
+
+	defer func() {
+		if v := recover(); v != nil {
+			s.logger.Error(
+				"tcpserver handler panic",
+				"panic", fmt.Sprint(v),
+				"remote", nc.RemoteAddr().String(),
+				"stack", string(debug.Stack()),
+			)
+		}
+	}()
+
+	s.handler(nc)
+}
+
+// Shutdown stops accepting connections, lets running commands finish then
+// closes connections. Remaining connections are closed forcibly when ctx is
+// done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shuttingDown = true
+	for ln := range s.listeners {
+		_ = ln.Close()
+	}
+	// wakes up connections blocked on reading the next command.
+	for nc := range s.conns {
+		_ = nc.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.Conns() == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			for nc := range s.conns {
+				_ = nc.Close()
+			}
+			s.mu.Unlock()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Closing reports whether Shutdown is called.
+func (s *Server) Closing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.shuttingDown
+}
+
+// Conns returns number of open connections.
+func (s *Server) Conns() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.conns)
+}
+
+func (s *Server) track(nc net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shuttingDown {
+		return false
+	}
+	s.conns[nc] = struct{}{}
+	return true
+}
+
+func (s *Server) untrack(nc net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conns, nc)
+}
@@ -0,0 +1,193 @@
+package tcpserver_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/transport/tcpserver"
+)
+
+// newServer serves echo handler, received line is reported on received
+// and answered after release is closed.
+func newServer(t *testing.T, received chan<- struct{}, release <-chan struct{}) (*tcpserver.Server, string, <-chan error) {
+	t.Helper()
+
+	var server *tcpserver.Server
+	server = tcpserver.New(func(nc net.Conn) {
+		reader := bufio.NewReader(nc)
+		for !server.Closing() {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			received <- struct{}{}
+			<-release
+			_, _ = nc.Write([]byte(line))
+		}
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ln)
+	}()
+
+	return server, ln.Addr().String(), served
+}
+
+func dial(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	nc, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	t.Cleanup(func() { _ = nc.Close() })
+
+	_ = nc.SetDeadline(time.Now().Add(5 * time.Second))
+
+	return nc, bufio.NewReader(nc)
+}
+
+func TestShutdownWaitsRunningCommand(t *testing.T) {
+	received, release := make(chan struct{}, 1), make(chan struct{})
+	server, addr, served := newServer(t, received, release)
+
+	idle, _ := dial(t, addr)
+	busy, reader := dial(t, addr)
+	if _, err := busy.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	<-received
+	for server.Conns() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	if err := <-served; !errors.Is(err, tcpserver.ErrServerClosed) {
+		t.Errorf("wrong serve error, want: %v, got: %v", tcpserver.ErrServerClosed, err)
+	}
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("shutdown returned before running command finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	if err := <-shutdown; err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if line, err := reader.ReadString('\n'); err != nil || line != "hello\n" {
+		t.Errorf("wrong reply, want: %q, got: %q (%v)", "hello\n", line, err)
+	}
+
+	if _, err := idle.Read(make([]byte, 1)); err == nil {
+		t.Error("idle connection should be closed")
+	}
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Error("listener should be closed")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	received, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+
+	server, addr, served := newServer(t, received, release)
+
+	busy, reader := dial(t, addr)
+	if _, err := busy.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	<-received
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wrong shutdown error, want: %v, got: %v", context.DeadlineExceeded, err)
+	}
+	if err := <-served; !errors.Is(err, tcpserver.ErrServerClosed) {
+		t.Errorf("wrong serve error, want: %v, got: %v", tcpserver.ErrServerClosed, err)
+	}
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Error("connection should be closed forcibly")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err = server.Serve(ln); !errors.Is(err, tcpserver.ErrServerClosed) {
+		t.Errorf("wrong serve error, want: %v, got: %v", tcpserver.ErrServerClosed, err)
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	server := tcpserver.New(func(nc net.Conn) {
+		line, err := bufio.NewReader(nc).ReadString('\n')
+		if err != nil {
+			return
+		}
+		if line == "panic\n" {
+			panic("boom")
+		}
+		_, _ = nc.Write([]byte(line))
+	}, tcpserver.WithLogger(logger))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	defer func() { _ = server.Shutdown(context.Background()) }()
+
+	addr := ln.Addr().String()
+
+	panicking, reader := dial(t, addr)
+	if _, err = panicking.Write([]byte("panic\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err = reader.ReadString('\n'); err == nil {
+		t.Error("connection of panicking handler should be closed")
+	}
+
+	other, reader := dial(t, addr)
+	if _, err = other.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if line, err := reader.ReadString('\n'); err != nil || line != "hello\n" {
+		t.Errorf("wrong reply, want: %q, got: %q (%v)", "hello\n", line, err)
+	}
+
+	for server.Conns() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), "tcpserver handler panic") || !strings.Contains(logs.String(), "boom") {
+		t.Errorf("panic should be logged, got: %s", logs.String())
+	}
+}
@@ -0,0 +1,168 @@
+package kvctl
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/vbyazilim/kvstore/src/client"
+)
+
+// listPageSize is the page size used to fetch all items.
+const listPageSize = 1000
+
+// parseValue parses value as json if asJSON is set, otherwise value is a
+// string.
+func parseValue(value string, asJSON bool) (any, error) {
+	if !asJSON {
+		return value, nil
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil, fmt.Errorf("invalid json value: %w", err)
+	}
+	return v, nil
+}
+
+func runGet(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+
+	positional, err := k.parseFlags(fs, usage, args, 1)
+	if err != nil {
+		return err
+	}
+
+	item, err := k.client.Get(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return k.printItem(item)
+}
+
+func runSet(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("set", flag.ContinueOnError)
+	ttl := fs.Duration("ttl", 0, "time to live, never expires if zero")
+	asJSON := fs.Bool("json", false, "parse value as json")
+
+	positional, err := k.parseFlags(fs, usage, args, 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := parseValue(positional[1], *asJSON)
+	if err != nil {
+		return err
+	}
+
+	item, err := k.client.Set(ctx, positional[0], value, *ttl)
+	if err != nil {
+		return err
+	}
+	return k.printItem(item)
+}
+
+func runUpdate(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	ttl := fs.Duration("ttl", 0, "time to live, never expires if zero")
+	version := fs.Uint64("version", 0, "expected version, unconditional if zero")
+	asJSON := fs.Bool("json", false, "parse value as json")
+
+	positional, err := k.parseFlags(fs, usage, args, 2)
+	if err != nil {
+		return err
+	}
+
+	value, err := parseValue(positional[1], *asJSON)
+	if err != nil {
+		return err
+	}
+
+	item, err := k.client.Update(ctx, positional[0], value, *ttl, *version)
+	if err != nil {
+		return err
+	}
+	return k.printItem(item)
+}
+
+func runDelete(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	version := fs.Uint64("version", 0, "expected version, unconditional if zero")
+
+	positional, err := k.parseFlags(fs, usage, args, 1)
+	if err != nil {
+		return err
+	}
+
+	return k.client.Delete(ctx, positional[0], *version)
+}
+
+// listAll fetches items page by page, zero limit means all items.
+func (k *kvctl) listAll(ctx context.Context, opts client.ListOptions, limit int) ([]client.Item, error) {
+	var items []client.Item
+	for {
+		opts.Limit = listPageSize
+		if limit > 0 && limit-len(items) < listPageSize {
+			opts.Limit = limit - len(items)
+		}
+
+		page, err := k.client.List(ctx, &opts)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+
+		if page.Next == "" || (limit > 0 && len(items) >= limit) {
+			return items, nil
+		}
+		opts.Cursor = page.Next
+	}
+}
+
+func runList(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	prefix := fs.String("prefix", "", "list keys starting with prefix")
+	start := fs.String("start", "", "list keys from start (inclusive)")
+	end := fs.String("end", "", "list keys up to end (exclusive)")
+	limit := fs.Int("limit", 0, "maximum number of items, all if zero")
+
+	if _, err := k.parseFlags(fs, usage, args, 0); err != nil {
+		return err
+	}
+
+	items, err := k.listAll(ctx, client.ListOptions{
+		Prefix: *prefix,
+		Start:  *start,
+		End:    *end,
+	}, *limit)
+	if err != nil {
+		return err
+	}
+	return k.printItems(items)
+}
+
+func runWatch(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	prefix := fs.String("prefix", "", "watch keys starting with prefix, all keys if empty")
+	revision := fs.Uint64("revision", 0, "first revision to receive, only new events if zero")
+
+	positional, err := k.parseFlags(fs, usage, args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	opts := &client.WatchOptions{
+		Prefix:   *prefix,
+		Revision: *revision,
+	}
+	if len(positional) == 1 {
+		opts.Key = positional[0]
+	}
+
+	err = k.client.Watch(ctx, opts, k.printEvent)
+	if ctx.Err() != nil {
+		return nil // interrupted
+	}
+	return err
+}
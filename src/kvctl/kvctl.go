@@ -0,0 +1,181 @@
+// Package kvctl implements kvctl, the command-line client of kvstore.
+package kvctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/client"
+)
+
+// defaults.
+const (
+	DefaultServer  = "http://localhost:8000"
+	DefaultTimeout = 10 * time.Second
+
+	// ServerEnv is the environment variable used when --server is not given.
+	ServerEnv = "KVSTORE_SERVER"
+	// TokenEnv is the environment variable used when --token is not given.
+	TokenEnv = "KVSTORE_TOKEN"
+)
+
+// output formats.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputRaw   = "raw"
+)
+
+// ErrUsage is returned for invalid command line, usage is already printed.
+var ErrUsage = errors.New("invalid usage")
+
+type kvctl struct {
+	client *client.Client
+	output string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, k *kvctl, usage string, args []string) error
+}
+
+var commands = map[string]command{
+	"get":    {usage: "get <key>", run: runGet},
+	"set":    {usage: "set [--ttl duration] [--json] <key> <value>", run: runSet},
+	"update": {usage: "update [--ttl duration] [--version n] [--json] <key> <value>", run: runUpdate},
+	"delete": {usage: "delete [--version n] <key>", run: runDelete},
+	"list":   {usage: "list [--prefix p] [--start k] [--end k] [--limit n]", run: runList},
+	"watch":  {usage: "watch [--prefix p] [--revision n] [key]", run: runWatch},
+	"import": {usage: "import [--format json|ndjson|csv] [--file path]", run: runImport},
+	"export": {usage: "export [--format json|ndjson|csv] [--file path] [--prefix p]", run: runExport},
+}
+
+// Run runs kvctl with given arguments (without program name), getenv is
+// used for configuration which is not given as flag.
+func Run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("kvctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	server := fs.String("server", "", "server address, defaults to $"+ServerEnv+" or "+DefaultServer)
+	namespace := fs.String("namespace", "", "namespace, default namespace if empty")
+	output := fs.String("output", OutputTable, "output format: table, json or raw")
+	timeout := fs.Duration("timeout", DefaultTimeout, "timeout of each request")
+	token := fs.String("token", "", "api key or token, defaults to $"+TokenEnv)
+
+	fs.Usage = func() {
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(stderr, "usage: kvctl [flags] <command> [command flags] [args]")
+		fmt.Fprintln(stderr, "\ncommands:")
+		for _, name := range names {
+			fmt.Fprintln(stderr, "  "+commands[name].usage)
+		}
+		fmt.Fprintln(stderr, "\nflags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return ErrUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return ErrUsage
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return ErrUsage
+	}
+
+	switch *output {
+	case OutputTable, OutputJSON, OutputRaw:
+	default:
+		fmt.Fprintf(stderr, "invalid output %q, must be one of table, json or raw\n", *output)
+		return ErrUsage
+	}
+
+	if *server == "" {
+		*server = getenv(ServerEnv)
+	}
+	if *server == "" {
+		*server = DefaultServer
+	}
+
+	if *token == "" {
+		*token = getenv(TokenEnv)
+	}
+
+	c, err := client.New(
+		*server,
+		// timeout is applied per request by client, watch streams are
+		// long-lived.
+		client.WithHTTPClient(&http.Client{}),
+		client.WithTimeout(*timeout),
+		client.WithToken(*token),
+	)
+	if err != nil {
+		return err
+	}
+
+	k := &kvctl{
+		client: c.Namespace(*namespace),
+		output: *output,
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+
+	return cmd.run(ctx, k, cmd.usage, fs.Args()[1:])
+}
+
+// parseFlags parses command flags which may be mixed with positional
+// arguments, returns positional arguments.
+func (k *kvctl) parseFlags(fs *flag.FlagSet, usage string, args []string, nargs ...int) ([]string, error) {
+	fs.SetOutput(k.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(k.stderr, "usage: kvctl "+usage)
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, ErrUsage
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	for _, n := range nargs {
+		if len(positional) == n {
+			return positional, nil
+		}
+	}
+	if len(nargs) > 0 {
+		fs.Usage()
+		return nil, ErrUsage
+	}
+	return positional, nil
+}
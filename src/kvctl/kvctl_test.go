@@ -0,0 +1,185 @@
+package kvctl_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+	"github.com/vbyazilim/kvstore/src/internal/transport/http/kvstorehandler"
+	"github.com/vbyazilim/kvstore/src/kvctl"
+)
+
+func newServer(t *testing.T) string {
+	t.Helper()
+
+	storage := kvstorage.New()
+	service := kvstoreservice.New(kvstoreservice.WithStorage(storage))
+	handler := kvstorehandler.New(
+		kvstorehandler.WithService(service),
+		kvstorehandler.WithContextTimeout(time.Second),
+		kvstorehandler.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/set/", handler.Set)
+	mux.HandleFunc("/api/v1/get/", handler.Get)
+	mux.HandleFunc("/api/v1/update/", handler.Update)
+	mux.HandleFunc("/api/v1/delete/", handler.Delete)
+	mux.HandleFunc("/api/v1/list/", handler.List)
+	mux.HandleFunc("/api/v1/watch/", handler.Watch)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+// run runs kvctl against server given as environment variable.
+func run(t *testing.T, server, stdin string, args ...string) (string, error) {
+	t.Helper()
+
+	getenv := func(name string) string {
+		if name == kvctl.ServerEnv {
+			return server
+		}
+		return ""
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := kvctl.Run(context.Background(), args, getenv, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	server := newServer(t)
+
+	tcs := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-output", "raw", "set", "greeting", "hello"}, "hello\n"},
+		{[]string{"-output", "json", "set", "--json", "config", `{"debug":true}`}, `{"key":"config","value":{"debug":true},"version":2}` + "\n"},
+		{[]string{"-output", "raw", "get", "config"}, `{"debug":true}` + "\n"},
+		{[]string{"-output", "raw", "update", "greeting", "hi", "--version", "1"}, "hi\n"},
+		{[]string{"get", "greeting"}, "KEY       VALUE  VERSION  EXPIRES AT\ngreeting  hi     3        -\n"},
+		{[]string{"-output", "raw", "list", "--prefix", "gr"}, "hi\n"},
+		{[]string{"delete", "config"}, ""},
+		{[]string{"-output", "json", "list"}, `[{"key":"greeting","value":"hi","version":3}]` + "\n"},
+	}
+
+	for _, tc := range tcs {
+		got, err := run(t, server, "", tc.args...)
+		if err != nil {
+			t.Fatalf("error occurred for %v: %v", tc.args, err)
+		}
+		if got != tc.want {
+			t.Errorf("wrong output for %v, want: %q, got: %q", tc.args, tc.want, got)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := newServer(t)
+
+	if _, err := run(t, server, "", "get", "missing"); err == nil || !strings.Contains(err.Error(), "key not found") {
+		t.Errorf("wrong error: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"get"},
+		{"set", "key"},
+		{"-output", "yaml", "get", "key"},
+	} {
+		if _, err := run(t, server, "", args...); !errors.Is(err, kvctl.ErrUsage) {
+			t.Errorf("wrong error for %v, want: %v, got: %v", args, kvctl.ErrUsage, err)
+		}
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	for _, format := range []string{kvctl.FormatJSON, kvctl.FormatNDJSON, kvctl.FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			source := newServer(t)
+
+			if _, err := run(t, source, "", "set", "--json", "number", "42"); err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+			if _, err := run(t, source, "", "set", "--ttl", "1h", "text", "a,b \"c\""); err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+
+			file := filepath.Join(t.TempDir(), "export."+format)
+			if _, err := run(t, source, "", "export", "--file", file); err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+
+			target := newServer(t)
+			if _, err = run(t, target, "", "set", "number", "old"); err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+			if _, err = run(t, target, string(data), "import", "--format", format); err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+
+			got, err := run(t, target, "", "-output", "raw", "list")
+			if err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+			if want := "42\na,b \"c\"\n"; got != want {
+				t.Errorf("wrong imported values, want: %q, got: %q", want, got)
+			}
+
+			got, err = run(t, target, "", "-output", "json", "get", "text")
+			if err != nil {
+				t.Fatalf("error occurred: %v", err)
+			}
+			if !strings.Contains(got, `"expires_at"`) {
+				t.Errorf("ttl must be imported: %s", got)
+			}
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	server := newServer(t)
+
+	if _, err := run(t, server, "", "set", "app/a", "1"); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err := run(t, server, "", "delete", "app/a"); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	getenv := func(string) string { return server }
+
+	var stdout, stderr bytes.Buffer
+	err := kvctl.Run(ctx, []string{"watch", "--prefix", "app/", "--revision", "1"}, getenv, nil, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if want := "1\tput\tapp/a\t1\n2\tdelete\tapp/a\t-\n"; stdout.String() != want {
+		t.Errorf("wrong output, want: %q, got: %q", want, stdout.String())
+	}
+}
@@ -0,0 +1,136 @@
+package kvctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/client"
+)
+
+// record is the json representation of item, used by json output and
+// import/export.
+type record struct {
+	Key       string     `json:"key"`
+	Value     any        `json:"value"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Version   uint64     `json:"version,omitempty"`
+}
+
+func newRecord(item *client.Item) record {
+	r := record{
+		Key:     item.Key,
+		Value:   item.Value,
+		Version: item.Version,
+	}
+	if !item.ExpiresAt.IsZero() {
+		expiresAt := item.ExpiresAt
+		r.ExpiresAt = &expiresAt
+	}
+	return r
+}
+
+// eventRecord is the json representation of watch event.
+type eventRecord struct {
+	Type     string  `json:"type"`
+	Key      string  `json:"key,omitempty"`
+	Revision uint64  `json:"revision"`
+	Item     *record `json:"item,omitempty"`
+	PrevItem *record `json:"prev_item,omitempty"`
+}
+
+// formatValue returns strings as is, other values json encoded.
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(j)
+}
+
+func (k *kvctl) printJSON(v any) error {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("kvctl json.Marshal err: %w", err)
+	}
+	_, err = fmt.Fprintln(k.stdout, string(j))
+	return err
+}
+
+// printItem prints single item.
+func (k *kvctl) printItem(item *client.Item) error {
+	if k.output == OutputJSON {
+		return k.printJSON(newRecord(item))
+	}
+	return k.printItems([]client.Item{*item})
+}
+
+// printItems prints items, json output is an array.
+func (k *kvctl) printItems(items []client.Item) error {
+	switch k.output {
+	case OutputJSON:
+		records := make([]record, len(items))
+		for i := range items {
+			records[i] = newRecord(&items[i])
+		}
+		return k.printJSON(records)
+	case OutputRaw:
+		for _, item := range items {
+			if _, err := fmt.Fprintln(k.stdout, formatValue(item.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(k.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tVERSION\tEXPIRES AT")
+	for _, item := range items {
+		expiresAt := "-"
+		if !item.ExpiresAt.IsZero() {
+			expiresAt = item.ExpiresAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", item.Key, formatValue(item.Value), item.Version, expiresAt)
+	}
+	return w.Flush()
+}
+
+// printEvent prints watch event, raw output prints values of put events
+// only.
+func (k *kvctl) printEvent(event client.Event) error {
+	switch k.output {
+	case OutputJSON:
+		r := eventRecord{
+			Type:     event.Type,
+			Key:      event.Key,
+			Revision: event.Revision,
+		}
+		if event.Item != nil {
+			item := newRecord(event.Item)
+			r.Item = &item
+		}
+		if event.PrevItem != nil {
+			prevItem := newRecord(event.PrevItem)
+			r.PrevItem = &prevItem
+		}
+		return k.printJSON(r)
+	case OutputRaw:
+		if event.Item == nil {
+			return nil
+		}
+		_, err := fmt.Fprintln(k.stdout, formatValue(event.Item.Value))
+		return err
+	}
+
+	value := "-"
+	if event.Item != nil {
+		value = formatValue(event.Item.Value)
+	}
+	_, err := fmt.Fprintf(k.stdout, "%d\t%s\t%s\t%s\n", event.Revision, event.Type, event.Key, value)
+	return err
+}
@@ -0,0 +1,260 @@
+package kvctl
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/client"
+)
+
+// import/export formats.
+const (
+	FormatJSON   = "json"   // array of records
+	FormatNDJSON = "ndjson" // one record per line
+	FormatCSV    = "csv"    // key,value,expires_at with json encoded value
+)
+
+var csvHeader = []string{"key", "value", "expires_at"}
+
+// detectFormat returns format, inferred from file extension if not given.
+func detectFormat(format, path string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ndjson", ".jsonl":
+			format = FormatNDJSON
+		case ".csv":
+			format = FormatCSV
+		default:
+			format = FormatJSON
+		}
+	}
+
+	switch format {
+	case FormatJSON, FormatNDJSON, FormatCSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid format %q, must be one of json, ndjson or csv", format)
+}
+
+func runExport(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "json, ndjson or csv, inferred from file extension if empty")
+	file := fs.String("file", "-", "output file, stdout if -")
+	prefix := fs.String("prefix", "", "export keys starting with prefix")
+
+	if _, err := k.parseFlags(fs, usage, args, 0); err != nil {
+		return err
+	}
+
+	f, err := detectFormat(*format, *file)
+	if err != nil {
+		return err
+	}
+
+	items, err := k.listAll(ctx, client.ListOptions{Prefix: *prefix}, 0)
+	if err != nil {
+		return err
+	}
+
+	w := k.stdout
+	if *file != "-" {
+		out, err := os.Create(*file)
+		if err != nil {
+			return fmt.Errorf("kvctl export os.Create err: %w", err)
+		}
+		defer func() {
+			_ = out.Close()
+		}()
+		w = out
+	}
+
+	if err = writeRecords(w, f, items); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(k.stderr, "exported %d keys\n", len(items))
+	return nil
+}
+
+func writeRecords(w io.Writer, format string, items []client.Item) error {
+	bw := bufio.NewWriter(w)
+
+	switch format {
+	case FormatJSON:
+		records := make([]record, len(items))
+		for i := range items {
+			records[i] = newRecord(&items[i])
+		}
+
+		encoder := json.NewEncoder(bw)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			return fmt.Errorf("kvctl export encoder.Encode err: %w", err)
+		}
+	case FormatNDJSON:
+		encoder := json.NewEncoder(bw)
+		for i := range items {
+			if err := encoder.Encode(newRecord(&items[i])); err != nil {
+				return fmt.Errorf("kvctl export encoder.Encode err: %w", err)
+			}
+		}
+	case FormatCSV:
+		cw := csv.NewWriter(bw)
+		if err := cw.Write(csvHeader); err != nil {
+			return fmt.Errorf("kvctl export cw.Write err: %w", err)
+		}
+		for _, item := range items {
+			value, err := json.Marshal(item.Value)
+			if err != nil {
+				return fmt.Errorf("kvctl export json.Marshal err: %w", err)
+			}
+
+			var expiresAt string
+			if !item.ExpiresAt.IsZero() {
+				expiresAt = item.ExpiresAt.Format(time.RFC3339Nano)
+			}
+
+			if err = cw.Write([]string{item.Key, string(value), expiresAt}); err != nil {
+				return fmt.Errorf("kvctl export cw.Write err: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("kvctl export cw.Flush err: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("kvctl export bw.Flush err: %w", err)
+	}
+	return nil
+}
+
+// readRecords reads records in given format. Values of csv records are
+// json decoded, values which are not valid json are taken as string.
+func readRecords(r io.Reader, format string) ([]record, error) {
+	var records []record
+
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&records); err != nil {
+			return nil, fmt.Errorf("kvctl import decoder.Decode err: %w", err)
+		}
+	case FormatNDJSON:
+		decoder := json.NewDecoder(r)
+		for {
+			var rec record
+			err := decoder.Decode(&rec)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("kvctl import decoder.Decode record %d err: %w", len(records)+1, err)
+			}
+			records = append(records, rec)
+		}
+	case FormatCSV:
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("kvctl import csv.ReadAll err: %w", err)
+		}
+		if len(rows) > 0 && strings.EqualFold(rows[0][0], csvHeader[0]) {
+			rows = rows[1:]
+		}
+
+		for i, row := range rows {
+			if len(row) < 2 {
+				return nil, fmt.Errorf("kvctl import invalid csv row %d, want at least key and value", i+1)
+			}
+
+			rec := record{Key: row[0], Value: row[1]}
+
+			var value any
+			if err = json.Unmarshal([]byte(row[1]), &value); err == nil {
+				rec.Value = value
+			}
+
+			if len(row) > 2 && row[2] != "" {
+				expiresAt, err := time.Parse(time.RFC3339Nano, row[2])
+				if err != nil {
+					return nil, fmt.Errorf("kvctl import invalid expires_at of csv row %d err: %w", i+1, err)
+				}
+				rec.ExpiresAt = &expiresAt
+			}
+
+			records = append(records, rec)
+		}
+	}
+
+	return records, nil
+}
+
+func runImport(ctx context.Context, k *kvctl, usage string, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "json, ndjson or csv, inferred from file extension if empty")
+	file := fs.String("file", "-", "input file, stdin if -")
+
+	if _, err := k.parseFlags(fs, usage, args, 0); err != nil {
+		return err
+	}
+
+	f, err := detectFormat(*format, *file)
+	if err != nil {
+		return err
+	}
+
+	r := k.stdin
+	if *file != "-" {
+		in, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("kvctl import os.Open err: %w", err)
+		}
+		defer func() {
+			_ = in.Close()
+		}()
+		r = in
+	}
+
+	records, err := readRecords(r, f)
+	if err != nil {
+		return err
+	}
+
+	var imported, skipped int
+	for _, rec := range records {
+		if rec.Key == "" {
+			return fmt.Errorf("kvctl import record %d has empty key", imported+skipped+1)
+		}
+
+		var ttl time.Duration
+		if rec.ExpiresAt != nil {
+			if ttl = time.Until(*rec.ExpiresAt); ttl <= 0 {
+				skipped++ // already expired
+				continue
+			}
+		}
+
+		// existing keys are overwritten.
+		_, err = k.client.Set(ctx, rec.Key, rec.Value, ttl)
+		if errors.Is(err, client.ErrKeyExists) {
+			_, err = k.client.Update(ctx, rec.Key, rec.Value, ttl, 0)
+		}
+		if err != nil {
+			return fmt.Errorf("kvctl import key %q err: %w", rec.Key, err)
+		}
+		imported++
+	}
+
+	fmt.Fprintf(k.stderr, "imported %d keys, skipped %d expired\n", imported, skipped)
+	return nil
+}
@@ -0,0 +1,74 @@
+package kvstore
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/vbyazilim/kvstore/src/internal/kverror"
+)
+
+var _ error = (*Error)(nil) // compile time proof
+
+// sentinel errors, use errors.Is to check returned errors.
+var (
+	ErrKeyNotFound       = errors.New("key not found")
+	ErrKeyExists         = errors.New("key exist")
+	ErrVersionMismatch   = errors.New("version mismatch")
+	ErrInvalidTxn        = errors.New("invalid transaction")
+	ErrNamespaceExists   = errors.New("namespace exist")
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	ErrInvalidNamespace  = errors.New("invalid namespace")
+	ErrRevisionCompacted = errors.New("revision compacted")
+	ErrNoSnapshotDir     = errors.New("snapshot dir is not set")
+)
+
+// sentinels maps storage errors to sentinel errors.
+var sentinels = []struct {
+	storageErr error
+	err        error
+}{
+	{kverror.ErrKeyNotFound, ErrKeyNotFound},
+	{kverror.ErrKeyExists, ErrKeyExists},
+	{kverror.ErrVersionMismatch, ErrVersionMismatch},
+	{kverror.ErrInvalidTxn, ErrInvalidTxn},
+	{kverror.ErrNamespaceExists, ErrNamespaceExists},
+	{kverror.ErrNamespaceNotFound, ErrNamespaceNotFound},
+	{kverror.ErrInvalidNamespace, ErrInvalidNamespace},
+	{kverror.ErrRevisionCompacted, ErrRevisionCompacted},
+}
+
+// Error represents failed store operation.
+type Error struct {
+	Op  string // e.g. get, set, txn
+	Key string // key or namespace name, empty if not applicable
+	Err error  // one of sentinel errors, context error or underlying error
+}
+
+func (e *Error) Error() string {
+	if e.Key == "" {
+		return "kvstore " + e.Op + ": " + e.Err.Error()
+	}
+	return "kvstore " + e.Op + " " + strconv.Quote(e.Key) + ": " + e.Err.Error()
+}
+
+// Unwrap returns sentinel error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError wraps err of operation, storage errors are replaced with
+// sentinel errors. Returns nil if err is nil.
+func newError(op, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := &Error{Op: op, Key: key, Err: err}
+	for _, s := range sentinels {
+		if errors.Is(err, s.storageErr) {
+			e.Err = s.err
+			break
+		}
+	}
+	return e
+}
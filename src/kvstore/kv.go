@@ -0,0 +1,124 @@
+package kvstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+)
+
+// Item represents stored k/v item.
+type Item struct {
+	Key       string
+	Value     any
+	ExpiresAt time.Time // zero value means never expires
+	Version   uint64    // store revision of the last write
+}
+
+func newItem(r *kvstoreservice.ItemResponse) *Item {
+	if r == nil {
+		return nil
+	}
+	return &Item{
+		Key:       r.Key,
+		Value:     r.Value,
+		ExpiresAt: r.ExpiresAt,
+		Version:   r.Version,
+	}
+}
+
+// ListOptions selects items of List, all fields are optional.
+type ListOptions struct {
+	Prefix string
+	Start  string // inclusive
+	End    string // exclusive
+	After  string // exclusive, Next of the previous page
+	Limit  int    // zero means unlimited
+}
+
+// ListPage is a page of items in key order. Next is set if there are more
+// items, pass it as After to fetch the next page.
+type ListPage struct {
+	Items []Item
+	Next  string
+}
+
+// Set creates key, fails with ErrKeyExists if key exists. Zero ttl means
+// never expires.
+func (s *Store) Set(ctx context.Context, key string, value any, ttl time.Duration) (*Item, error) {
+	item, err := s.service.Set(ctx, &kvstoreservice.SetRequest{Key: key, Value: value, TTL: ttl})
+	if err != nil {
+		return nil, newError("set", key, err)
+	}
+	return newItem(item), nil
+}
+
+// Get returns item of key, fails with ErrKeyNotFound if key does not exist
+// or is expired.
+func (s *Store) Get(ctx context.Context, key string) (*Item, error) {
+	item, err := s.service.Get(ctx, key)
+	if err != nil {
+		return nil, newError("get", key, err)
+	}
+	return newItem(item), nil
+}
+
+// Update replaces value of existing key. Non-zero version must match the
+// current version of the item, ErrVersionMismatch otherwise.
+func (s *Store) Update(ctx context.Context, key string, value any, ttl time.Duration, version uint64) (*Item, error) {
+	item, err := s.service.Update(ctx, &kvstoreservice.UpdateRequest{
+		Key:     key,
+		Value:   value,
+		TTL:     ttl,
+		Version: version,
+	})
+	if err != nil {
+		return nil, newError("update", key, err)
+	}
+	return newItem(item), nil
+}
+
+// Put creates or replaces key.
+func (s *Store) Put(ctx context.Context, key string, value any, ttl time.Duration) (*Item, error) {
+	response, err := s.service.Txn(ctx, &kvstoreservice.TxnRequest{
+		Success: []kvstoreservice.TxnOp{{Type: OpPut, Key: key, Value: value, TTL: ttl}},
+	})
+	if err != nil {
+		return nil, newError("put", key, err)
+	}
+	return newItem(response.Results[0].Item), nil
+}
+
+// Delete removes key. Non-zero version must match the current version of
+// the item, ErrVersionMismatch otherwise.
+func (s *Store) Delete(ctx context.Context, key string, version uint64) error {
+	err := s.service.Delete(ctx, &kvstoreservice.DeleteRequest{Key: key, Version: version})
+	return newError("delete", key, err)
+}
+
+// List returns page of items, empty page if nothing matches.
+func (s *Store) List(ctx context.Context, opts *ListOptions) (*ListPage, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	response, err := s.service.List(ctx, &kvstoreservice.ListRequest{
+		Prefix: opts.Prefix,
+		Start:  opts.Start,
+		End:    opts.End,
+		After:  opts.After,
+		Limit:  opts.Limit,
+	})
+	if err != nil {
+		return nil, newError("list", "", err)
+	}
+
+	page := &ListPage{
+		Items: make([]Item, len(response.Items)),
+		Next:  response.Next,
+	}
+	for i := range response.Items {
+		page.Items[i] = *newItem(&response.Items[i])
+	}
+	return page, nil
+}
@@ -0,0 +1,207 @@
+// Package kvstore is the embeddable kvstore, runs the same storage as the
+// kvstore server in-process. Data can optionally be served over the http
+// api via Store.Handler.
+package kvstore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/apiserver"
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvpersist"
+	"github.com/vbyazilim/kvstore/src/internal/storage/memory/kvstorage"
+)
+
+// defaults.
+const (
+	DefaultNamespace           = kvstorage.DefaultNamespace
+	DefaultSnapshotInterval    = kvpersist.DefaultSnapshotInterval
+	DefaultExpireSweepInterval = kvpersist.DefaultExpireSweepInterval
+)
+
+// FsyncPolicy represents append-only file fsync policy type.
+type FsyncPolicy = kvstorage.FsyncPolicy
+
+// fsync policies.
+const (
+	FsyncEverySecond = kvstorage.FsyncEverySecond // fsync once per second, default
+	FsyncAlways      = kvstorage.FsyncAlways      // fsync after every write
+	FsyncNever       = kvstorage.FsyncNever       // let operating system decide
+)
+
+// Store is an in-memory k/v store, safe for concurrent use. Key operations
+// work on a single namespace, DefaultNamespace unless the Store is obtained
+// via Namespace.
+type Store struct {
+	*state
+	service   kvstoreservice.KVStoreService
+	namespace string
+}
+
+// state is shared by all namespace bound Store values.
+type state struct {
+	storage kvstorage.Storer
+	persist *kvpersist.Storage
+	logger  *slog.Logger
+
+	aofPath          string
+	aofFsync         FsyncPolicy
+	snapshotDir      string
+	snapshotInterval time.Duration
+
+	handlerOnce sync.Once
+	handler     http.Handler
+	handlerErr  error
+}
+
+// Option represents store option type.
+type Option func(*state)
+
+// WithSnapshotDir sets snapshot directory option. Store is restored from
+// the latest snapshot of dir, snapshots are saved periodically and on
+// Close.
+func WithSnapshotDir(dir string) Option {
+	return func(s *state) {
+		s.snapshotDir = dir
+	}
+}
+
+// WithSnapshotInterval sets periodic snapshot interval option, defaults to
+// DefaultSnapshotInterval.
+func WithSnapshotInterval(interval time.Duration) Option {
+	return func(s *state) {
+		s.snapshotInterval = interval
+	}
+}
+
+// WithAOF sets append-only file option, every mutation is logged to path
+// and replayed on Open.
+func WithAOF(path string, policy FsyncPolicy) Option {
+	return func(s *state) {
+		s.aofPath = path
+		s.aofFsync = policy
+	}
+}
+
+// WithLogger sets logger option, defaults to slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(s *state) {
+		s.logger = l
+	}
+}
+
+// Open opens store, without persistence options data lives in memory only.
+// Store must be closed by Close.
+func Open(options ...Option) (*Store, error) {
+	st := &state{
+		aofFsync:         FsyncEverySecond,
+		snapshotInterval: DefaultSnapshotInterval,
+	}
+
+	for _, o := range options {
+		o(st)
+	}
+
+	if st.logger == nil {
+		st.logger = slog.Default()
+	}
+
+	persist, err := kvpersist.Open(
+		kvpersist.WithSnapshotDir(st.snapshotDir),
+		kvpersist.WithSnapshotInterval(st.snapshotInterval),
+		kvpersist.WithAOF(st.aofPath, st.aofFsync),
+		kvpersist.WithLogger(st.logger),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("kvstore.Open err: %w", err)
+	}
+	persist.Start()
+
+	st.persist = persist
+	st.storage = persist.Storer()
+
+	return &Store{
+		state:     st,
+		service:   kvstoreservice.New(kvstoreservice.WithStorage(st.storage)),
+		namespace: DefaultNamespace,
+	}, nil
+}
+
+// Close stops background jobs, saves the final snapshot and closes
+// append-only file, which is closed even if the snapshot fails. Store and
+// its namespaces must not be used afterwards.
+func (s *Store) Close() error {
+	if err := s.persist.Close(); err != nil {
+		return fmt.Errorf("kvstore.Close err: %w", err)
+	}
+	return nil
+}
+
+// Snapshot saves snapshot of all namespaces, requires WithSnapshotDir.
+func (s *Store) Snapshot() error {
+	if s.snapshotDir == "" {
+		return newError("snapshot", "", ErrNoSnapshotDir)
+	}
+	if err := s.storage.Snapshot(); err != nil {
+		return newError("snapshot", "", err)
+	}
+	return nil
+}
+
+// Handler returns http handler of the kvstore http api serving all
+// namespaces of the store, e.g. for mounting into an existing server. The
+// handler is built once, all calls return the same handler.
+func (s *Store) Handler() (http.Handler, error) {
+	s.handlerOnce.Do(func() {
+		srvr, err := apiserver.NewServer(
+			apiserver.WithStorage(s.storage),
+			apiserver.WithLogger(s.logger),
+		)
+		if err != nil {
+			s.handlerErr = fmt.Errorf("kvstore.Handler apiserver.NewServer err: %w", err)
+			return
+		}
+		s.handler = srvr.Handler()
+	})
+	return s.handler, s.handlerErr
+}
+
+// Namespace returns store bound to given namespace, namespace must be
+// created by CreateNamespace before use.
+func (s *Store) Namespace(name string) *Store {
+	return &Store{
+		state:     s.state,
+		service:   s.service.Namespace(name),
+		namespace: name,
+	}
+}
+
+// Name returns namespace name of the store.
+func (s *Store) Name() string {
+	return s.namespace
+}
+
+// CreateNamespace creates namespace.
+func (s *Store) CreateNamespace(ctx context.Context, name string) error {
+	return newError("create namespace", name, s.service.CreateNamespace(ctx, name))
+}
+
+// DropNamespace removes namespace with all of its keys, DefaultNamespace
+// can not be dropped.
+func (s *Store) DropNamespace(ctx context.Context, name string) error {
+	return newError("drop namespace", name, s.service.DropNamespace(ctx, name))
+}
+
+// Namespaces returns names of existing namespaces in order.
+func (s *Store) Namespaces(ctx context.Context) ([]string, error) {
+	names, err := s.service.ListNamespaces(ctx)
+	if err != nil {
+		return nil, newError("namespaces", "", err)
+	}
+	return names, nil
+}
@@ -0,0 +1,298 @@
+package kvstore_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/client"
+	"github.com/vbyazilim/kvstore/src/kvstore"
+)
+
+func open(t *testing.T, options ...kvstore.Option) *kvstore.Store {
+	t.Helper()
+
+	options = append(options, kvstore.WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	store, err := kvstore.Open(options...)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	return store
+}
+
+func TestKeyOperations(t *testing.T) {
+	ctx := context.Background()
+	store := open(t)
+	defer func() { _ = store.Close() }()
+
+	item, err := store.Set(ctx, "key", "value", 0)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item.Version != 1 {
+		t.Errorf("wrong version, want: %d, got: %d", 1, item.Version)
+	}
+
+	if _, err = store.Set(ctx, "key", "value", 0); !errors.Is(err, kvstore.ErrKeyExists) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrKeyExists, err)
+	}
+
+	var kvErr *kvstore.Error
+	if !errors.As(err, &kvErr) || kvErr.Op != "set" || kvErr.Key != "key" {
+		t.Errorf("wrong error: %#v", err)
+	}
+
+	if _, err = store.Update(ctx, "key", "new", 0, 42); !errors.Is(err, kvstore.ErrVersionMismatch) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrVersionMismatch, err)
+	}
+
+	if item, err = store.Update(ctx, "key", "new", 0, 1); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item.Value != "new" || item.Version != 2 {
+		t.Errorf("wrong item: %+v", item)
+	}
+
+	if item, err = store.Put(ctx, "other", 1, time.Hour); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item.Version != 3 || item.ExpiresAt.IsZero() {
+		t.Errorf("wrong item: %+v", item)
+	}
+
+	if err = store.Delete(ctx, "key", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if _, err = store.Get(ctx, "key"); !errors.Is(err, kvstore.ErrKeyNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrKeyNotFound, err)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = store.Get(cctx, "other"); !errors.Is(err, context.Canceled) {
+		t.Errorf("wrong error, want: %v, got: %v", context.Canceled, err)
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	store := open(t)
+	defer func() { _ = store.Close() }()
+
+	for _, key := range []string{"a/1", "a/2", "a/3", "b/1"} {
+		if _, err := store.Set(ctx, key, key, 0); err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+	}
+
+	var keys []string
+	opts := &kvstore.ListOptions{Prefix: "a/", Limit: 2}
+	for {
+		page, err := store.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("error occurred: %v", err)
+		}
+		for _, item := range page.Items {
+			keys = append(keys, item.Key)
+		}
+		if page.Next == "" {
+			break
+		}
+		opts.After = page.Next
+	}
+
+	if len(keys) != 3 || keys[0] != "a/1" || keys[2] != "a/3" {
+		t.Errorf("wrong keys: %v", keys)
+	}
+
+	page, err := store.List(ctx, &kvstore.ListOptions{Prefix: "c/"})
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("wrong number of items, want: %d, got: %d", 0, len(page.Items))
+	}
+}
+
+func TestTxn(t *testing.T) {
+	ctx := context.Background()
+	store := open(t)
+	defer func() { _ = store.Close() }()
+
+	txn := &kvstore.Txn{
+		Compares: []kvstore.Compare{{Key: "lock", Target: kvstore.CompareVersion, Result: kvstore.CompareEqual}},
+		Success:  []kvstore.Op{{Type: kvstore.OpPut, Key: "lock", Value: "owner"}},
+		Failure:  []kvstore.Op{{Type: kvstore.OpGet, Key: "lock"}},
+	}
+
+	result, err := store.Txn(ctx, txn)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if !result.Succeeded || result.Results[0].Item.Version != 1 {
+		t.Errorf("wrong result: %+v", result)
+	}
+
+	if result, err = store.Txn(ctx, txn); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if result.Succeeded || result.Results[0].Item.Value != "owner" {
+		t.Errorf("wrong result: %+v", result)
+	}
+
+	_, err = store.Txn(ctx, &kvstore.Txn{Success: []kvstore.Op{{Type: "unknown", Key: "key"}}})
+	if !errors.Is(err, kvstore.ErrInvalidTxn) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrInvalidTxn, err)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	store := open(t)
+	defer func() { _ = store.Close() }()
+
+	team := store.Namespace("team-a")
+	if _, err := team.Set(ctx, "key", "value", 0); !errors.Is(err, kvstore.ErrNamespaceNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrNamespaceNotFound, err)
+	}
+
+	if err := store.CreateNamespace(ctx, "team-a"); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err := team.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err := store.Get(ctx, "key"); !errors.Is(err, kvstore.ErrKeyNotFound) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrKeyNotFound, err)
+	}
+
+	names, err := store.Namespaces(ctx)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if len(names) != 2 || names[0] != kvstore.DefaultNamespace || names[1] != "team-a" {
+		t.Errorf("wrong namespaces: %v", names)
+	}
+
+	if err = store.DropNamespace(ctx, kvstore.DefaultNamespace); !errors.Is(err, kvstore.ErrInvalidNamespace) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrInvalidNamespace, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	store := open(t)
+	defer func() { _ = store.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := store.Watch(ctx, &kvstore.WatchOptions{Prefix: "app/"})
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if _, err = store.Set(ctx, "other", 1, 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err = store.Set(ctx, "app/a", 1, 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err = store.Delete(ctx, "app/a", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	for _, want := range []kvstore.Event{
+		{Type: kvstore.EventPut, Key: "app/a", Revision: 2},
+		{Type: kvstore.EventDelete, Key: "app/a", Revision: 3},
+	} {
+		event := <-events
+		if event.Type != want.Type || event.Key != want.Key || event.Revision != want.Revision {
+			t.Errorf("wrong event, want: %+v, got: %+v", want, event)
+		}
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("events must be closed when ctx is done")
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	options := []kvstore.Option{
+		kvstore.WithSnapshotDir(filepath.Join(dir, "snapshots")),
+		kvstore.WithAOF(filepath.Join(dir, "kvstore.aof"), kvstore.FsyncAlways),
+	}
+
+	store := open(t, options...)
+	if _, err := store.Set(ctx, "snapshotted", 1, 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err := store.Snapshot(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if _, err := store.Set(ctx, "logged", 2, 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	store = open(t, options...)
+	defer func() { _ = store.Close() }()
+
+	for _, key := range []string{"snapshotted", "logged"} {
+		if _, err := store.Get(ctx, key); err != nil {
+			t.Errorf("key %q must be restored: %v", key, err)
+		}
+	}
+
+	memory := open(t)
+	defer func() { _ = memory.Close() }()
+
+	if err := memory.Snapshot(); !errors.Is(err, kvstore.ErrNoSnapshotDir) {
+		t.Errorf("wrong error, want: %v, got: %v", kvstore.ErrNoSnapshotDir, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	store := open(t)
+	defer func() { _ = store.Close() }()
+
+	handler, err := store.Handler()
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if again, _ := store.Handler(); again != handler {
+		t.Error("handler must be built once")
+	}
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c, err := client.New(server.URL)
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	if _, err = c.Set(ctx, "key", "over http", 0); err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+
+	item, err := store.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("error occurred: %v", err)
+	}
+	if item.Value != "over http" {
+		t.Errorf("wrong value, want: %q, got: %v", "over http", item.Value)
+	}
+}
@@ -0,0 +1,112 @@
+package kvstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+)
+
+// compare targets.
+const (
+	CompareVersion = "version" // zero version means key does not exist
+	CompareValue   = "value"
+)
+
+// compare results.
+const (
+	CompareEqual    = "="
+	CompareNotEqual = "!="
+	CompareGreater  = ">"
+	CompareLess     = "<"
+)
+
+// operation types.
+const (
+	OpGet    = "get"
+	OpPut    = "put" // creates or replaces
+	OpDelete = "delete"
+)
+
+// Compare is a condition of Txn, compares Version or Value of key with
+// Target.
+type Compare struct {
+	Key     string
+	Target  string // CompareVersion or CompareValue
+	Result  string // CompareEqual, CompareNotEqual, CompareGreater or CompareLess
+	Version uint64
+	Value   any
+}
+
+// Op is an operation of Txn.
+type Op struct {
+	Type  string // OpGet, OpPut or OpDelete
+	Key   string
+	Value any
+	TTL   time.Duration // zero means never expires
+}
+
+// Txn is an atomic transaction. Success operations are applied if all
+// compares hold, failure operations otherwise.
+type Txn struct {
+	Compares []Compare
+	Success  []Op
+	Failure  []Op
+}
+
+// OpResult is the result of transaction operation. Item is nil for delete
+// and for get of missing key.
+type OpResult struct {
+	Type    string
+	Key     string
+	Item    *Item
+	Deleted bool
+}
+
+// TxnResult is the result of transaction, Results are in operation order.
+type TxnResult struct {
+	Succeeded bool
+	Revision  uint64
+	Results   []OpResult
+}
+
+// Txn applies transaction atomically, invalid transactions fail with
+// ErrInvalidTxn.
+func (s *Store) Txn(ctx context.Context, txn *Txn) (*TxnResult, error) {
+	request := &kvstoreservice.TxnRequest{
+		Compares: make([]kvstoreservice.TxnCompare, len(txn.Compares)),
+		Success:  txnOps(txn.Success),
+		Failure:  txnOps(txn.Failure),
+	}
+	for i, c := range txn.Compares {
+		request.Compares[i] = kvstoreservice.TxnCompare(c)
+	}
+
+	response, err := s.service.Txn(ctx, request)
+	if err != nil {
+		return nil, newError("txn", "", err)
+	}
+
+	result := &TxnResult{
+		Succeeded: response.Succeeded,
+		Revision:  response.Revision,
+		Results:   make([]OpResult, len(response.Results)),
+	}
+	for i, r := range response.Results {
+		result.Results[i] = OpResult{
+			Type:    r.Type,
+			Key:     r.Key,
+			Item:    newItem(r.Item),
+			Deleted: r.Deleted,
+		}
+	}
+	return result, nil
+}
+
+func txnOps(ops []Op) []kvstoreservice.TxnOp {
+	serviceOps := make([]kvstoreservice.TxnOp, len(ops))
+	for i, op := range ops {
+		serviceOps[i] = kvstoreservice.TxnOp(op)
+	}
+	return serviceOps
+}
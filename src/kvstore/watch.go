@@ -0,0 +1,73 @@
+package kvstore
+
+import (
+	"context"
+
+	"github.com/vbyazilim/kvstore/src/internal/service/kvstoreservice"
+)
+
+// event types.
+const (
+	EventPut           = "put"
+	EventDelete        = "delete"
+	EventExpire        = "expire"
+	EventDropNamespace = "drop_namespace" // key is empty
+)
+
+// Event represents a change of watched keys.
+type Event struct {
+	Type     string
+	Key      string
+	Revision uint64
+	Item     *Item // new item, put only
+	PrevItem *Item // nil if key did not exist
+}
+
+// WatchOptions selects events of Watch. Key watches single key, Prefix
+// watches keys starting with it (empty prefix watches all keys).
+type WatchOptions struct {
+	Key      string
+	Prefix   string
+	Revision uint64 // first revision to receive, zero means only new events
+}
+
+// Watch streams changes of the namespace in revision order until ctx is
+// done, returned channel is closed then. Channel is also closed when the
+// receiver can not keep up, resume from the last received revision + 1.
+// Revisions which are no longer kept fail with ErrRevisionCompacted.
+func (s *Store) Watch(ctx context.Context, opts *WatchOptions) (<-chan Event, error) {
+	if opts == nil {
+		opts = &WatchOptions{}
+	}
+
+	serviceEvents, err := s.service.Watch(ctx, &kvstoreservice.WatchRequest{
+		Key:      opts.Key,
+		Prefix:   opts.Prefix,
+		Revision: opts.Revision,
+	})
+	if err != nil {
+		return nil, newError("watch", opts.Key, err)
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+
+		for event := range serviceEvents {
+			select {
+			case events <- Event{
+				Type:     event.Type,
+				Key:      event.Key,
+				Revision: event.Revision,
+				Item:     newItem(event.Item),
+				PrevItem: newItem(event.PrevItem),
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}